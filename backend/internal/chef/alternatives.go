@@ -0,0 +1,191 @@
+package chef
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quota"
+	"github.com/google/uuid"
+)
+
+// MaxAlternatives is the largest Count accepted by GenerateAlternatives.
+const MaxAlternatives = 5
+
+// Two recipes are considered the same idea when their titles or their
+// ingredient lists overlap at least this much (Jaccard index).
+const (
+	titleSimilarity      = 0.6
+	ingredientSimilarity = 0.8
+)
+
+// variations steer each alternative towards a different kind of dish so the
+// options are distinct before we even compare them.
+var variations = []string{
+	"Make it a quick stovetop dish such as a stir-fry or a sauté.",
+	"Make it an oven-baked or roasted dish.",
+	"Make it a soup, stew or curry.",
+	"Make it a fresh salad or bowl.",
+	"Make it a pasta, rice or grain-based dish.",
+}
+
+// GenerateAlternatives generates req.Count distinct recipes concurrently.
+// Options that fail are reported in Errors instead of failing the request;
+// an error is only returned when no option could be generated. Options that
+// are not returned, or come from the cache, are refunded to the quota.
+func (s *ChefService) GenerateAlternatives(ctx context.Context, userID uuid.UUID, req GenerateRequest) (*AlternativesResponse, error) {
+	count := min(max(req.Count, 1), MaxAlternatives)
+	plan, err := s.plan(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*GenerateResponse, count)
+	errs := make([]error, count)
+	var wg sync.WaitGroup
+	for i := range count {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = s.generateAlternative(ctx, req, plan, i, count, nil)
+		}()
+	}
+	wg.Wait()
+
+	resp := &AlternativesResponse{Requested: count}
+	var firstErr error
+	for i, result := range results {
+		if errs[i] != nil {
+			log.Printf("ChefService.GenerateAlternatives - option %d failed: %v", i+1, errs[i])
+			resp.Errors = append(resp.Errors, fmt.Sprintf("option %d: failed to generate recipe", i+1))
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			quota.Refund(ctx, 1)
+			continue
+		}
+
+		if similar := findSimilar(result, resp.Recipes); similar != nil {
+			// One more try, now telling the model what to avoid.
+			retry, err := s.generateAlternative(ctx, req, plan, i, count, titles(resp.Recipes))
+			if err != nil || findSimilar(retry, resp.Recipes) != nil {
+				resp.Errors = append(resp.Errors, fmt.Sprintf("option %d: too similar to %q", i+1, similar.Title))
+				quota.Refund(ctx, 1)
+				continue
+			}
+			result = retry
+		}
+		if result.Cached {
+			quota.Refund(ctx, 1)
+		}
+		resp.Recipes = append(resp.Recipes, result)
+	}
+
+	if len(resp.Recipes) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return resp, nil
+}
+
+// generateAlternative generates option i of count. avoid lists titles the
+// recipe must differ from.
+func (s *ChefService) generateAlternative(ctx context.Context, req GenerateRequest, plan *generationPlan, i, count int, avoid []string) (*GenerateResponse, error) {
+	if s.generator == nil {
+		return s.mockRecipe(ctx, plan, req, i+len(avoid))
+	}
+
+	instruction := variationInstruction(i, count, avoid)
+	key := s.cacheKey(req, plan, "\x00"+instruction)
+	if cached := s.cached(ctx, key, req); cached != nil {
+		if err := plan.accept(cached); err == nil {
+			return cached, nil
+		}
+	}
+
+	prompt, err := plan.prompts.Recipe(req, plan.prompt)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.generate(ctx, req, prompt+instruction, plan.check)
+	if err != nil {
+		return nil, err
+	}
+	result.PromptVersion = plan.prompts.Version
+	if err := plan.finish(result); err != nil {
+		return nil, err
+	}
+	s.store(ctx, key, result)
+	return result, nil
+}
+
+func variationInstruction(i, count int, avoid []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\nThis is option %d of %d alternatives offered to the user. %s\n", i+1, count, variations[i%len(variations)])
+	if len(avoid) > 0 {
+		fmt.Fprintf(&sb, "It must be clearly different from these recipes: %s.\n", strings.Join(avoid, "; "))
+	}
+	return sb.String()
+}
+
+// findSimilar returns the recipe in accepted that r duplicates, if any.
+// Degraded recipes have no reliable structure and are never compared.
+func findSimilar(r *GenerateResponse, accepted []*GenerateResponse) *GenerateResponse {
+	if r.ParseStatus == ParseDegraded {
+		return nil
+	}
+	for _, other := range accepted {
+		if other.ParseStatus == ParseDegraded {
+			continue
+		}
+		if jaccard(titleWords(r.Title), titleWords(other.Title)) >= titleSimilarity ||
+			jaccard(ingredientNames(r), ingredientNames(other)) >= ingredientSimilarity {
+			return other
+		}
+	}
+	return nil
+}
+
+func titles(recipes []*GenerateResponse) []string {
+	out := make([]string, len(recipes))
+	for i, r := range recipes {
+		out[i] = r.Title
+	}
+	return out
+}
+
+// titleWords returns the significant words of a title; short words such as
+// "and", "with" or "de" carry no meaning for the comparison.
+func titleWords(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '-' || r == '(' || r == ')' || r == '&'
+	}) {
+		if len([]rune(w)) > 3 {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+func ingredientNames(r *GenerateResponse) map[string]bool {
+	names := make(map[string]bool, len(r.Ingredients))
+	for _, ing := range r.Ingredients {
+		names[strings.ToLower(strings.TrimSpace(ing.Name))] = true
+	}
+	return names
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for k := range a {
+		if b[k] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
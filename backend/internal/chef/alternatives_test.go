@@ -0,0 +1,145 @@
+package chef
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quota"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/google/uuid"
+)
+
+// promptGenerator answers with the first output whose marker appears in the
+// prompt. It is safe for concurrent use.
+type promptGenerator struct {
+	mu      sync.Mutex
+	outputs []struct{ marker, text string }
+	calls   int
+}
+
+func (g *promptGenerator) Generate(_ context.Context, prompt ia.Prompt) (*ia.Completion, error) {
+	g.mu.Lock()
+	g.calls++
+	g.mu.Unlock()
+	for _, out := range g.outputs {
+		if strings.Contains(prompt.Text, out.marker) {
+			if out.text == "" {
+				return nil, errors.New("provider failed")
+			}
+			return &ia.Completion{Text: out.text}, nil
+		}
+	}
+	return nil, errors.New("unexpected prompt")
+}
+
+func (g *promptGenerator) Provider() string { return "prompt" }
+
+func (g *promptGenerator) Model() string { return "prompt" }
+
+func TestGenerateAlternativesPartialFailureAndDiversity(t *testing.T) {
+	gen := &promptGenerator{outputs: []struct{ marker, text string }{
+		{"clearly different", `{"title":"Chicken Soup","ingredients":["chicken","leek"],"steps":["Simmer."]}`},
+		{"option 1 of", `{"title":"Chicken Stir-Fry","ingredients":["chicken","rice"],"steps":["Fry."]}`},
+		{"option 2 of", ``},
+		{"option 3 of", `{"title":"Chicken and Rice Stir-Fry","ingredients":["chicken","rice"],"steps":["Fry."]}`},
+	}}
+	s := &ChefService{generator: gen}
+	ctx, refunded := quota.Track(context.Background(), 3)
+
+	resp, err := s.GenerateAlternatives(ctx, uuid.New(), GenerateRequest{Ingredients: []string{"chicken"}, Count: 3})
+	if err != nil {
+		t.Fatalf("GenerateAlternatives: %v", err)
+	}
+
+	var got []string
+	for _, r := range resp.Recipes {
+		got = append(got, r.Title)
+	}
+	if strings.Join(got, "|") != "Chicken Stir-Fry|Chicken Soup" {
+		t.Errorf("recipes = %v; want the duplicate replaced and the failure left out", got)
+	}
+	if resp.Requested != 3 || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0], "option 2") {
+		t.Errorf("requested = %d, errors = %v", resp.Requested, resp.Errors)
+	}
+	if spent := 3 - refunded(); spent != len(resp.Recipes) {
+		t.Errorf("charged %d generations for %d recipes", spent, len(resp.Recipes))
+	}
+}
+
+func TestGenerateRecipeCount(t *testing.T) {
+	h := NewChefHandler(&ChefService{})
+	for body, want := range map[string]int{
+		`{"ingredients":["egg"],"count":-1}`: http.StatusBadRequest,
+		`{"ingredients":["egg"],"count":6}`:  http.StatusBadRequest,
+		`{"ingredients":["egg"],"count":0}`:  http.StatusUnauthorized,
+		`{"ingredients":["egg"]}`:            http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		h.GenerateRecipe(w, httptest.NewRequest(http.MethodPost, "/generate", strings.NewReader(body)))
+		if w.Code != want {
+			t.Errorf("%s: status %d, want %d", body, w.Code, want)
+		}
+		if want == http.StatusBadRequest && !strings.Contains(w.Body.String(), "or omitted") {
+			t.Errorf("%s: message %s doesn't say count can be omitted", body, w.Body)
+		}
+	}
+}
+
+func TestGenerateAlternativesAllFailed(t *testing.T) {
+	gen := &promptGenerator{}
+	s := &ChefService{generator: gen}
+
+	if _, err := s.GenerateAlternatives(context.Background(), uuid.New(), GenerateRequest{Ingredients: []string{"egg"}, Count: 2}); err == nil {
+		t.Fatal("expected an error when every option fails")
+	}
+}
+
+func TestGenerateAlternativesMock(t *testing.T) {
+	s := &ChefService{mock: newMockGenerator(MockConfig{})}
+
+	resp, err := s.GenerateAlternatives(context.Background(), uuid.New(), GenerateRequest{Ingredients: []string{"egg", "spinach"}, Count: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Recipes) != 4 {
+		t.Fatalf("got %d recipes, errors %v", len(resp.Recipes), resp.Errors)
+	}
+	seen := map[string]bool{}
+	for _, r := range resp.Recipes {
+		if seen[r.Cuisine] {
+			t.Errorf("cuisine %s repeated", r.Cuisine)
+		}
+		seen[r.Cuisine] = true
+	}
+}
+
+func TestFindSimilar(t *testing.T) {
+	ing := func(names ...string) recipe.Details {
+		var d recipe.Details
+		for _, n := range names {
+			d.Ingredients = append(d.Ingredients, recipe.Ingredient{Name: n})
+		}
+		return d
+	}
+	base := &GenerateResponse{Title: "Roasted Chicken with Potatoes", Details: ing("chicken", "potato", "oil")}
+	accepted := []*GenerateResponse{base}
+
+	for _, tt := range []struct {
+		r    *GenerateResponse
+		want bool
+	}{
+		{&GenerateResponse{Title: "Roasted Chicken and Potatoes", Details: ing("chicken", "lemon")}, true},
+		{&GenerateResponse{Title: "Chicken Curry", Details: ing("chicken", "potato", "oil")}, true},
+		{&GenerateResponse{Title: "Chicken Curry", Details: ing("chicken", "coconut milk", "curry paste")}, false},
+	} {
+		if got := findSimilar(tt.r, accepted) != nil; got != tt.want {
+			t.Errorf("findSimilar(%q) = %v, want %v", tt.r.Title, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package chef
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+)
+
+const (
+	CacheMemory   = "memory"
+	CachePostgres = "postgres"
+	CacheOff      = "off"
+)
+
+// Cache stores generated recipes by request key. Implementations must treat
+// expired entries as missing.
+type Cache interface {
+	Get(ctx context.Context, key string) (*GenerateResponse, bool, error)
+	Set(ctx context.Context, key string, resp *GenerateResponse, ttl time.Duration) error
+}
+
+type CacheConfig struct {
+	Backend string
+	TTL     time.Duration
+	// Size is the number of entries kept by the memory backend.
+	Size int
+}
+
+// LoadCacheConfig reads GENERATION_CACHE (memory, postgres or off),
+// GENERATION_CACHE_TTL and GENERATION_CACHE_SIZE.
+func LoadCacheConfig() CacheConfig {
+	cfg := CacheConfig{
+		Backend: strings.ToLower(util.GetEnv("GENERATION_CACHE", CacheMemory)),
+		TTL:     24 * time.Hour,
+		Size:    500,
+	}
+	if d, err := time.ParseDuration(util.GetEnv("GENERATION_CACHE_TTL", "")); err == nil && d > 0 {
+		cfg.TTL = d
+	}
+	if n, err := strconv.Atoi(util.GetEnv("GENERATION_CACHE_SIZE", "")); err == nil && n > 0 {
+		cfg.Size = n
+	}
+	return cfg
+}
+
+// cacheKey hashes the parts of a request that change the generated recipe:
+// the ingredient set (sorted, lowercased, deduped), the preferences, the
+// language, the pantry hint sent with the prompt and the model used.
+func cacheKey(req GenerateRequest, pantry, model string) string {
+	ingredients := normalizeIngredients(req.Ingredients)
+	slices.Sort(ingredients)
+
+	h := sha256.New()
+	for _, part := range []string{
+		strings.Join(ingredients, "\x1f"),
+		strings.Join(strings.Fields(strings.ToLower(req.Preferences)), " "),
+		locale.Resolve(req.Language).Tag,
+		pantry,
+		model,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryCache is a fixed-size LRU. Values are stored encoded so callers can
+// never mutate a cached recipe.
+type memoryCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+func NewMemoryCache(size int) Cache {
+	return &memoryCache{
+		size:    max(size, 1),
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) (*GenerateResponse, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+
+	var resp GenerateResponse
+	if err := json.Unmarshal(entry.value, &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp, true, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, resp *GenerateResponse, ttl time.Duration) error {
+	value, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package chef
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCacheKeyNormalizesRequest(t *testing.T) {
+	a := cacheKey(GenerateRequest{Ingredients: []string{"Rice", "egg", "rice "}, Preferences: "Quick  meal", Language: "EN"}, "", "m")
+	b := cacheKey(GenerateRequest{Ingredients: []string{"egg", "rice"}, Preferences: "quick meal", Language: "en"}, "", "m")
+	if a != b {
+		t.Error("equivalent requests produced different keys")
+	}
+
+	for name, key := range map[string]string{
+		"ingredients": cacheKey(GenerateRequest{Ingredients: []string{"egg", "rice", "leek"}, Preferences: "quick meal", Language: "en"}, "", "m"),
+		"language":    cacheKey(GenerateRequest{Ingredients: []string{"egg", "rice"}, Preferences: "quick meal", Language: "pt"}, "", "m"),
+		"pantry":      cacheKey(GenerateRequest{Ingredients: []string{"egg", "rice"}, Preferences: "quick meal", Language: "en"}, "flour", "m"),
+		"model":       cacheKey(GenerateRequest{Ingredients: []string{"egg", "rice"}, Preferences: "quick meal", Language: "en"}, "", "other"),
+	} {
+		if key == a {
+			t.Errorf("changing %s did not change the key", name)
+		}
+	}
+}
+
+func TestMemoryCacheEvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	c := NewMemoryCache(2).(*memoryCache)
+	c.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b"} {
+		_ = c.Set(ctx, key, &GenerateResponse{Title: key}, time.Minute)
+	}
+	// Touch "a" so "b" becomes the least recently used.
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("a missing")
+	}
+	_ = c.Set(ctx, "c", &GenerateResponse{Title: "c"}, time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if resp, ok, _ := c.Get(ctx, "c"); !ok || resp.Title != "c" {
+		t.Errorf("Get(c) = %v, %v", resp, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("a should have expired")
+	}
+}
+
+func TestGenerateRecipeUsesCache(t *testing.T) {
+	gen := &scriptedGenerator{outputs: []string{
+		`{"title":"Rice","calories":300,"ingredients":["rice"],"steps":["Cook."]}`,
+	}}
+	s := &ChefService{generator: gen, cache: NewMemoryCache(10), cacheTTL: time.Hour}
+	ctx := context.Background()
+
+	first, err := s.GenerateRecipe(ctx, uuid.New(), GenerateRequest{Ingredients: []string{"rice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.GenerateRecipe(ctx, uuid.New(), GenerateRequest{Ingredients: []string{" RICE"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached || !second.Cached || second.Title != "Rice" {
+		t.Errorf("first.Cached = %v, second = %+v", first.Cached, second)
+	}
+	if len(gen.prompts) != 1 {
+		t.Errorf("provider called %d times, want 1", len(gen.prompts))
+	}
+
+	third, err := s.GenerateRecipe(ctx, uuid.New(), GenerateRequest{Ingredients: []string{"rice"}, Regenerate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Cached || len(gen.prompts) != 2 {
+		t.Errorf("regenerate served from cache (cached=%v, calls=%d)", third.Cached, len(gen.prompts))
+	}
+
+	// Streamed recipes are parsed from markdown and don't share entries.
+	streamed, err := s.GenerateRecipeStream(ctx, uuid.New(), GenerateRequest{Ingredients: []string{"rice"}}, func(string) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streamed.Cached || len(gen.prompts) != 3 {
+		t.Errorf("stream served from the generate cache (cached=%v, calls=%d)", streamed.Cached, len(gen.prompts))
+	}
+}
@@ -1,12 +1,26 @@
 package chef
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quota"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
 	appMiddleware "github.com/Igorlimaponce/fridgeChef/backend/middleware"
 	"github.com/Igorlimaponce/fridgeChef/backend/util"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
@@ -25,10 +39,11 @@ func (h *ChefHandler) GenerateRecipe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Ingredients) == 0 {
-		util.WriteError(w, http.StatusBadRequest, "ingredients are required")
+	if msg := validateGenerateRequest(req); msg != "" {
+		util.WriteError(w, http.StatusBadRequest, msg)
 		return
 	}
+	req.Language = locale.Resolve(req.Language).Tag
 
 	userID := appMiddleware.GetUserIDFromCtx(r.Context())
 	if userID == uuid.Nil {
@@ -36,12 +51,270 @@ func (h *ChefHandler) GenerateRecipe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Count > 1 {
+		alternatives, err := h.service.GenerateAlternatives(r.Context(), userID, req)
+		if err != nil {
+			log.Printf("ChefService.GenerateAlternatives error: %v", err)
+			writeGenerateError(w, err, req.Language)
+			return
+		}
+		util.WriteJSON(w, http.StatusOK, alternatives)
+		return
+	}
+
 	resp, err := h.service.GenerateRecipe(r.Context(), userID, req)
 	if err != nil {
 		log.Printf("ChefService.GenerateRecipe error: %v", err)
-		util.WriteError(w, http.StatusInternalServerError, "failed to generate recipe")
+		writeGenerateError(w, err, req.Language)
 		return
 	}
 
+	if resp.Cached {
+		w.Header().Set("X-Cache", "HIT")
+	}
 	util.WriteJSON(w, http.StatusOK, resp)
 }
+
+// GenerateRecipeStream streams the recipe as Server-Sent Events: a "token"
+// event per markdown fragment, then a single "recipe" event with the parsed
+// GenerateResponse, or an "error" event if generation fails midway.
+func (h *ChefHandler) GenerateRecipeStream(w http.ResponseWriter, r *http.Request) {
+	var req GenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if msg := validateGenerateRequest(req); msg != "" {
+		util.WriteError(w, http.StatusBadRequest, msg)
+		return
+	}
+	req.Language = locale.Resolve(req.Language).Tag
+
+	if req.Count > 1 {
+		util.WriteError(w, http.StatusBadRequest, "streaming supports a single recipe, use /generate for alternatives")
+		return
+	}
+
+	userID := appMiddleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	rc := util.StartSSE(w)
+
+	resp, err := h.service.GenerateRecipeStream(r.Context(), userID, req, func(token string) error {
+		return util.WriteSSE(w, rc, "token", map[string]string{"text": token})
+	})
+	if err != nil {
+		log.Printf("ChefService.GenerateRecipeStream error: %v", err)
+		status, msg := generateErrorStatus(err, req.Language)
+		// The stream already answered 200, so the quota middleware can't
+		// tell this request failed.
+		if quota.Refundable(status) {
+			quota.Refund(r.Context(), 1)
+		}
+		_ = util.WriteSSE(w, rc, "error", map[string]any{"error": msg, "status": status})
+		return
+	}
+
+	_ = util.WriteSSE(w, rc, "recipe", resp)
+}
+
+type RefineHandler struct {
+	service *RefineService
+}
+
+func NewRefineHandler(service *RefineService) *RefineHandler {
+	return &RefineHandler{service: service}
+}
+
+func (h *RefineHandler) Refine(w http.ResponseWriter, r *http.Request) {
+	var req RefineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if msg := validateRefineRequest(req); msg != "" {
+		util.WriteError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	userID := appMiddleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	resp, err := h.service.Refine(r.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSessionNotFound), errors.Is(err, recipe.ErrRecipeNotFound):
+			util.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrSessionFull):
+			util.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			log.Printf("RefineService.Refine error: %v", err)
+			language := ""
+			if req.Recipe != nil {
+				language = req.Recipe.Language
+			}
+			writeGenerateError(w, err, language)
+		}
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *RefineHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	userID := appMiddleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid session ID")
+		return
+	}
+
+	session, err := h.service.Session(r.Context(), userID, id)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			util.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("RefineService.Session error: %v", err)
+		util.WriteError(w, http.StatusInternalServerError, "failed to get session")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, session)
+}
+
+type SubstitutionHandler struct {
+	service *SubstitutionService
+}
+
+func NewSubstitutionHandler(service *SubstitutionService) *SubstitutionHandler {
+	return &SubstitutionHandler{service: service}
+}
+
+func (h *SubstitutionHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	var req SubstitutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if msg := validateSubstitutionRequest(req); msg != "" {
+		util.WriteError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	userID := appMiddleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	resp, err := h.service.Substitutions(r.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, recipe.ErrRecipeNotFound) {
+			util.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("SubstitutionService.Substitutions error: %v", err)
+		writeGenerateError(w, err, req.Language)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, resp)
+}
+
+// validateGenerateRequest returns a message describing what is wrong with
+// req, or "" when it is valid.
+func validateGenerateRequest(req GenerateRequest) string {
+	switch {
+	case !supportedLanguage(req.Language):
+		return fmt.Sprintf("language %q is not supported, use one of: %s", req.Language, strings.Join(locale.Tags(), ", "))
+	case !req.PantryMode.Valid():
+		return "pantry_mode must be strict or extras"
+	case len(req.Ingredients) == 0 && !req.PantryMode.constrained():
+		return "ingredients are required"
+	case req.MaxExtras < 0 || req.MaxExtras > MaxPantryExtras:
+		return fmt.Sprintf("max_extras must be between 0 and %d", MaxPantryExtras)
+	case req.Count < 0 || req.Count > MaxAlternatives:
+		// 0 is what an omitted count decodes to, and means one recipe.
+		return fmt.Sprintf("count must be between 1 and %d, or omitted for one recipe", MaxAlternatives)
+	}
+	return ""
+}
+
+func supportedLanguage(tag string) bool {
+	_, err := locale.Lookup(tag)
+	return err == nil
+}
+
+// GenerationCost returns how many generations a generate request spends, for
+// the quota middleware. It peeks at the JSON body and puts it back.
+func GenerationCost(r *http.Request) int {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return 1
+	}
+
+	var req struct {
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return 1
+	}
+	return min(max(req.Count, 1), MaxAlternatives)
+}
+
+// writeGenerateError maps provider failures to the status a client can act
+// on: 503 while the circuit is open, 429 when the provider is rate limiting
+// us, 502 for other upstream errors and 504 on timeouts.
+func writeGenerateError(w http.ResponseWriter, err error, language string) {
+	status, msg := generateErrorStatus(err, language)
+	if retryAfter := ia.RetryAfter(err); retryAfter > 0 && (status == http.StatusServiceUnavailable || status == http.StatusTooManyRequests) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	util.WriteError(w, status, msg)
+}
+
+// generateErrorStatus returns the status for err and a message in the
+// requested language.
+func generateErrorStatus(err error, language string) (int, string) {
+	var apiErr *ia.APIError
+	var dietErr *dietary.ViolationError
+	var pantryErr *PantryViolationError
+	switch {
+	case errors.As(err, &dietErr):
+		var terms []string
+		for _, v := range dietErr.Violations {
+			terms = append(terms, fmt.Sprintf("%s (%s)", v.Term, v.Rule))
+		}
+		return http.StatusUnprocessableEntity, localize(language, msgDietConflict) + ": " + strings.Join(terms, ", ")
+	case errors.As(err, &pantryErr):
+		return http.StatusUnprocessableEntity, localize(language, msgPantryConflict) + ": " + strings.Join(pantryErr.Extras, ", ")
+	case errors.Is(err, ErrEmptyPantry):
+		return http.StatusUnprocessableEntity, localize(language, msgEmptyPantry)
+	case errors.Is(err, ia.ErrCircuitOpen):
+		return http.StatusServiceUnavailable, localize(language, msgUnavailable)
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests:
+		return http.StatusTooManyRequests, localize(language, msgBusy)
+	case errors.As(err, &apiErr):
+		return http.StatusBadGateway, localize(language, msgGenerateFailed)
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, localize(language, msgTimeout)
+	default:
+		return http.StatusInternalServerError, localize(language, msgGenerateFailed)
+	}
+}
@@ -0,0 +1,31 @@
+package chef
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+)
+
+func TestValidateGenerateRequestLanguage(t *testing.T) {
+	for _, lang := range []string{"", "en", "pt", "es-AR", "fr-CA"} {
+		if msg := validateGenerateRequest(GenerateRequest{Ingredients: []string{"egg"}, Language: lang}); msg != "" {
+			t.Errorf("language %q rejected: %s", lang, msg)
+		}
+	}
+	msg := validateGenerateRequest(GenerateRequest{Ingredients: []string{"egg"}, Language: "de"})
+	if !strings.Contains(msg, "not supported") {
+		t.Errorf("language de accepted, msg = %q", msg)
+	}
+}
+
+func TestGenerateErrorStatusIsLocalized(t *testing.T) {
+	status, msg := generateErrorStatus(&ia.CircuitOpenError{}, "es-MX")
+	if status != http.StatusServiceUnavailable || msg != messages["es"][msgUnavailable] {
+		t.Errorf("got %d %q", status, msg)
+	}
+	if _, msg := generateErrorStatus(ErrEmptyPantry, "de"); msg != messages["en"][msgEmptyPantry] {
+		t.Errorf("unknown language did not fall back to English: %q", msg)
+	}
+}
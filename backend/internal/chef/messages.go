@@ -0,0 +1,63 @@
+package chef
+
+import "github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+
+// message identifies a user-facing generation error.
+type message int
+
+const (
+	msgGenerateFailed message = iota
+	msgDietConflict
+	msgEmptyPantry
+	msgPantryConflict
+	msgUnavailable
+	msgBusy
+	msgTimeout
+)
+
+// messages are keyed by base language; English is the fallback.
+var messages = map[string]map[message]string{
+	"en": {
+		msgGenerateFailed: "failed to generate recipe",
+		msgDietConflict:   "could not generate a recipe that fits your dietary profile",
+		msgEmptyPantry:    "your pantry is empty, add items or turn off pantry mode",
+		msgPantryConflict: "could not generate a recipe that sticks to your pantry, it also needs",
+		msgUnavailable:    "recipe generation is temporarily unavailable, please try again later",
+		msgBusy:           "recipe generation is busy, please try again later",
+		msgTimeout:        "recipe generation timed out",
+	},
+	"pt": {
+		msgGenerateFailed: "não foi possível gerar a receita",
+		msgDietConflict:   "não foi possível gerar uma receita compatível com o seu perfil alimentar",
+		msgEmptyPantry:    "sua despensa está vazia, adicione itens ou desative o modo despensa",
+		msgPantryConflict: "não foi possível gerar uma receita só com a sua despensa, ela também precisa de",
+		msgUnavailable:    "a geração de receitas está temporariamente indisponível, tente novamente mais tarde",
+		msgBusy:           "a geração de receitas está sobrecarregada, tente novamente mais tarde",
+		msgTimeout:        "a geração da receita demorou demais",
+	},
+	"es": {
+		msgGenerateFailed: "no se pudo generar la receta",
+		msgDietConflict:   "no se pudo generar una receta compatible con tu perfil alimentario",
+		msgEmptyPantry:    "tu despensa está vacía, añade productos o desactiva el modo despensa",
+		msgPantryConflict: "no se pudo generar una receta solo con tu despensa, también necesita",
+		msgUnavailable:    "la generación de recetas no está disponible temporalmente, inténtalo más tarde",
+		msgBusy:           "la generación de recetas está saturada, inténtalo más tarde",
+		msgTimeout:        "la generación de la receta tardó demasiado",
+	},
+	"fr": {
+		msgGenerateFailed: "impossible de générer la recette",
+		msgDietConflict:   "impossible de générer une recette compatible avec votre profil alimentaire",
+		msgEmptyPantry:    "votre garde-manger est vide, ajoutez des articles ou désactivez le mode garde-manger",
+		msgPantryConflict: "impossible de générer une recette avec uniquement votre garde-manger, il faudrait aussi",
+		msgUnavailable:    "la génération de recettes est temporairement indisponible, réessayez plus tard",
+		msgBusy:           "la génération de recettes est surchargée, réessayez plus tard",
+		msgTimeout:        "la génération de la recette a pris trop de temps",
+	},
+}
+
+func localize(language string, key message) string {
+	if msg, ok := messages[locale.Resolve(language).Base()][key]; ok {
+		return msg
+	}
+	return messages["en"][key]
+}
@@ -0,0 +1,432 @@
+package chef
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+)
+
+// ErrMockFailure is returned when the mock generator injects a failure.
+var ErrMockFailure = errors.New("mock generator: injected failure")
+
+// MockConfig controls the offline generator used when no AI provider is configured.
+type MockConfig struct {
+	// Latency is how long every generation takes.
+	Latency time.Duration
+	// FailureRate is the probability (0..1) that a generation fails.
+	FailureRate float64
+	// Seed is mixed into the per-request seed, so a deployment can get a
+	// different (but still reproducible) set of recipes.
+	Seed uint64
+}
+
+// LoadMockConfig reads MOCK_LATENCY, MOCK_FAILURE_RATE and MOCK_SEED.
+func LoadMockConfig() MockConfig {
+	cfg := MockConfig{Latency: 2 * time.Second}
+
+	if d, err := time.ParseDuration(util.GetEnv("MOCK_LATENCY", "")); err == nil && d >= 0 {
+		cfg.Latency = d
+	}
+	if rate, err := strconv.ParseFloat(util.GetEnv("MOCK_FAILURE_RATE", ""), 64); err == nil {
+		cfg.FailureRate = min(max(rate, 0), 1)
+	}
+	if seed, err := strconv.ParseUint(util.GetEnv("MOCK_SEED", ""), 10, 64); err == nil {
+		cfg.Seed = seed
+	}
+
+	return cfg
+}
+
+// mockGenerator builds recipes from templates. The output only depends on the
+// request (ingredients, preferences, language) and the configured seed.
+type mockGenerator struct {
+	cfg MockConfig
+}
+
+func newMockGenerator(cfg MockConfig) *mockGenerator {
+	return &mockGenerator{cfg: cfg}
+}
+
+type mockTemplateData struct {
+	Main       string
+	Others     string
+	All        string
+	Preference string
+	// Oven is the oven temperature in the language's units.
+	Oven string
+}
+
+type mockDish struct {
+	Cuisine  string
+	Tags     []string
+	Prep     [2]int
+	Cook     [2]int
+	Calories [2]int
+	// Staples are added to every recipe of this kind, per language.
+	Staples map[string][]recipe.Ingredient
+	// Titles and Steps are text/template sources, per language.
+	Titles map[string][]string
+	Steps  map[string][]string
+}
+
+var mockDishes = []mockDish{
+	{
+		Cuisine: "Asian", Tags: []string{"quick", "stir-fry"},
+		Prep: [2]int{10, 15}, Cook: [2]int{8, 15}, Calories: [2]int{380, 560},
+		Staples: map[string][]recipe.Ingredient{
+			"en": {{Name: "soy sauce", Quantity: 2, Unit: "tbsp"}, {Name: "garlic", Quantity: 2, Unit: "cloves"}, {Name: "vegetable oil", Quantity: 1, Unit: "tbsp"}},
+			"pt": {{Name: "molho de soja", Quantity: 2, Unit: "tbsp"}, {Name: "alho", Quantity: 2, Unit: "cloves"}, {Name: "óleo vegetal", Quantity: 1, Unit: "tbsp"}},
+			"es": {{Name: "salsa de soja", Quantity: 2, Unit: "tbsp"}, {Name: "ajo", Quantity: 2, Unit: "cloves"}, {Name: "aceite vegetal", Quantity: 1, Unit: "tbsp"}},
+			"fr": {{Name: "sauce soja", Quantity: 2, Unit: "tbsp"}, {Name: "ail", Quantity: 2, Unit: "cloves"}, {Name: "huile végétale", Quantity: 1, Unit: "tbsp"}},
+		},
+		Titles: map[string][]string{
+			"en": {"{{.Main}} Stir-Fry with {{.Others}}", "Wok-Tossed {{.Main}} and {{.Others}}"},
+			"pt": {"{{.Main}} Salteado com {{.Others}}", "{{.Main}} no Wok com {{.Others}}"},
+			"es": {"{{.Main}} Salteado con {{.Others}}", "{{.Main}} al Wok con {{.Others}}"},
+			"fr": {"{{.Main}} Sauté avec {{.Others}}", "{{.Main}} au Wok et {{.Others}}"},
+		},
+		Steps: map[string][]string{
+			"en": {"Cut the {{.All}} into bite-sized pieces.", "Heat the oil in a wok over high heat and fry the garlic for 30 seconds.", "Add the {{.Main}} and stir-fry for 4 minutes.", "Add the {{.Others}} and the soy sauce and cook for 3 more minutes.", "Serve immediately."},
+			"pt": {"Corte {{.All}} em pedaços pequenos.", "Aqueça o óleo em uma wok em fogo alto e frite o alho por 30 segundos.", "Adicione {{.Main}} e salteie por 4 minutos.", "Junte {{.Others}} e o molho de soja e cozinhe por mais 3 minutos.", "Sirva imediatamente."},
+			"es": {"Corta {{.All}} en trozos pequeños.", "Calienta el aceite en un wok a fuego fuerte y fríe el ajo durante 30 segundos.", "Añade {{.Main}} y saltea durante 4 minutos.", "Incorpora {{.Others}} y la salsa de soja y cocina 3 minutos más.", "Sirve de inmediato."},
+			"fr": {"Coupez {{.All}} en petits morceaux.", "Faites chauffer l'huile dans un wok à feu vif et faites revenir l'ail 30 secondes.", "Ajoutez {{.Main}} et faites sauter 4 minutes.", "Ajoutez {{.Others}} et la sauce soja et laissez cuire encore 3 minutes.", "Servez aussitôt."},
+		},
+	},
+	{
+		Cuisine: "Mediterranean", Tags: []string{"oven", "one-pan"},
+		Prep: [2]int{10, 20}, Cook: [2]int{25, 45}, Calories: [2]int{420, 650},
+		Staples: map[string][]recipe.Ingredient{
+			"en": {{Name: "olive oil", Quantity: 3, Unit: "tbsp"}, {Name: "salt", Note: "to taste"}, {Name: "black pepper", Note: "to taste"}},
+			"pt": {{Name: "azeite", Quantity: 3, Unit: "tbsp"}, {Name: "sal", Note: "a gosto"}, {Name: "pimenta-do-reino", Note: "a gosto"}},
+			"es": {{Name: "aceite de oliva", Quantity: 3, Unit: "tbsp"}, {Name: "sal", Note: "al gusto"}, {Name: "pimienta negra", Note: "al gusto"}},
+			"fr": {{Name: "huile d'olive", Quantity: 3, Unit: "tbsp"}, {Name: "sel", Note: "selon le goût"}, {Name: "poivre noir", Note: "selon le goût"}},
+		},
+		Titles: map[string][]string{
+			"en": {"Roasted {{.Main}} Tray Bake with {{.Others}}", "Oven-Baked {{.Main}} and {{.Others}}"},
+			"pt": {"{{.Main}} Assado com {{.Others}}", "Assadeira de {{.Main}} e {{.Others}}"},
+			"es": {"{{.Main}} Asado con {{.Others}}", "Bandeja de {{.Main}} y {{.Others}} al Horno"},
+			"fr": {"{{.Main}} Rôti avec {{.Others}}", "Plaque de {{.Main}} et {{.Others}} au Four"},
+		},
+		Steps: map[string][]string{
+			"en": {"Preheat the oven to {{.Oven}}.", "Toss the {{.All}} with olive oil, salt and pepper.", "Spread everything on a baking tray in a single layer.", "Roast until golden, turning halfway through.", "Rest for 5 minutes before serving."},
+			"pt": {"Preaqueça o forno a {{.Oven}}.", "Misture {{.All}} com azeite, sal e pimenta.", "Espalhe tudo em uma assadeira em uma única camada.", "Asse até dourar, virando na metade do tempo.", "Deixe descansar 5 minutos antes de servir."},
+			"es": {"Precalienta el horno a {{.Oven}}.", "Mezcla {{.All}} con aceite de oliva, sal y pimienta.", "Extiende todo en una bandeja de horno en una sola capa.", "Asa hasta que esté dorado, dando la vuelta a mitad de cocción.", "Deja reposar 5 minutos antes de servir."},
+			"fr": {"Préchauffez le four à {{.Oven}}.", "Mélangez {{.All}} avec l'huile d'olive, le sel et le poivre.", "Étalez le tout sur une plaque en une seule couche.", "Faites rôtir jusqu'à ce que ce soit doré, en retournant à mi-cuisson.", "Laissez reposer 5 minutes avant de servir."},
+		},
+	},
+	{
+		Cuisine: "Comfort", Tags: []string{"soup", "warm"},
+		Prep: [2]int{10, 15}, Cook: [2]int{20, 40}, Calories: [2]int{250, 420},
+		Staples: map[string][]recipe.Ingredient{
+			"en": {{Name: "onion", Quantity: 1, Unit: "unit"}, {Name: "stock", Quantity: 1, Unit: "l"}, {Name: "salt", Note: "to taste"}},
+			"pt": {{Name: "cebola", Quantity: 1, Unit: "unit"}, {Name: "caldo", Quantity: 1, Unit: "l"}, {Name: "sal", Note: "a gosto"}},
+			"es": {{Name: "cebolla", Quantity: 1, Unit: "unit"}, {Name: "caldo", Quantity: 1, Unit: "l"}, {Name: "sal", Note: "al gusto"}},
+			"fr": {{Name: "oignon", Quantity: 1, Unit: "unit"}, {Name: "bouillon", Quantity: 1, Unit: "l"}, {Name: "sel", Note: "selon le goût"}},
+		},
+		Titles: map[string][]string{
+			"en": {"Hearty {{.Main}} Soup with {{.Others}}", "Creamy {{.Main}} and {{.Others}} Soup"},
+			"pt": {"Sopa Reconfortante de {{.Main}} com {{.Others}}", "Creme de {{.Main}} e {{.Others}}"},
+			"es": {"Sopa Reconfortante de {{.Main}} con {{.Others}}", "Crema de {{.Main}} y {{.Others}}"},
+			"fr": {"Soupe Réconfortante de {{.Main}} aux {{.Others}}", "Velouté de {{.Main}} et {{.Others}}"},
+		},
+		Steps: map[string][]string{
+			"en": {"Chop the onion and sweat it in a large pot until soft.", "Add the {{.All}} and stir for 2 minutes.", "Pour in the stock and bring to a boil.", "Simmer until everything is tender.", "Season with salt and serve hot."},
+			"pt": {"Pique a cebola e refogue em uma panela grande até amolecer.", "Adicione {{.All}} e mexa por 2 minutos.", "Despeje o caldo e deixe ferver.", "Cozinhe em fogo baixo até tudo ficar macio.", "Tempere com sal e sirva quente."},
+			"es": {"Pica la cebolla y sofríela en una olla grande hasta que esté blanda.", "Añade {{.All}} y remueve durante 2 minutos.", "Vierte el caldo y lleva a ebullición.", "Cocina a fuego lento hasta que todo esté tierno.", "Sazona con sal y sirve caliente."},
+			"fr": {"Émincez l'oignon et faites-le suer dans une grande casserole jusqu'à ce qu'il soit tendre.", "Ajoutez {{.All}} et remuez pendant 2 minutes.", "Versez le bouillon et portez à ébullition.", "Laissez mijoter jusqu'à ce que tout soit tendre.", "Salez et servez bien chaud."},
+		},
+	},
+	{
+		Cuisine: "Fresh", Tags: []string{"salad", "no-cook"},
+		Prep: [2]int{10, 15}, Cook: [2]int{0, 0}, Calories: [2]int{180, 380},
+		Staples: map[string][]recipe.Ingredient{
+			"en": {{Name: "olive oil", Quantity: 2, Unit: "tbsp"}, {Name: "lemon juice", Quantity: 1, Unit: "tbsp"}, {Name: "salt", Note: "to taste"}},
+			"pt": {{Name: "azeite", Quantity: 2, Unit: "tbsp"}, {Name: "suco de limão", Quantity: 1, Unit: "tbsp"}, {Name: "sal", Note: "a gosto"}},
+			"es": {{Name: "aceite de oliva", Quantity: 2, Unit: "tbsp"}, {Name: "zumo de limón", Quantity: 1, Unit: "tbsp"}, {Name: "sal", Note: "al gusto"}},
+			"fr": {{Name: "huile d'olive", Quantity: 2, Unit: "tbsp"}, {Name: "jus de citron", Quantity: 1, Unit: "tbsp"}, {Name: "sel", Note: "selon le goût"}},
+		},
+		Titles: map[string][]string{
+			"en": {"Fresh {{.Main}} Salad with {{.Others}}", "Bright {{.Main}} and {{.Others}} Bowl"},
+			"pt": {"Salada Fresca de {{.Main}} com {{.Others}}", "Bowl de {{.Main}} e {{.Others}}"},
+			"es": {"Ensalada Fresca de {{.Main}} con {{.Others}}", "Bol de {{.Main}} y {{.Others}}"},
+			"fr": {"Salade Fraîche de {{.Main}} aux {{.Others}}", "Bol de {{.Main}} et {{.Others}}"},
+		},
+		Steps: map[string][]string{
+			"en": {"Wash and dry the {{.All}}.", "Slice everything thinly and add it to a large bowl.", "Whisk the olive oil, lemon juice and salt.", "Dress the salad just before serving."},
+			"pt": {"Lave e seque {{.All}}.", "Fatie tudo finamente e coloque em uma tigela grande.", "Misture o azeite, o suco de limão e o sal.", "Tempere a salada logo antes de servir."},
+			"es": {"Lava y seca {{.All}}.", "Corta todo en láminas finas y ponlo en un bol grande.", "Bate el aceite de oliva, el zumo de limón y la sal.", "Aliña la ensalada justo antes de servir."},
+			"fr": {"Lavez et séchez {{.All}}.", "Émincez le tout finement et mettez-le dans un grand saladier.", "Fouettez l'huile d'olive, le jus de citron et le sel.", "Assaisonnez la salade juste avant de servir."},
+		},
+	},
+	{
+		Cuisine: "Italian", Tags: []string{"pasta", "family"},
+		Prep: [2]int{10, 15}, Cook: [2]int{12, 20}, Calories: [2]int{480, 720},
+		Staples: map[string][]recipe.Ingredient{
+			"en": {{Name: "pasta", Quantity: 250, Unit: "g"}, {Name: "olive oil", Quantity: 2, Unit: "tbsp"}, {Name: "parmesan", Quantity: 30, Unit: "g", Note: "grated"}},
+			"pt": {{Name: "massa", Quantity: 250, Unit: "g"}, {Name: "azeite", Quantity: 2, Unit: "tbsp"}, {Name: "parmesão", Quantity: 30, Unit: "g", Note: "ralado"}},
+			"es": {{Name: "pasta", Quantity: 250, Unit: "g"}, {Name: "aceite de oliva", Quantity: 2, Unit: "tbsp"}, {Name: "parmesano", Quantity: 30, Unit: "g", Note: "rallado"}},
+			"fr": {{Name: "pâtes", Quantity: 250, Unit: "g"}, {Name: "huile d'olive", Quantity: 2, Unit: "tbsp"}, {Name: "parmesan", Quantity: 30, Unit: "g", Note: "râpé"}},
+		},
+		Titles: map[string][]string{
+			"en": {"{{.Main}} Pasta with {{.Others}}", "Rustic {{.Main}} and {{.Others}} Pasta"},
+			"pt": {"Macarrão com {{.Main}} e {{.Others}}", "Massa Rústica de {{.Main}} com {{.Others}}"},
+			"es": {"Pasta con {{.Main}} y {{.Others}}", "Pasta Rústica de {{.Main}} con {{.Others}}"},
+			"fr": {"Pâtes au {{.Main}} et {{.Others}}", "Pâtes Rustiques au {{.Main}} et {{.Others}}"},
+		},
+		Steps: map[string][]string{
+			"en": {"Cook the pasta in salted boiling water until al dente.", "Meanwhile, sauté the {{.Main}} in olive oil for 5 minutes.", "Add the {{.Others}} and cook for 3 minutes.", "Toss with the drained pasta and a splash of cooking water.", "Finish with the grated parmesan."},
+			"pt": {"Cozinhe a massa em água fervente com sal até ficar al dente.", "Enquanto isso, refogue {{.Main}} no azeite por 5 minutos.", "Adicione {{.Others}} e cozinhe por 3 minutos.", "Misture com a massa escorrida e um pouco da água do cozimento.", "Finalize com o parmesão ralado."},
+			"es": {"Cuece la pasta en agua hirviendo con sal hasta que esté al dente.", "Mientras tanto, saltea {{.Main}} en aceite de oliva durante 5 minutos.", "Añade {{.Others}} y cocina 3 minutos.", "Mezcla con la pasta escurrida y un chorrito del agua de cocción.", "Termina con el parmesano rallado."},
+			"fr": {"Faites cuire les pâtes dans de l'eau bouillante salée jusqu'à ce qu'elles soient al dente.", "Pendant ce temps, faites revenir {{.Main}} dans l'huile d'olive 5 minutes.", "Ajoutez {{.Others}} et laissez cuire 3 minutes.", "Mélangez avec les pâtes égouttées et un peu d'eau de cuisson.", "Terminez avec le parmesan râpé."},
+		},
+	},
+	{
+		Cuisine: "Brunch", Tags: []string{"eggs", "quick"},
+		Prep: [2]int{5, 10}, Cook: [2]int{10, 20}, Calories: [2]int{300, 480},
+		Staples: map[string][]recipe.Ingredient{
+			"en": {{Name: "eggs", Quantity: 4, Unit: "unit"}, {Name: "butter", Quantity: 1, Unit: "tbsp"}, {Name: "salt", Note: "to taste"}},
+			"pt": {{Name: "ovos", Quantity: 4, Unit: "unit"}, {Name: "manteiga", Quantity: 1, Unit: "tbsp"}, {Name: "sal", Note: "a gosto"}},
+			"es": {{Name: "huevos", Quantity: 4, Unit: "unit"}, {Name: "mantequilla", Quantity: 1, Unit: "tbsp"}, {Name: "sal", Note: "al gusto"}},
+			"fr": {{Name: "œufs", Quantity: 4, Unit: "unit"}, {Name: "beurre", Quantity: 1, Unit: "tbsp"}, {Name: "sel", Note: "selon le goût"}},
+		},
+		Titles: map[string][]string{
+			"en": {"{{.Main}} Frittata with {{.Others}}", "Golden {{.Main}} and {{.Others}} Omelette"},
+			"pt": {"Fritada de {{.Main}} com {{.Others}}", "Omelete Dourada de {{.Main}} e {{.Others}}"},
+			"es": {"Frittata de {{.Main}} con {{.Others}}", "Tortilla Dorada de {{.Main}} y {{.Others}}"},
+			"fr": {"Frittata de {{.Main}} aux {{.Others}}", "Omelette Dorée de {{.Main}} et {{.Others}}"},
+		},
+		Steps: map[string][]string{
+			"en": {"Beat the eggs with a pinch of salt.", "Melt the butter in a non-stick pan and cook the {{.All}} for 5 minutes.", "Pour the eggs over and cook on low heat until almost set.", "Finish under the grill for 2 minutes and serve."},
+			"pt": {"Bata os ovos com uma pitada de sal.", "Derreta a manteiga em uma frigideira antiaderente e cozinhe {{.All}} por 5 minutos.", "Despeje os ovos e cozinhe em fogo baixo até quase firmar.", "Finalize no forno por 2 minutos e sirva."},
+			"es": {"Bate los huevos con una pizca de sal.", "Derrite la mantequilla en una sartén antiadherente y cocina {{.All}} durante 5 minutos.", "Vierte los huevos y cocina a fuego bajo hasta que casi cuajen.", "Termina 2 minutos bajo el gratinador y sirve."},
+			"fr": {"Battez les œufs avec une pincée de sel.", "Faites fondre le beurre dans une poêle antiadhésive et faites cuire {{.All}} 5 minutes.", "Versez les œufs et laissez cuire à feu doux jusqu'à ce qu'ils soient presque pris.", "Terminez 2 minutes sous le gril et servez."},
+		},
+	},
+}
+
+// Generate returns a recipe for req. Identical requests always produce the
+// same recipe; only latency and injected failures vary between calls.
+func (m *mockGenerator) Generate(ctx context.Context, req GenerateRequest) (*GenerateResponse, error) {
+	return m.generateVariant(ctx, req, 0)
+}
+
+// generateVariant returns alternative number variant for req. Variants of
+// the same request use different kinds of dish.
+func (m *mockGenerator) generateVariant(ctx context.Context, req GenerateRequest, variant int) (*GenerateResponse, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ingredients := normalizeIngredients(req.Ingredients)
+	if len(ingredients) == 0 {
+		ingredients = []string{"vegetables"}
+	}
+
+	// Sort first so the order the user typed doesn't change the recipe, then
+	// shuffle with the seeded source to pick the "main" ingredient.
+	sort.Strings(ingredients)
+	rng := rand.New(rand.NewPCG(m.seed(req, ingredients), m.cfg.Seed))
+	language := locale.Resolve(req.Language)
+	lang := language.Base()
+	dish := mockDishes[(rng.IntN(len(mockDishes))+variant)%len(mockDishes)]
+
+	rng.Shuffle(len(ingredients), func(i, j int) { ingredients[i], ingredients[j] = ingredients[j], ingredients[i] })
+
+	data := mockTemplateData{
+		Main:       ingredients[0],
+		Others:     joinList(ingredients[1:], lang),
+		All:        joinList(ingredients, lang),
+		Preference: req.Preferences,
+		Oven:       "200°C",
+	}
+	if language.Units == locale.Imperial {
+		data.Oven = "400°F"
+	}
+	if data.Others == "" {
+		data.Others = mockHerbs[lang][rng.IntN(len(mockHerbs[lang]))]
+	}
+
+	titles := dish.Titles[lang]
+	title := capitalize(renderMockTemplate(titles[rng.IntN(len(titles))], data))
+	if req.Preferences != "" {
+		title += " (" + req.Preferences + ")"
+	}
+
+	var steps []string
+	for _, step := range dish.Steps[lang] {
+		steps = append(steps, renderMockTemplate(step, data))
+	}
+
+	var lines []recipe.Ingredient
+	for _, name := range ingredients {
+		lines = append(lines, recipe.Ingredient{Name: name, Quantity: float64(50 * (2 + rng.IntN(7))), Unit: "g"})
+	}
+	lines = append(lines, dish.Staples[lang]...)
+	if language.Units == locale.Imperial {
+		for i := range lines {
+			lines[i] = imperialIngredient(lines[i])
+		}
+	}
+
+	resp := &GenerateResponse{
+		Title:       title,
+		Calories:    between(rng, dish.Calories),
+		Language:    req.Language,
+		Model:       ia.ProviderMock,
+		ParseStatus: ParseClean,
+		Details: recipe.Details{
+			Servings:        2 + rng.IntN(3),
+			PrepTimeMinutes: between(rng, dish.Prep),
+			CookTimeMinutes: between(rng, dish.Cook),
+			Cuisine:         dish.Cuisine,
+			Tags:            append([]string{"mock"}, dish.Tags...),
+			Ingredients:     lines,
+			Steps:           steps,
+		},
+	}
+	resp.render()
+
+	return resp, nil
+}
+
+// refine "revises" current by adding the instruction as a final step, which
+// is enough to exercise the refinement flow offline.
+func (m *mockGenerator) refine(ctx context.Context, current *GenerateResponse, instruction string) (*GenerateResponse, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	revised := *current
+	revised.Cached, revised.PantryUsed, revised.ExtraIngredients, revised.DietaryWarnings = false, nil, nil, nil
+	revised.Model = ia.ProviderMock
+	revised.Steps = append(slices.Clone(current.Steps), capitalize(strings.TrimRight(strings.TrimSpace(instruction), ".!"))+".")
+	revised.Tags = slices.Clone(current.Tags)
+	revised.Ingredients = slices.Clone(current.Ingredients)
+	if revised.IsStructured() {
+		revised.ParseStatus = ParseClean
+		revised.render()
+	} else {
+		revised.Content = strings.TrimSpace(current.Content) + "\n\n" + revised.Steps[len(revised.Steps)-1]
+	}
+	return &revised, nil
+}
+
+// wait applies the configured latency and failure injection.
+func (m *mockGenerator) wait(ctx context.Context) error {
+	if m.cfg.Latency > 0 {
+		timer := time.NewTimer(m.cfg.Latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if m.cfg.FailureRate > 0 && rand.Float64() < m.cfg.FailureRate {
+		return ErrMockFailure
+	}
+	return nil
+}
+
+// seed derives the per-request seed from the normalized request. ingredients
+// must already be normalized and sorted.
+func (m *mockGenerator) seed(req GenerateRequest, ingredients []string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(ingredients, "\x00")))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(req.Preferences))))
+	h.Write([]byte{0})
+	h.Write([]byte(mockLanguage(req.Language)))
+	return h.Sum64()
+}
+
+var mockHerbs = map[string][]string{
+	"en": {"fresh herbs", "garlic", "lemon", "chili"},
+	"pt": {"ervas frescas", "alho", "limão", "pimenta"},
+	"es": {"hierbas frescas", "ajo", "limón", "guindilla"},
+	"fr": {"herbes fraîches", "ail", "citron", "piment"},
+}
+
+// mockLanguage returns the base language the mock content is keyed by.
+func mockLanguage(language string) string {
+	return locale.Resolve(language).Base()
+}
+
+// normalizeIngredients lowercases, trims and de-duplicates ingredients
+// while keeping their original order.
+func normalizeIngredients(ingredients []string) []string {
+	seen := make(map[string]bool, len(ingredients))
+	var out []string
+	for _, ing := range ingredients {
+		ing = strings.ToLower(strings.TrimSpace(ing))
+		if ing == "" || seen[ing] {
+			continue
+		}
+		seen[ing] = true
+		out = append(out, ing)
+	}
+	return out
+}
+
+var listAnd = map[string]string{"en": " and ", "pt": " e ", "es": " y ", "fr": " et "}
+
+func joinList(items []string, lang string) string {
+	and := listAnd[lang]
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + and + items[len(items)-1]
+	}
+}
+
+// imperialIngredient converts the metric amounts the mock uses to US
+// customary units, rounded to kitchen-friendly values.
+func imperialIngredient(ing recipe.Ingredient) recipe.Ingredient {
+	switch ing.Unit {
+	case "g":
+		ing.Quantity, ing.Unit = math.Max(1, math.Round(ing.Quantity/28.35)), "oz"
+	case "l":
+		ing.Quantity, ing.Unit = math.Round(ing.Quantity*4.2*2)/2, "cups"
+	}
+	return ing
+}
+
+func renderMockTemplate(src string, data mockTemplateData) string {
+	var sb strings.Builder
+	template.Must(template.New("mock").Parse(src)).Execute(&sb, data)
+	return sb.String()
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+func between(rng *rand.Rand, r [2]int) int {
+	if r[1] <= r[0] {
+		return r[0]
+	}
+	return r[0] + rng.IntN(r[1]-r[0]+1)
+}
@@ -0,0 +1,93 @@
+package chef
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMockGeneratorIsDeterministic(t *testing.T) {
+	m := newMockGenerator(MockConfig{})
+	ctx := context.Background()
+
+	a, err := m.Generate(ctx, GenerateRequest{Ingredients: []string{"Chicken", "rice", "chicken "}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	b, err := m.Generate(ctx, GenerateRequest{Ingredients: []string{"rice", "chicken"}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected identical recipes for equivalent ingredient lists\n%+v\n%+v", a, b)
+	}
+	if !a.IsStructured() || a.Content == "" {
+		t.Errorf("expected a structured recipe with rendered content, got %+v", a)
+	}
+}
+
+func TestMockGeneratorVariesWithInput(t *testing.T) {
+	m := newMockGenerator(MockConfig{})
+	ctx := context.Background()
+
+	titles := make(map[string]bool)
+	for _, ing := range []string{"tomato", "potato", "salmon", "tofu", "spinach", "beef"} {
+		resp, err := m.Generate(ctx, GenerateRequest{Ingredients: []string{ing}})
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		titles[resp.Title] = true
+	}
+
+	if len(titles) < 2 {
+		t.Errorf("expected varied titles, got %v", titles)
+	}
+}
+
+func TestMockGeneratorLanguage(t *testing.T) {
+	m := newMockGenerator(MockConfig{})
+
+	resp, err := m.Generate(context.Background(), GenerateRequest{Ingredients: []string{"frango"}, Language: "pt"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !strings.Contains(resp.Content, "## Ingredientes") {
+		t.Errorf("expected Portuguese headings, got:\n%s", resp.Content)
+	}
+}
+
+func TestMockGeneratorLocalizesContentAndUnits(t *testing.T) {
+	m := newMockGenerator(MockConfig{})
+
+	for lang, heading := range map[string]string{"es-MX": "## Ingredientes", "fr": "## Ingrédients"} {
+		resp, err := m.Generate(context.Background(), GenerateRequest{Ingredients: []string{"tomate", "riz"}, Language: lang})
+		if err != nil {
+			t.Fatalf("Generate(%s): %v", lang, err)
+		}
+		if !strings.Contains(resp.Content, heading) || strings.Contains(resp.Content, " and ") {
+			t.Errorf("%s recipe is not localized:\n%s", lang, resp.Content)
+		}
+	}
+
+	resp, err := m.Generate(context.Background(), GenerateRequest{Ingredients: []string{"chicken"}, Language: "en-US"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for _, ing := range resp.Ingredients {
+		if ing.Unit == "g" || ing.Unit == "l" {
+			t.Errorf("en-US recipe uses metric unit: %+v", ing)
+		}
+	}
+}
+
+func TestMockGeneratorFailureInjection(t *testing.T) {
+	m := newMockGenerator(MockConfig{FailureRate: 1})
+
+	_, err := m.Generate(context.Background(), GenerateRequest{Ingredients: []string{"egg"}})
+	if !errors.Is(err, ErrMockFailure) {
+		t.Fatalf("expected ErrMockFailure, got %v", err)
+	}
+}
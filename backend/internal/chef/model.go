@@ -1,13 +1,147 @@
 package chef
 
+import (
+	"time"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/nutrition"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/google/uuid"
+)
+
 type GenerateRequest struct {
 	Ingredients []string `json:"ingredients"`
 	Preferences string   `json:"preferences"`
 	Language    string   `json:"language"`
+	// Regenerate skips the generation cache and replaces the cached entry.
+	Regenerate bool `json:"regenerate,omitempty"`
+	// Count asks for several distinct alternatives (1 to MaxAlternatives).
+	// 0, the omitted value, is treated as 1.
+	Count int `json:"count,omitempty"`
+	// PantryMode and MaxExtras control how the recipe uses the pantry; with
+	// a constrained mode Ingredients may be empty.
+	PantryMode PantryMode `json:"pantry_mode,omitempty"`
+	MaxExtras  int        `json:"max_extras,omitempty"`
+	// UseUp lists pantry item IDs the recipe should prioritize.
+	UseUp []uuid.UUID `json:"use_up,omitempty"`
 }
 
+// GenerateResponse is a generated recipe. Content is rendered from the
+// structured details whenever the model returned them.
 type GenerateResponse struct {
 	Title    string `json:"title"`
 	Content  string `json:"content"`
 	Calories int    `json:"calories"`
+	Language string `json:"language,omitempty"`
+	// Nutrition is computed from the ingredient lines, unlike Calories
+	// which is the model's estimate.
+	Nutrition *nutrition.Facts `json:"nutrition,omitempty"`
+	// ParseStatus reports whether the model output parsed cleanly, had to be
+	// repaired, or is degraded and must not be saved.
+	ParseStatus ParseStatus `json:"parse_status"`
+	// Cached is set when the recipe was served from the generation cache.
+	Cached bool `json:"cached,omitempty"`
+	// PantryUsed lists the pantry items the recipe uses and how much of
+	// each; ExtraIngredients are the ones the pantry doesn't have.
+	PantryUsed       []PantryUsage `json:"pantry_used,omitempty"`
+	ExtraIngredients []string      `json:"extra_ingredients,omitempty"`
+	// PromptVersion and Model record what produced the recipe, so they can
+	// be saved with it.
+	PromptVersion string `json:"prompt_version,omitempty"`
+	Model         string `json:"model,omitempty"`
+	// RevisionOf is the saved recipe a refinement started from; saving the
+	// refined recipe links it back.
+	RevisionOf *uuid.UUID `json:"revision_of,omitempty"`
+	// DietaryWarnings are non-blocking profile matches, such as dislikes.
+	DietaryWarnings []dietary.Violation `json:"dietary_warnings,omitempty"`
+	recipe.Details
+}
+
+// AlternativesResponse holds the recipes generated for a request with
+// Count > 1. Options that failed or were too similar to another one are
+// left out and described in Errors.
+type AlternativesResponse struct {
+	Recipes   []*GenerateResponse `json:"recipes"`
+	Requested int                 `json:"requested"`
+	Errors    []string            `json:"errors,omitempty"`
+}
+
+// RefineRequest revises a recipe following Instruction. The recipe is the
+// latest revision of SessionID, or starts a new session from a saved recipe
+// (RecipeID) or a generated one (Recipe).
+type RefineRequest struct {
+	SessionID   *uuid.UUID        `json:"session_id,omitempty"`
+	RecipeID    *uuid.UUID        `json:"recipe_id,omitempty"`
+	Recipe      *GenerateResponse `json:"recipe,omitempty"`
+	Instruction string            `json:"instruction"`
+}
+
+type RefineResponse struct {
+	SessionID uuid.UUID `json:"session_id"`
+	// Revision is the position of Recipe in the session; 0 is the original.
+	Revision int               `json:"revision"`
+	Recipe   *GenerateResponse `json:"recipe"`
+}
+
+// RefinementSession is the conversation history of a recipe: the original
+// followed by one turn per instruction.
+type RefinementSession struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"-"`
+	// RecipeID is the saved recipe the session started from, if any.
+	RecipeID  *uuid.UUID       `json:"recipe_id,omitempty"`
+	Turns     []RefinementTurn `json:"turns"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+type RefinementTurn struct {
+	Position    int               `json:"position"`
+	Instruction string            `json:"instruction,omitempty"`
+	Recipe      *GenerateResponse `json:"recipe"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// SubstitutionRequest asks for replacements for Ingredient, or for every
+// ingredient of the saved recipe RecipeID that the pantry doesn't cover.
+// Context describes the dish ("for a sponge cake") when there's no recipe.
+type SubstitutionRequest struct {
+	RecipeID   *uuid.UUID `json:"recipe_id,omitempty"`
+	Ingredient string     `json:"ingredient,omitempty"`
+	Context    string     `json:"context,omitempty"`
+	Language   string     `json:"language,omitempty"`
+}
+
+// SubstitutionSource tells where the substitutions of an ingredient came
+// from.
+type SubstitutionSource string
+
+const (
+	SubstitutionTable SubstitutionSource = "table"
+	SubstitutionModel SubstitutionSource = "model"
+	// SubstitutionNone means the ingredient is unknown and no model is
+	// available.
+	SubstitutionNone SubstitutionSource = "none"
+)
+
+type Substitution struct {
+	Name string `json:"name"`
+	// Ratio is how much of the substitute replaces one unit of the original.
+	Ratio float64 `json:"ratio"`
+	Notes string  `json:"notes,omitempty"`
+	// PantryItemID is set when the user already has the substitute.
+	PantryItemID *uuid.UUID `json:"pantry_item_id,omitempty"`
+	InPantry     bool       `json:"in_pantry"`
+}
+
+type IngredientSubstitutions struct {
+	Ingredient    string             `json:"ingredient"`
+	Source        SubstitutionSource `json:"source"`
+	Substitutions []Substitution     `json:"substitutions"`
+}
+
+type SubstitutionResponse struct {
+	Results []IngredientSubstitutions `json:"results"`
+	// Model is set when part of the results came from the model.
+	Model string `json:"model,omitempty"`
 }
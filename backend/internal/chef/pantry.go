@@ -0,0 +1,275 @@
+package chef
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/nutrition"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/pantry"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quantity"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/google/uuid"
+)
+
+// PantryMode controls how strictly a recipe must stick to the user's pantry.
+type PantryMode string
+
+const (
+	// PantryHint lists the pantry as optional extras (the default).
+	PantryHint PantryMode = ""
+	// PantryStrict only allows pantry items, the requested ingredients and
+	// basic staples.
+	PantryStrict PantryMode = "strict"
+	// PantryExtras allows at most MaxExtras items on top of those.
+	PantryExtras PantryMode = "extras"
+)
+
+// MaxPantryExtras is the largest MaxExtras accepted in PantryExtras mode.
+const MaxPantryExtras = 10
+
+var (
+	ErrEmptyPantry     = errors.New("pantry is empty")
+	ErrPantryViolation = errors.New("pantry mode violation")
+)
+
+// PantryViolationError lists the ingredients of a recipe that are not in
+// the pantry when more of them are used than the pantry mode allows.
+type PantryViolationError struct {
+	Extras []string
+	Limit  int
+}
+
+func (e *PantryViolationError) Error() string {
+	return fmt.Sprintf("uses %d ingredients that are not in the pantry (%s), at most %d allowed",
+		len(e.Extras), strings.Join(e.Extras, ", "), e.Limit)
+}
+
+func (e *PantryViolationError) Unwrap() error { return ErrPantryViolation }
+
+// basicStaples never count as extra ingredients.
+var basicStaples = map[string]bool{
+	"salt": true, "pepper": true, "black pepper": true, "water": true,
+	"oil": true, "olive oil": true, "vegetable oil": true,
+	"sal": true, "pimenta": true, "pimenta-do-reino": true, "água": true,
+	"óleo": true, "azeite": true, "óleo vegetal": true,
+}
+
+func (m PantryMode) Valid() bool {
+	return m == PantryHint || m == PantryStrict || m == PantryExtras
+}
+
+// constrained reports whether the recipe must be built from the pantry.
+func (m PantryMode) constrained() bool {
+	return m == PantryStrict || m == PantryExtras
+}
+
+// PantryUsage is the amount of a pantry item a recipe uses, so it can be
+// deducted from the pantry later.
+type PantryUsage struct {
+	PantryItemID uuid.UUID `json:"pantry_item_id"`
+	Name         string    `json:"name"`
+	Quantity     float64   `json:"quantity,omitempty"`
+	Unit         string    `json:"unit,omitempty"`
+	// Available is what the pantry holds, as entered by the user.
+	Available string `json:"available,omitempty"`
+	// Exceeds is set when the recipe needs more than Available.
+	Exceeds bool `json:"exceeds,omitempty"`
+}
+
+// pantryPlan is the pantry side of a generation request: what the prompt
+// says about the pantry and how the result is checked against it.
+type pantryPlan struct {
+	items     []*pantry.PantryItem
+	mode      PantryMode
+	maxExtras int
+	// allowed are the requested ingredients, which never count as extras.
+	allowed []string
+	prompt  string
+}
+
+// pantryPlan loads the user's pantry for req. In hint mode lookups are best
+// effort: a failure only means the model gets less context.
+func (s *ChefService) pantryPlan(ctx context.Context, userID uuid.UUID, req GenerateRequest) (*pantryPlan, error) {
+	plan := &pantryPlan{mode: req.PantryMode, maxExtras: req.MaxExtras, allowed: normalizeIngredients(req.Ingredients)}
+
+	if s.pantryService != nil {
+		items, err := s.pantryService.List(ctx, userID)
+		if err != nil && plan.mode.constrained() {
+			return nil, fmt.Errorf("load pantry: %w", err)
+		}
+		plan.items = items
+	}
+	if len(plan.items) == 0 && plan.mode.constrained() {
+		return nil, ErrEmptyPantry
+	}
+
+	plan.prompt = plan.describe(req.UseUp)
+	return plan, nil
+}
+
+func (p *pantryPlan) describe(useUp []uuid.UUID) string {
+	if len(p.items) == 0 {
+		return ""
+	}
+
+	var items, priority []string
+	for _, item := range p.items {
+		line := pantryLine(item)
+		items = append(items, line)
+		if slices.Contains(useUp, item.ID) {
+			priority = append(priority, line)
+		}
+	}
+
+	var sb strings.Builder
+	switch p.mode {
+	case PantryStrict:
+		fmt.Fprintf(&sb, "Use ONLY these pantry items (with the amounts available): %s. Besides the main ingredients above, the only other ingredients allowed are salt, pepper, oil and water.", strings.Join(items, ", "))
+	case PantryExtras:
+		fmt.Fprintf(&sb, "Cook from these pantry items (with the amounts available): %s. You may add at most %d other ingredients besides salt, pepper, oil and water.", strings.Join(items, ", "), p.maxExtras)
+	default:
+		fmt.Fprintf(&sb, "The user also has these items in their pantry (use them if needed): %s.", strings.Join(items, ", "))
+	}
+	sb.WriteString(" Never use more of an item than the pantry holds.")
+	if len(priority) > 0 {
+		fmt.Fprintf(&sb, " Prioritize using up: %s.", strings.Join(priority, ", "))
+	}
+	return sb.String()
+}
+
+func pantryLine(item *pantry.PantryItem) string {
+	amount := strings.TrimSpace(strings.TrimSpace(item.Quantity) + " " + strings.TrimSpace(item.Unit))
+	if amount == "" {
+		return item.Name
+	}
+	return fmt.Sprintf("%s (%s)", item.Name, amount)
+}
+
+// apply fills in which pantry items the recipe uses and which ingredients
+// are extras.
+func (p *pantryPlan) apply(r *GenerateResponse) {
+	r.PantryUsed, r.ExtraIngredients = nil, nil
+	for _, ing := range r.Ingredients {
+		if item := p.match(ing.Name); item != nil {
+			r.PantryUsed = append(r.PantryUsed, PantryUsage{
+				PantryItemID: item.ID,
+				Name:         item.Name,
+				Quantity:     ing.Quantity,
+				Unit:         ing.Unit,
+				Available:    strings.TrimSpace(item.Quantity + " " + item.Unit),
+				Exceeds:      exceedsPantry(ing, item),
+			})
+			continue
+		}
+		if !p.isAllowed(ing) {
+			r.ExtraIngredients = append(r.ExtraIngredients, ing.Name)
+		}
+	}
+}
+
+// exceedsPantry reports whether a recipe line needs more of an item than
+// the pantry holds. Amounts that can't be compared count as enough.
+func exceedsPantry(ing recipe.Ingredient, item *pantry.PantryItem) bool {
+	available, ok := item.Amount()
+	if !ok || ing.Quantity <= 0 {
+		return false
+	}
+	food, _ := nutrition.Default().Lookup(ing.Name)
+	needed, err := quantity.Quantity{Amount: ing.Quantity, Unit: ing.Unit}.Convert(available.Unit, food.Density)
+	if err != nil {
+		return false
+	}
+	return needed.Amount > available.Amount*1.001
+}
+
+// check rejects recipes that break the pantry mode so they get a corrective
+// retry.
+func (p *pantryPlan) check(r *GenerateResponse) error {
+	if !p.mode.constrained() {
+		return nil
+	}
+	p.apply(r)
+
+	limit := 0
+	if p.mode == PantryExtras {
+		limit = p.maxExtras
+	}
+	if len(r.ExtraIngredients) > limit {
+		return &PantryViolationError{Extras: r.ExtraIngredients, Limit: limit}
+	}
+	return nil
+}
+
+func (p *pantryPlan) isAllowed(ing recipe.Ingredient) bool {
+	name := strings.ToLower(strings.TrimSpace(ing.Name))
+	if basicStaples[name] {
+		return true
+	}
+	for _, allowed := range p.allowed {
+		if sameIngredient(name, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns the pantry item an ingredient refers to, if any.
+func (p *pantryPlan) match(name string) *pantry.PantryItem {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, item := range p.items {
+		if sameIngredient(name, strings.ToLower(strings.TrimSpace(item.Name))) {
+			return item
+		}
+	}
+	return nil
+}
+
+// sameIngredient matches names that are equal or where one contains the
+// other as whole words ("chicken" and "chicken breast"), ignoring plurals.
+func sameIngredient(a, b string) bool {
+	a, b = singular(a), singular(b)
+	if a == "" || b == "" {
+		return false
+	}
+	return a == b || containsWords(a, b) || containsWords(b, a)
+}
+
+func containsWords(s, sub string) bool {
+	return strings.Contains(" "+s+" ", " "+sub+" ")
+}
+
+// singular crudely singularizes every word of an English ingredient name.
+func singular(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		switch {
+		case len(w) > 4 && strings.HasSuffix(w, "ies"):
+			words[i] = strings.TrimSuffix(w, "ies") + "y"
+		case len(w) > 4 && (strings.HasSuffix(w, "oes") || strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes")):
+			words[i] = strings.TrimSuffix(w, "es")
+		case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+			words[i] = strings.TrimSuffix(w, "s")
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// mockIngredients adds pantry items to the mock request in constrained
+// modes, since the mock has no model to pick them.
+func (p *pantryPlan) mockIngredients(req GenerateRequest) GenerateRequest {
+	if !p.mode.constrained() {
+		return req
+	}
+	ingredients := slices.Clone(req.Ingredients)
+	for _, item := range p.items {
+		if len(ingredients) >= 4 {
+			break
+		}
+		ingredients = append(ingredients, item.Name)
+	}
+	req.Ingredients = ingredients
+	return req
+}
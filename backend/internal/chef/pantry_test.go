@@ -0,0 +1,137 @@
+package chef
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/pantry"
+	"github.com/google/uuid"
+)
+
+func testPlan(mode PantryMode, maxExtras int) *pantryPlan {
+	return &pantryPlan{
+		mode:      mode,
+		maxExtras: maxExtras,
+		allowed:   []string{"egg"},
+		items: []*pantry.PantryItem{
+			{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Name: "Rice", Quantity: "500", Unit: "g"},
+			{ID: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Name: "tomato", Quantity: "3"},
+		},
+	}
+}
+
+func TestPantryPlanDescribe(t *testing.T) {
+	plan := testPlan(PantryExtras, 2)
+	got := plan.describe([]uuid.UUID{plan.items[1].ID})
+
+	for _, want := range []string{"Rice (500 g)", "tomato (3)", "at most 2 other ingredients", "Prioritize using up: tomato (3)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("describe() = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestPantryPlanApply(t *testing.T) {
+	resp, _, err := parseRecipeOutput(`{"title":"Tomato Rice","ingredients":[
+		{"name":"rice","quantity":200,"unit":"g"},
+		{"name":"tomatoes","quantity":2},
+		{"name":"egg","quantity":1},
+		{"name":"salt"},
+		{"name":"parmesan","quantity":30,"unit":"g"}
+	],"steps":["Cook."]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testPlan(PantryStrict, 0).apply(resp)
+
+	if len(resp.PantryUsed) != 2 {
+		t.Fatalf("PantryUsed = %+v, want rice and tomato", resp.PantryUsed)
+	}
+	if u := resp.PantryUsed[0]; u.Name != "Rice" || u.Quantity != 200 || u.Unit != "g" || u.Available != "500 g" {
+		t.Errorf("rice usage = %+v", u)
+	}
+	if strings.Join(resp.ExtraIngredients, ",") != "parmesan" {
+		t.Errorf("ExtraIngredients = %v, want [parmesan]", resp.ExtraIngredients)
+	}
+}
+
+func TestPantryUsageExceeds(t *testing.T) {
+	resp, _, err := parseRecipeOutput(`{"title":"Rice Bowl","ingredients":[
+		{"name":"rice","quantity":1,"unit":"kg"},
+		{"name":"tomato","quantity":3}
+	],"steps":["Cook."]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testPlan(PantryHint, 0).apply(resp)
+
+	if len(resp.PantryUsed) != 2 || !resp.PantryUsed[0].Exceeds || resp.PantryUsed[1].Exceeds {
+		t.Errorf("PantryUsed = %+v, want only rice to exceed the pantry", resp.PantryUsed)
+	}
+}
+
+func TestGenerateRetriesWhenPantryModeIsBroken(t *testing.T) {
+	gen := &scriptedGenerator{outputs: []string{
+		`{"title":"Fancy Rice","ingredients":["200 g rice","50 g parmesan","1 truffle"],"steps":["Cook."]}`,
+		`{"title":"Tomato Rice","ingredients":["200 g rice","2 tomato"],"steps":["Cook."]}`,
+	}}
+	s := &ChefService{generator: gen, parseRetries: 1}
+	plan := testPlan(PantryExtras, 1)
+	req := GenerateRequest{PantryMode: PantryExtras, MaxExtras: 1}
+
+	resp, err := s.generate(context.Background(), req, testPrompt(t, req, plan.prompt), plan.check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Title != "Tomato Rice" || len(resp.ExtraIngredients) != 0 {
+		t.Errorf("got %q with extras %v", resp.Title, resp.ExtraIngredients)
+	}
+	if len(gen.prompts) != 2 || !strings.Contains(gen.prompts[1], "not in the pantry") {
+		t.Errorf("expected a corrective prompt about the pantry, got %d prompts", len(gen.prompts))
+	}
+}
+
+func TestGenerateRejectsRecipeThatStillBreaksPantryMode(t *testing.T) {
+	gen := &scriptedGenerator{outputs: []string{
+		`{"title":"Fancy Rice","ingredients":["200 g rice","50 g parmesan","1 truffle"],"steps":["Cook."]}`,
+	}}
+	s := &ChefService{generator: gen, parseRetries: 1}
+	plan := testPlan(PantryStrict, 0)
+	req := GenerateRequest{PantryMode: PantryStrict}
+
+	resp, err := s.generate(context.Background(), req, testPrompt(t, req, plan.prompt), plan.check)
+	var pantryErr *PantryViolationError
+	if !errors.As(err, &pantryErr) || resp != nil {
+		t.Fatalf("got %v, %v; want a pantry violation", resp, err)
+	}
+	if strings.Join(pantryErr.Extras, ",") != "parmesan,truffle" {
+		t.Errorf("Extras = %v", pantryErr.Extras)
+	}
+	if status, _ := generateErrorStatus(err, "en"); status != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", status)
+	}
+}
+
+func TestStreamRejectsRecipeThatBreaksPantryMode(t *testing.T) {
+	gen := &scriptedGenerator{outputs: []string{
+		"# Fancy Rice\n\n## Ingredients\n- 200 g rice\n- 50 g parmesan\n\n## Instructions\n1. Cook.\n",
+	}}
+	s := &ChefService{generator: gen}
+	plan := &generationPlan{pantry: testPlan(PantryStrict, 0), diet: dietary.NewChecker(nil), prompts: builtinPrompts().For(uuid.Nil)}
+	req := GenerateRequest{PantryMode: PantryStrict}
+
+	resp, err := s.stream(context.Background(), req, plan, func(string) error { return nil })
+	var pantryErr *PantryViolationError
+	if !errors.As(err, &pantryErr) || resp != nil {
+		t.Fatalf("got %v, %v; want a pantry violation", resp, err)
+	}
+	if strings.Join(pantryErr.Extras, ",") != "parmesan" {
+		t.Errorf("Extras = %v", pantryErr.Extras)
+	}
+}
@@ -0,0 +1,301 @@
+package chef
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+)
+
+// ParseStatus tells the client how much the model output had to be fixed.
+type ParseStatus string
+
+const (
+	// ParseClean means the output was valid JSON matching the schema.
+	ParseClean ParseStatus = "clean"
+	// ParseRepaired means the JSON had to be extracted or fixed, or a
+	// corrective retry was needed.
+	ParseRepaired ParseStatus = "repaired"
+	// ParseDegraded means no valid recipe could be recovered; the response
+	// only carries the raw text and must not be saved.
+	ParseDegraded ParseStatus = "degraded"
+)
+
+var errNoJSONObject = errors.New("no JSON object found")
+
+// parseRecipeOutput turns raw model output into a validated recipe.
+// It tries a strict decode first, then extraction and repair.
+func parseRecipeOutput(text string) (*GenerateResponse, ParseStatus, error) {
+	trimmed := trimCodeFence(text)
+
+	if resp, err := decodeRecipe([]byte(trimmed)); err == nil {
+		if err := validateRecipe(resp); err != nil {
+			return nil, "", err
+		}
+		return resp, ParseClean, nil
+	}
+
+	object, err := extractJSONObject(trimmed)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := decodeRecipe(repairJSON(object))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := validateRecipe(resp); err != nil {
+		return nil, "", err
+	}
+
+	return resp, ParseRepaired, nil
+}
+
+func trimCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+			text = text[nl+1:]
+		}
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	}
+	return strings.TrimSpace(text)
+}
+
+// extractJSONObject returns the first balanced {...} object in text, ignoring
+// braces that appear inside string literals.
+func extractJSONObject(text string) (string, error) {
+	start := strings.IndexByte(text, '{')
+	if start < 0 {
+		return "", errNoJSONObject
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return text[start : i+1], nil
+			}
+		}
+	}
+
+	return "", errNoJSONObject
+}
+
+var smartQuotes = strings.NewReplacer(
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`,
+	"‘", "'", "’", "'",
+)
+
+// repairJSON fixes the mistakes models make most often: typographic quotes
+// used as string delimiters and trailing commas before } or ].
+func repairJSON(object string) []byte {
+	src := []byte(smartQuotes.Replace(object))
+
+	var out bytes.Buffer
+	inString := false
+	escaped := false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			out.WriteByte(c)
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			j := i + 1
+			for j < len(src) && (src[j] == ' ' || src[j] == '\n' || src[j] == '\r' || src[j] == '\t') {
+				j++
+			}
+			if j < len(src) && (src[j] == '}' || src[j] == ']') {
+				continue
+			}
+		}
+		out.WriteByte(c)
+	}
+
+	return out.Bytes()
+}
+
+// rawRecipe accepts the loose shapes models produce: numbers as strings and
+// ingredients as plain text lines.
+type rawRecipe struct {
+	Title           string          `json:"title"`
+	Calories        flexNumber      `json:"calories"`
+	Servings        flexNumber      `json:"servings"`
+	PrepTimeMinutes flexNumber      `json:"prep_time_minutes"`
+	CookTimeMinutes flexNumber      `json:"cook_time_minutes"`
+	Cuisine         string          `json:"cuisine"`
+	Tags            []string        `json:"tags"`
+	Ingredients     []rawIngredient `json:"ingredients"`
+	Steps           []string        `json:"steps"`
+}
+
+type rawIngredient recipe.Ingredient
+
+func (i *rawIngredient) UnmarshalJSON(data []byte) error {
+	var line string
+	if err := json.Unmarshal(data, &line); err == nil {
+		*i = rawIngredient(recipe.ParseIngredientLine(line))
+		return nil
+	}
+
+	var obj struct {
+		Name     string     `json:"name"`
+		Quantity flexNumber `json:"quantity"`
+		Unit     string     `json:"unit"`
+		Note     string     `json:"note"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*i = rawIngredient{Name: obj.Name, Quantity: float64(obj.Quantity), Unit: obj.Unit, Note: obj.Note}
+	return nil
+}
+
+type flexNumber float64
+
+func (n *flexNumber) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var f float64
+	if err := json.Unmarshal(data, &f); err == nil {
+		*n = flexNumber(f)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	// Accept "450 kcal", "~20", "4 servings".
+	fields := strings.Fields(strings.TrimLeft(s, "~≈ "))
+	if len(fields) == 0 {
+		return nil
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", "."), 64)
+	if err != nil {
+		return fmt.Errorf("not a number: %q", s)
+	}
+	*n = flexNumber(f)
+	return nil
+}
+
+func decodeRecipe(data []byte) (*GenerateResponse, error) {
+	var raw rawRecipe
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	resp := &GenerateResponse{
+		Title:    strings.TrimSpace(raw.Title),
+		Calories: int(raw.Calories + 0.5),
+		Details: recipe.Details{
+			Servings:        int(raw.Servings + 0.5),
+			PrepTimeMinutes: int(raw.PrepTimeMinutes + 0.5),
+			CookTimeMinutes: int(raw.CookTimeMinutes + 0.5),
+			Cuisine:         raw.Cuisine,
+			Tags:            raw.Tags,
+		},
+	}
+	for _, ing := range raw.Ingredients {
+		if ing.Name = strings.TrimSpace(ing.Name); ing.Name != "" {
+			resp.Ingredients = append(resp.Ingredients, recipe.Ingredient(ing))
+		}
+	}
+	for _, step := range raw.Steps {
+		if step = strings.TrimSpace(step); step != "" {
+			resp.Steps = append(resp.Steps, step)
+		}
+	}
+
+	return resp, nil
+}
+
+func validateRecipe(resp *GenerateResponse) error {
+	var missing []string
+	if resp.Title == "" {
+		missing = append(missing, "title")
+	}
+	if len(resp.Ingredients) == 0 {
+		missing = append(missing, "ingredients")
+	}
+	if len(resp.Steps) == 0 {
+		missing = append(missing, "steps")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required fields: %s", strings.Join(missing, ", "))
+	}
+	if resp.Calories < 0 || resp.Servings < 0 {
+		return errors.New("calories and servings must not be negative")
+	}
+	return nil
+}
+
+// correctionPrompt asks the model to fix its previous answer.
+func correctionPrompt(original, previous string, parseErr error) string {
+	const maxEcho = 4000
+	if len(previous) > maxEcho {
+		previous = previous[:maxEcho]
+	}
+	return fmt.Sprintf(`%s
+
+Your previous answer could not be used: %v.
+Previous answer:
+%s
+
+Reply again with ONLY the corrected JSON object, following the structure above exactly.`, original, parseErr, previous)
+}
+
+// degradedRecipe wraps output that could not be parsed so the user still sees
+// something, without pretending it is a valid recipe.
+func degradedRecipe(text string, req GenerateRequest) *GenerateResponse {
+	content := trimCodeFence(text)
+	return &GenerateResponse{
+		Title:       fallbackTitle(req),
+		Content:     content,
+		Language:    req.Language,
+		ParseStatus: ParseDegraded,
+	}
+}
+
+func fallbackTitle(req GenerateRequest) string {
+	if len(req.Ingredients) == 0 {
+		return "Pantry recipe"
+	}
+	return fmt.Sprintf("Recipe with %s", req.Ingredients[0])
+}
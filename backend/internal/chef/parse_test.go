@@ -0,0 +1,119 @@
+package chef
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/google/uuid"
+)
+
+func TestParseRecipeOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		status ParseStatus
+	}{
+		{
+			name:   "clean",
+			text:   `{"title":"Rice","calories":300,"servings":2,"ingredients":[{"name":"rice","quantity":200,"unit":"g"}],"steps":["Cook."]}`,
+			status: ParseClean,
+		},
+		{
+			name:   "fenced",
+			text:   "```json\n{\"title\":\"Rice\",\"calories\":300,\"ingredients\":[\"200 g rice\"],\"steps\":[\"Cook.\"]}\n```",
+			status: ParseClean,
+		},
+		{
+			name:   "prose and trailing commas",
+			text:   "Sure! Here is your recipe:\n{\"title\":\"Rice {easy}\",\"calories\":\"300 kcal\",\"ingredients\":[{\"name\":\"rice\",},],\"steps\":[\"Cook.\",],}\nEnjoy!",
+			status: ParseRepaired,
+		},
+		{
+			name:   "smart quotes",
+			text:   `{“title”: “Rice”, “calories”: 300, “ingredients”: [“rice”], “steps”: [“Cook.”]}`,
+			status: ParseRepaired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, status, err := parseRecipeOutput(tt.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status != tt.status {
+				t.Errorf("status = %s; want %s", status, tt.status)
+			}
+			if resp.Title == "" || resp.Calories != 300 || len(resp.Ingredients) != 1 || len(resp.Steps) != 1 {
+				t.Errorf("unexpected recipe: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestParseRecipeOutputRejectsInvalid(t *testing.T) {
+	for _, text := range []string{
+		"I can't help with that.",
+		`{"title":"Rice","calories":300}`,
+		`{"title":"Rice","ingredients":["rice"],"steps":["Cook."]`,
+	} {
+		if _, _, err := parseRecipeOutput(text); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+}
+
+type scriptedGenerator struct {
+	outputs []string
+	prompts []string
+}
+
+func (g *scriptedGenerator) Generate(_ context.Context, prompt ia.Prompt) (*ia.Completion, error) {
+	g.prompts = append(g.prompts, prompt.Text)
+	out := g.outputs[0]
+	if len(g.outputs) > 1 {
+		g.outputs = g.outputs[1:]
+	}
+	return &ia.Completion{Text: out, Model: "scripted"}, nil
+}
+
+func (g *scriptedGenerator) Provider() string { return "scripted" }
+
+func (g *scriptedGenerator) Model() string { return "scripted" }
+
+func TestGenerateRecipeRetriesInvalidOutput(t *testing.T) {
+	gen := &scriptedGenerator{outputs: []string{
+		`{"title":"Rice"}`,
+		`{"title":"Rice","calories":300,"ingredients":["rice"],"steps":["Cook."]}`,
+	}}
+	s := &ChefService{generator: gen, parseRetries: 1}
+
+	resp, err := s.GenerateRecipe(context.Background(), uuid.New(), GenerateRequest{Ingredients: []string{"rice"}})
+	if err != nil {
+		t.Fatalf("GenerateRecipe: %v", err)
+	}
+	if resp.ParseStatus != ParseRepaired {
+		t.Errorf("status = %s; want %s", resp.ParseStatus, ParseRepaired)
+	}
+	if len(gen.prompts) != 2 || !strings.Contains(gen.prompts[1], "missing required fields") {
+		t.Errorf("expected a corrective second prompt, got %d prompts", len(gen.prompts))
+	}
+}
+
+func TestGenerateRecipeDegradesAfterRetries(t *testing.T) {
+	gen := &scriptedGenerator{outputs: []string{"no recipe today"}}
+	s := &ChefService{generator: gen, parseRetries: 1}
+
+	resp, err := s.GenerateRecipe(context.Background(), uuid.New(), GenerateRequest{Ingredients: []string{"rice"}})
+	if err != nil {
+		t.Fatalf("GenerateRecipe: %v", err)
+	}
+	if resp.ParseStatus != ParseDegraded {
+		t.Errorf("status = %s; want %s", resp.ParseStatus, ParseDegraded)
+	}
+	if len(gen.prompts) != 2 {
+		t.Errorf("expected 2 attempts, got %d", len(gen.prompts))
+	}
+}
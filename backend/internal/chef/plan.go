@@ -0,0 +1,110 @@
+package chef
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/google/uuid"
+)
+
+// generationPlan is the per-user context of a generation: the pantry, the
+// dietary profile and the prompt version. prompt is what the model is told
+// about the pantry and the profile and is part of the cache key.
+type generationPlan struct {
+	pantry  *pantryPlan
+	diet    *dietary.Checker
+	prompt  string
+	prompts *PromptSet
+}
+
+// plan loads the user's context for req. The dietary profile is not best
+// effort: if it can't be loaded, nothing is generated.
+func (s *ChefService) plan(ctx context.Context, userID uuid.UUID, req GenerateRequest) (*generationPlan, error) {
+	pantry, err := s.pantryPlan(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile *dietary.Profile
+	if s.dietaryService != nil {
+		if profile, err = s.dietaryService.Get(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	diet := dietary.NewChecker(profile)
+	if v := dietary.Blocking(diet.Check(req.Ingredients...)); len(v) > 0 {
+		return nil, &dietary.ViolationError{Subject: "the requested ingredients", Violations: v}
+	}
+
+	prompt := strings.TrimSpace(pantry.prompt + "\n" + diet.Prompt())
+	return &generationPlan{pantry: pantry, diet: diet, prompt: prompt, prompts: s.promptSet(userID)}, nil
+}
+
+func (s *ChefService) promptSet(userID uuid.UUID) *PromptSet {
+	if s.prompts == nil {
+		return builtinPrompts().For(userID)
+	}
+	return s.prompts.For(userID)
+}
+
+// check rejects recipes that break the pantry mode or the dietary profile,
+// so they get a corrective retry.
+func (p *generationPlan) check(r *GenerateResponse) error {
+	if err := p.pantry.check(r); err != nil {
+		return err
+	}
+	if v := dietary.Blocking(p.diet.Check(recipeTexts(r)...)); len(v) > 0 {
+		return &dietary.ViolationError{Subject: "the recipe", Violations: v}
+	}
+	return nil
+}
+
+// finish fills in the pantry usage and dietary warnings of a recipe about to
+// be returned. Recipes that still break the profile are never returned.
+func (p *generationPlan) finish(r *GenerateResponse) error {
+	p.pantry.apply(r)
+
+	violations := p.diet.Check(recipeTexts(r)...)
+	if blocking := dietary.Blocking(violations); len(blocking) > 0 {
+		return &dietary.ViolationError{Subject: "the recipe", Violations: blocking}
+	}
+	r.DietaryWarnings = violations
+	return nil
+}
+
+// accept checks and finishes a recipe that check has not seen, such as a
+// cache hit or a streamed recipe.
+func (p *generationPlan) accept(r *GenerateResponse) error {
+	if err := p.check(r); err != nil {
+		return err
+	}
+	return p.finish(r)
+}
+
+// recipeTexts is everything the user will read about a recipe.
+func recipeTexts(r *GenerateResponse) []string {
+	texts := []string{r.Title, r.Content}
+	for _, ing := range r.Ingredients {
+		texts = append(texts, ing.String())
+	}
+	return append(texts, r.Steps...)
+}
+
+// mockRecipe returns the first mock variant, starting at variant, that fits
+// the plan.
+func (s *ChefService) mockRecipe(ctx context.Context, p *generationPlan, req GenerateRequest, variant int) (*GenerateResponse, error) {
+	req = p.pantry.mockIngredients(req)
+
+	var err error
+	for i := range len(mockDishes) {
+		var resp *GenerateResponse
+		if resp, err = s.mock.generateVariant(ctx, req, variant+i); err != nil {
+			return nil, err
+		}
+		if err = p.finish(resp); err == nil {
+			return resp, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,90 @@
+package chef
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/google/uuid"
+)
+
+func testPrompt(t *testing.T, req GenerateRequest, pantry string) string {
+	t.Helper()
+	prompt, err := builtinPrompts().For(uuid.Nil).Recipe(req, pantry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prompt
+}
+
+func dietPlan(p *dietary.Profile) *generationPlan {
+	diet := dietary.NewChecker(p)
+	return &generationPlan{pantry: &pantryPlan{}, diet: diet, prompt: diet.Prompt()}
+}
+
+func TestGenerateRetriesWhenDietIsBroken(t *testing.T) {
+	gen := &scriptedGenerator{outputs: []string{
+		`{"title":"Cashew Stir-fry","ingredients":["200 g rice","50 g cashews"],"steps":["Cook."]}`,
+		`{"title":"Tofu Stir-fry","ingredients":["200 g rice","150 g tofu"],"steps":["Cook."]}`,
+	}}
+	s := &ChefService{generator: gen, parseRetries: 1}
+	plan := dietPlan(&dietary.Profile{Allergens: []string{"tree_nuts"}})
+	req := GenerateRequest{Ingredients: []string{"rice"}}
+
+	resp, err := s.generate(context.Background(), req, testPrompt(t, req, plan.prompt), plan.check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.finish(resp); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	if resp.Title != "Tofu Stir-fry" {
+		t.Errorf("title = %q, want the corrected recipe", resp.Title)
+	}
+	if len(gen.prompts) != 2 || !strings.Contains(gen.prompts[1], "cashew") {
+		t.Errorf("expected a corrective prompt about cashews, got %d prompts", len(gen.prompts))
+	}
+}
+
+func TestGenerateRejectsRecipeThatStillBreaksDiet(t *testing.T) {
+	gen := &scriptedGenerator{outputs: []string{
+		`{"title":"Cashew Stir-fry","ingredients":["200 g rice","50 g cashews"],"steps":["Cook."]}`,
+	}}
+	s := &ChefService{generator: gen, parseRetries: 1}
+	plan := dietPlan(&dietary.Profile{Allergens: []string{"tree_nuts"}})
+	req := GenerateRequest{Ingredients: []string{"rice"}}
+
+	resp, err := s.generate(context.Background(), req, testPrompt(t, req, plan.prompt), plan.check)
+	if err == nil {
+		err = plan.finish(resp)
+	}
+	if !errors.Is(err, dietary.ErrProfileViolation) {
+		t.Fatalf("err = %v, want a profile violation", err)
+	}
+}
+
+func TestDietGuardStopsBeforeForbiddenWord(t *testing.T) {
+	var sent strings.Builder
+	guard := &dietGuard{
+		diet: dietary.NewChecker(&dietary.Profile{Diets: []string{"vegetarian"}}),
+		onToken: func(s string) error {
+			sent.WriteString(s)
+			return nil
+		},
+	}
+
+	var err error
+	for _, token := range []string{"# Rice", " with ", "chi", "cken", " and peas"} {
+		if err = guard.write(token); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, dietary.ErrProfileViolation) {
+		t.Fatalf("err = %v, want a profile violation", err)
+	}
+	if strings.Contains(sent.String(), "chi") {
+		t.Errorf("client received %q", sent.String())
+	}
+}
@@ -0,0 +1,100 @@
+package chef
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+)
+
+// nativeInstructions repeat the language instruction in the language
+// itself, which keeps models from drifting back to English.
+var nativeInstructions = map[string]string{
+	"pt": "Escreva toda a receita em português.",
+	"es": "Escribe toda la receta en español.",
+	"fr": "Rédigez toute la recette en français.",
+}
+
+func languageInstruction(tag string) string {
+	l := locale.Resolve(tag)
+	instruction := fmt.Sprintf("Respond in %s (%s).", l.Name, l.Tag)
+	if native := nativeInstructions[l.Base()]; native != "" {
+		instruction += " " + native
+	}
+	return instruction
+}
+
+func unitsInstruction(tag string) string {
+	if locale.Resolve(tag).Units == locale.Imperial {
+		return "Use US customary units (oz, lb, cups, tbsp, tsp) and °F."
+	}
+	return "Use metric units (g, kg, ml, l) and °C."
+}
+
+func mainIngredients(req GenerateRequest) string {
+	if len(req.Ingredients) == 0 {
+		return "Create a recipe from the user's pantry."
+	}
+	return fmt.Sprintf("Create a recipe using these main ingredients: %s.", strings.Join(req.Ingredients, ", "))
+}
+
+// recipeSchema mirrors the recipe prompt so providers with structured output can
+// enforce it.
+var recipeSchema = &ia.Schema{
+	Type: "object",
+	Properties: map[string]*ia.Schema{
+		"title":             {Type: "string"},
+		"calories":          {Type: "integer", Description: "Estimated calories per serving"},
+		"servings":          {Type: "integer"},
+		"prep_time_minutes": {Type: "integer"},
+		"cook_time_minutes": {Type: "integer"},
+		"cuisine":           {Type: "string"},
+		"tags":              {Type: "array", Items: &ia.Schema{Type: "string"}},
+		"ingredients": {
+			Type: "array",
+			Items: &ia.Schema{
+				Type: "object",
+				Properties: map[string]*ia.Schema{
+					"name":     {Type: "string"},
+					"quantity": {Type: "number"},
+					"unit":     {Type: "string"},
+					"note":     {Type: "string"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		"steps": {Type: "array", Items: &ia.Schema{Type: "string"}},
+	},
+	Required: []string{"title", "calories", "servings", "ingredients", "steps"},
+}
+
+// substitutionSchema mirrors the substitute prompt.
+var substitutionSchema = &ia.Schema{
+	Type: "object",
+	Properties: map[string]*ia.Schema{
+		"ingredients": {
+			Type: "array",
+			Items: &ia.Schema{
+				Type: "object",
+				Properties: map[string]*ia.Schema{
+					"ingredient": {Type: "string"},
+					"substitutes": {
+						Type: "array",
+						Items: &ia.Schema{
+							Type: "object",
+							Properties: map[string]*ia.Schema{
+								"name":  {Type: "string"},
+								"ratio": {Type: "number", Description: "Amount of the substitute per unit of the ingredient"},
+								"notes": {Type: "string"},
+							},
+							Required: []string{"name", "ratio"},
+						},
+					},
+				},
+				Required: []string{"ingredient", "substitutes"},
+			},
+		},
+	},
+	Required: []string{"ingredients"},
+}
@@ -0,0 +1,198 @@
+package chef
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxRefinements is how many instructions a session accepts.
+	MaxRefinements = 20
+	// MaxInstructionLength is the longest instruction accepted, in runes.
+	MaxInstructionLength = 500
+	// refineHistory is how many earlier instructions the prompt repeats.
+	refineHistory = 10
+)
+
+var (
+	ErrSessionNotFound = errors.New("refinement session not found")
+	ErrSessionFull     = fmt.Errorf("a session accepts at most %d refinements, save the recipe and refine it again", MaxRefinements)
+)
+
+// SessionStore persists refinement sessions.
+type SessionStore interface {
+	Create(ctx context.Context, session *RefinementSession) error
+	Get(ctx context.Context, id, userID uuid.UUID) (*RefinementSession, error)
+	AppendTurn(ctx context.Context, sessionID uuid.UUID, turn *RefinementTurn) error
+}
+
+// RecipeSource loads saved recipes to refine.
+type RecipeSource interface {
+	GetRecipe(ctx context.Context, id, userID uuid.UUID) (*recipe.Recipe, error)
+}
+
+// RefineService revises recipes through follow-up instructions ("make it
+// spicier"), keeping the conversation of every session.
+type RefineService struct {
+	chef     *ChefService
+	sessions SessionStore
+	recipes  RecipeSource
+}
+
+func NewRefineService(chef *ChefService, sessions SessionStore, recipes RecipeSource) *RefineService {
+	return &RefineService{chef: chef, sessions: sessions, recipes: recipes}
+}
+
+// Refine applies req.Instruction to the latest recipe of the session, or
+// starts a session when the request names a recipe instead.
+func (s *RefineService) Refine(ctx context.Context, userID uuid.UUID, req RefineRequest) (*RefineResponse, error) {
+	session, err := s.session(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(session.Turns)-1 >= MaxRefinements {
+		return nil, ErrSessionFull
+	}
+
+	current := session.Turns[len(session.Turns)-1].Recipe
+	var history []string
+	for _, turn := range session.Turns[1:] {
+		history = append(history, turn.Instruction)
+	}
+	if len(history) > refineHistory {
+		history = history[len(history)-refineHistory:]
+	}
+
+	revised, err := s.chef.refine(ctx, userID, current, history, req.Instruction)
+	if err != nil {
+		return nil, err
+	}
+	revised.RevisionOf = session.RecipeID
+
+	turn := RefinementTurn{Position: len(session.Turns), Instruction: req.Instruction, Recipe: revised}
+	if session.ID == uuid.Nil {
+		// New sessions are only stored once the first refinement worked.
+		session.Turns = append(session.Turns, turn)
+		err = s.sessions.Create(ctx, session)
+	} else {
+		err = s.sessions.AppendTurn(ctx, session.ID, &turn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &RefineResponse{SessionID: session.ID, Revision: turn.Position, Recipe: revised}, nil
+}
+
+// Session returns one of the user's sessions.
+func (s *RefineService) Session(ctx context.Context, userID, id uuid.UUID) (*RefinementSession, error) {
+	session, err := s.sessions.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (s *RefineService) session(ctx context.Context, userID uuid.UUID, req RefineRequest) (*RefinementSession, error) {
+	if req.SessionID != nil {
+		return s.Session(ctx, userID, *req.SessionID)
+	}
+
+	session := &RefinementSession{UserID: userID}
+	var original *GenerateResponse
+	if req.RecipeID != nil {
+		saved, err := s.recipes.GetRecipe(ctx, *req.RecipeID, userID)
+		if err != nil {
+			return nil, err
+		}
+		session.RecipeID = &saved.ID
+		original = fromSavedRecipe(saved)
+	} else {
+		original = req.Recipe
+	}
+
+	session.Turns = []RefinementTurn{{Recipe: original}}
+	return session, nil
+}
+
+// refine generates the revision of current asked for by instruction, with
+// the user's pantry and dietary profile applied as for a new recipe.
+func (s *ChefService) refine(ctx context.Context, userID uuid.UUID, current *GenerateResponse, history []string, instruction string) (*GenerateResponse, error) {
+	req := GenerateRequest{Language: current.Language}
+	plan, err := s.plan(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.generator == nil {
+		revised, err := s.mock.refine(ctx, current, instruction)
+		if err != nil {
+			return nil, err
+		}
+		if err := plan.finish(revised); err != nil {
+			return nil, err
+		}
+		return revised, nil
+	}
+
+	prompt, err := plan.prompts.Refine(req, plan.prompt, current.Content, history, instruction)
+	if err != nil {
+		return nil, err
+	}
+	revised, err := s.generate(ctx, req, prompt, plan.check)
+	if err != nil {
+		return nil, err
+	}
+	revised.PromptVersion = plan.prompts.Version
+	if err := plan.finish(revised); err != nil {
+		return nil, err
+	}
+	return revised, nil
+}
+
+func fromSavedRecipe(r *recipe.Recipe) *GenerateResponse {
+	resp := &GenerateResponse{
+		Title:         r.Title,
+		Content:       r.ContentMarkdown,
+		Calories:      r.CaloriesEstimate,
+		Language:      r.Language,
+		ParseStatus:   ParseClean,
+		PromptVersion: r.PromptVersion,
+		Model:         r.Model,
+		Details:       r.Details,
+	}
+	resp.render()
+	return resp
+}
+
+// validateRefineRequest returns a message describing what is wrong with
+// req, or "" when it is valid.
+func validateRefineRequest(req RefineRequest) string {
+	sources := 0
+	for _, set := range []bool{req.SessionID != nil, req.RecipeID != nil, req.Recipe != nil} {
+		if set {
+			sources++
+		}
+	}
+	switch {
+	case sources != 1:
+		return "exactly one of session_id, recipe_id and recipe is required"
+	case strings.TrimSpace(req.Instruction) == "":
+		return "instruction is required"
+	case utf8.RuneCountInString(req.Instruction) > MaxInstructionLength:
+		return fmt.Sprintf("instruction must be at most %d characters", MaxInstructionLength)
+	case req.Recipe != nil && strings.TrimSpace(req.Recipe.Content) == "" && !req.Recipe.IsStructured():
+		return "recipe has no content"
+	case req.Recipe != nil && !supportedLanguage(req.Recipe.Language):
+		return fmt.Sprintf("language %q is not supported", req.Recipe.Language)
+	}
+	return ""
+}
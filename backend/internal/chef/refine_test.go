@@ -0,0 +1,141 @@
+package chef
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/google/uuid"
+)
+
+type memorySessions struct {
+	sessions map[uuid.UUID]*RefinementSession
+}
+
+func (m *memorySessions) Create(_ context.Context, session *RefinementSession) error {
+	if m.sessions == nil {
+		m.sessions = map[uuid.UUID]*RefinementSession{}
+	}
+	session.ID = uuid.New()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memorySessions) Get(_ context.Context, id, userID uuid.UUID) (*RefinementSession, error) {
+	session, ok := m.sessions[id]
+	if !ok || session.UserID != userID {
+		return nil, nil
+	}
+	copied := *session
+	copied.Turns = append([]RefinementTurn(nil), session.Turns...)
+	return &copied, nil
+}
+
+func (m *memorySessions) AppendTurn(_ context.Context, sessionID uuid.UUID, turn *RefinementTurn) error {
+	session := m.sessions[sessionID]
+	turn.Position = len(session.Turns)
+	session.Turns = append(session.Turns, *turn)
+	return nil
+}
+
+type savedRecipes map[uuid.UUID]*recipe.Recipe
+
+func (s savedRecipes) GetRecipe(_ context.Context, id, userID uuid.UUID) (*recipe.Recipe, error) {
+	r, ok := s[id]
+	if !ok || r.UserID != userID {
+		return nil, recipe.ErrRecipeNotFound
+	}
+	return r, nil
+}
+
+func TestRefineMockStartsAndContinuesSession(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	chef := &ChefService{mock: newMockGenerator(MockConfig{})}
+	original, err := chef.GenerateRecipe(ctx, userID, GenerateRequest{Ingredients: []string{"rice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := &memorySessions{}
+	s := NewRefineService(chef, sessions, savedRecipes{})
+
+	first, err := s.Refine(ctx, userID, RefineRequest{Recipe: original, Instruction: "add a pinch of chili"})
+	if err != nil {
+		t.Fatalf("Refine: %v", err)
+	}
+	if first.Revision != 1 || len(sessions.sessions[first.SessionID].Turns) != 2 {
+		t.Fatalf("revision = %d, turns = %d", first.Revision, len(sessions.sessions[first.SessionID].Turns))
+	}
+	if !strings.Contains(first.Recipe.Content, "Add a pinch of chili") {
+		t.Errorf("instruction not applied:\n%s", first.Recipe.Content)
+	}
+
+	second, err := s.Refine(ctx, userID, RefineRequest{SessionID: &first.SessionID, Instruction: "serve with lime"})
+	if err != nil {
+		t.Fatalf("Refine: %v", err)
+	}
+	if second.Revision != 2 || !strings.Contains(second.Recipe.Content, "Add a pinch of chili") {
+		t.Errorf("second revision lost the first change: %+v", second)
+	}
+
+	if _, err := s.Refine(ctx, uuid.New(), RefineRequest{SessionID: &first.SessionID, Instruction: "more salt"}); err != ErrSessionNotFound {
+		t.Errorf("other user's session: err = %v", err)
+	}
+}
+
+func TestRefineSavedRecipeSendsHistory(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	saved := &recipe.Recipe{ID: uuid.New(), UserID: userID, Title: "Rice", ContentMarkdown: "# Rice\n\nCook the rice.", Language: "en"}
+	gen := &scriptedGenerator{outputs: []string{
+		`{"title":"Spicy Rice","calories":300,"servings":2,"ingredients":["rice","chili"],"steps":["Cook."]}`,
+	}}
+	s := NewRefineService(&ChefService{generator: gen}, &memorySessions{}, savedRecipes{saved.ID: saved})
+
+	first, err := s.Refine(ctx, userID, RefineRequest{RecipeID: &saved.ID, Instruction: "make it spicier"})
+	if err != nil {
+		t.Fatalf("Refine: %v", err)
+	}
+	if first.Recipe.RevisionOf == nil || *first.Recipe.RevisionOf != saved.ID {
+		t.Errorf("revision_of = %v, want %s", first.Recipe.RevisionOf, saved.ID)
+	}
+	if !strings.Contains(gen.prompts[0], "Cook the rice.") {
+		t.Errorf("prompt is missing the current recipe:\n%s", gen.prompts[0])
+	}
+
+	if _, err := s.Refine(ctx, userID, RefineRequest{SessionID: &first.SessionID, Instruction: "less salt"}); err != nil {
+		t.Fatalf("Refine: %v", err)
+	}
+	last := gen.prompts[len(gen.prompts)-1]
+	if !strings.Contains(last, "- make it spicier") || !strings.Contains(last, "Spicy Rice") {
+		t.Errorf("second prompt is missing the history:\n%s", last)
+	}
+
+	missing := uuid.New()
+	if _, err := s.Refine(ctx, userID, RefineRequest{RecipeID: &missing, Instruction: "x"}); err != recipe.ErrRecipeNotFound {
+		t.Errorf("missing recipe: err = %v", err)
+	}
+}
+
+func TestValidateRefineRequest(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		req  RefineRequest
+		want string
+	}{
+		{RefineRequest{SessionID: &id, Instruction: "spicier"}, ""},
+		{RefineRequest{Instruction: "spicier"}, "exactly one"},
+		{RefineRequest{SessionID: &id, RecipeID: &id, Instruction: "spicier"}, "exactly one"},
+		{RefineRequest{SessionID: &id, Instruction: "  "}, "instruction is required"},
+		{RefineRequest{SessionID: &id, Instruction: strings.Repeat("a", MaxInstructionLength+1)}, "at most"},
+		{RefineRequest{Recipe: &GenerateResponse{}, Instruction: "spicier"}, "no content"},
+	}
+	for _, tt := range tests {
+		got := validateRefineRequest(tt.req)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("validateRefineRequest(%+v) = %q, want %q", tt.req, got, tt.want)
+		}
+	}
+}
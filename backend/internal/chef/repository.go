@@ -1,4 +1,191 @@
 package chef
 
-// Repository is not needed for chef service as it doesn't store data directly,
-// but we keep the file for consistency or future use.
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// purgeEvery controls how often Set also deletes expired rows.
+const purgeEvery = 100
+
+// PostgresCache shares the generation cache between instances through the
+// generation_cache table.
+type PostgresCache struct {
+	db     *sql.DB
+	writes atomic.Int64
+}
+
+func NewPostgresCache(db *sql.DB) *PostgresCache {
+	return &PostgresCache{db: db}
+}
+
+func (c *PostgresCache) Get(ctx context.Context, key string) (*GenerateResponse, bool, error) {
+	query := `SELECT response FROM generation_cache WHERE key = $1 AND expires_at > NOW()`
+
+	var value []byte
+	err := c.db.QueryRowContext(ctx, query, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get cached recipe: %w", err)
+	}
+
+	var resp GenerateResponse
+	if err := json.Unmarshal(value, &resp); err != nil {
+		return nil, false, fmt.Errorf("decode cached recipe: %w", err)
+	}
+	return &resp, true, nil
+}
+
+func (c *PostgresCache) Set(ctx context.Context, key string, resp *GenerateResponse, ttl time.Duration) error {
+	value, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("encode cached recipe: %w", err)
+	}
+
+	query := `
+		INSERT INTO generation_cache (key, response, expires_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')
+		ON CONFLICT (key) DO UPDATE
+		SET response = EXCLUDED.response, expires_at = EXCLUDED.expires_at, created_at = NOW()
+	`
+	if _, err := c.db.ExecContext(ctx, query, key, value, int64(ttl.Seconds())); err != nil {
+		return fmt.Errorf("set cached recipe: %w", err)
+	}
+
+	if c.writes.Add(1)%purgeEvery == 0 {
+		if _, err := c.db.ExecContext(ctx, `DELETE FROM generation_cache WHERE expires_at <= NOW()`); err != nil {
+			return fmt.Errorf("purge cached recipes: %w", err)
+		}
+	}
+	return nil
+}
+
+// SessionRepository stores refinement sessions in the refinement_sessions
+// and refinement_turns tables.
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create stores a new session and its first turn, the original recipe.
+func (r *SessionRepository) Create(ctx context.Context, session *RefinementSession) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO refinement_sessions (user_id, recipe_id)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`
+	if err := tx.QueryRowContext(ctx, query, session.UserID, session.RecipeID).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt); err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+
+	for i := range session.Turns {
+		if err := insertTurn(ctx, tx, session.ID, &session.Turns[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit session: %w", err)
+	}
+	return nil
+}
+
+// Get returns the user's session with all its turns, or nil if there is no
+// such session.
+func (r *SessionRepository) Get(ctx context.Context, id, userID uuid.UUID) (*RefinementSession, error) {
+	session := RefinementSession{ID: id, UserID: userID}
+	query := `SELECT recipe_id, created_at, updated_at FROM refinement_sessions WHERE id = $1 AND user_id = $2`
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(&session.RecipeID, &session.CreatedAt, &session.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT position, instruction, recipe, created_at
+		FROM refinement_turns
+		WHERE session_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("get session turns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var turn RefinementTurn
+		var value []byte
+		if err := rows.Scan(&turn.Position, &turn.Instruction, &value, &turn.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan session turn: %w", err)
+		}
+		if err := json.Unmarshal(value, &turn.Recipe); err != nil {
+			return nil, fmt.Errorf("decode session turn: %w", err)
+		}
+		session.Turns = append(session.Turns, turn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get session turns: %w", err)
+	}
+	return &session, nil
+}
+
+// AppendTurn adds turn after the last one and sets its position.
+func (r *SessionRepository) AppendTurn(ctx context.Context, sessionID uuid.UUID, turn *RefinementTurn) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the session row serializes concurrent refinements.
+	if _, err := tx.ExecContext(ctx, `UPDATE refinement_sessions SET updated_at = NOW() WHERE id = $1`, sessionID); err != nil {
+		return fmt.Errorf("touch session: %w", err)
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(position), -1) + 1 FROM refinement_turns WHERE session_id = $1`, sessionID).Scan(&turn.Position); err != nil {
+		return fmt.Errorf("next turn position: %w", err)
+	}
+	if err := insertTurn(ctx, tx, sessionID, turn); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit session turn: %w", err)
+	}
+	return nil
+}
+
+func insertTurn(ctx context.Context, tx *sql.Tx, sessionID uuid.UUID, turn *RefinementTurn) error {
+	value, err := json.Marshal(turn.Recipe)
+	if err != nil {
+		return fmt.Errorf("encode session turn: %w", err)
+	}
+	query := `
+		INSERT INTO refinement_turns (session_id, position, instruction, recipe)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	if err := tx.QueryRowContext(ctx, query, sessionID, turn.Position, turn.Instruction, value).Scan(&turn.CreatedAt); err != nil {
+		return fmt.Errorf("insert session turn: %w", err)
+	}
+	return nil
+}
@@ -1,190 +1,189 @@
 package chef
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"strings"
+	"log"
+	"strconv"
 	"time"
 
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
 	"github.com/Igorlimaponce/fridgeChef/backend/internal/pantry"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quota"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
 	"github.com/google/uuid"
 )
 
 type ChefService struct {
-	apiKey        string
-	client        *http.Client
-	pantryService *pantry.PantryService
+	generator      ia.RecipeGenerator
+	mock           *mockGenerator
+	pantryService  *pantry.PantryService
+	dietaryService *dietary.DietaryService
+	prompts        *Prompts
+	parseRetries   int
+	cache          Cache
+	cacheTTL       time.Duration
 }
 
-func NewChefService(pantryService *pantry.PantryService) *ChefService {
+// NewChefService builds the chef. A nil generator makes it fall back to the
+// offline mock generator configured by LoadMockConfig; a nil cache disables
+// caching.
+func NewChefService(generator ia.RecipeGenerator, pantryService *pantry.PantryService, dietaryService *dietary.DietaryService, prompts *Prompts, cache Cache, cacheTTL time.Duration) *ChefService {
 	return &ChefService{
-		apiKey:        os.Getenv("GEMINI_API_KEY"),
-		client:        &http.Client{Timeout: 60 * time.Second},
-		pantryService: pantryService,
+		generator:      generator,
+		mock:           newMockGenerator(LoadMockConfig()),
+		pantryService:  pantryService,
+		dietaryService: dietaryService,
+		prompts:        prompts,
+		parseRetries:   parseRetries(),
+		cache:          cache,
+		cacheTTL:       cacheTTL,
 	}
 }
 
-// Gemini API Structures
-type geminiRequest struct {
-	Contents []geminiContent `json:"contents"`
-}
-
-type geminiContent struct {
-	Parts []geminiPart `json:"parts"`
-}
-
-type geminiPart struct {
-	Text string `json:"text"`
-}
-
-type geminiResponse struct {
-	Candidates []struct {
-		Content struct {
-			Parts []struct {
-				Text string `json:"text"`
-			} `json:"parts"`
-		} `json:"content"`
-	} `json:"candidates"`
-}
-
 func (s *ChefService) GenerateRecipe(ctx context.Context, userID uuid.UUID, req GenerateRequest) (*GenerateResponse, error) {
-	if s.apiKey == "" {
-		// Fallback to mock if no API key
-		return s.generateMockRecipe(req)
-	}
-
-	// Fetch pantry items
-	pantryItems, err := s.pantryService.List(ctx, userID)
-	pantryStr := ""
-	if err == nil && len(pantryItems) > 0 {
-		var items []string
-		for _, item := range pantryItems {
-			items = append(items, item.Name)
-		}
-		pantryStr = fmt.Sprintf("The user also has these items in their pantry (use them if needed): %s.", strings.Join(items, ", "))
+	plan, err := s.plan(ctx, userID, req)
+	if err != nil {
+		return nil, err
 	}
 
-	langInstruction := "Respond in English."
-	if req.Language == "pt" {
-		langInstruction = "Respond in Portuguese (pt-BR)."
+	if s.generator == nil {
+		// Fallback to mock if no provider is configured
+		return s.mockRecipe(ctx, plan, req, 0)
 	}
 
-	prompt := fmt.Sprintf(`
-You are a professional chef. Create a recipe using these main ingredients: %s.
-%s
-Preferences: %s.
-%s
-Return ONLY a JSON object (no markdown formatting) with this structure:
-{
-	"title": "Recipe Title",
-	"content": "Markdown formatted content with Ingredients and Instructions",
-	"calories": 500
-}
-`, strings.Join(req.Ingredients, ", "), pantryStr, req.Preferences, langInstruction)
-
-	requestBody := geminiRequest{
-		Contents: []geminiContent{
-			{
-				Parts: []geminiPart{
-					{Text: prompt},
-				},
-			},
-		},
+	key := s.cacheKey(req, plan, "")
+	if cached := s.cached(ctx, key, req); cached != nil {
+		if err := plan.accept(cached); err == nil {
+			quota.Refund(ctx, 1)
+			return cached, nil
+		}
 	}
 
-	jsonBody, err := json.Marshal(requestBody)
+	prompt, err := plan.prompts.Recipe(req, plan.prompt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %v", err)
+		return nil, err
 	}
-
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash:generateContent?key=%s", s.apiKey)
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	result, err := s.generate(ctx, req, prompt, plan.check)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, err
+	}
+	result.PromptVersion = plan.prompts.Version
+	if err := plan.finish(result); err != nil {
+		return nil, err
 	}
+	s.store(ctx, key, result)
+	return result, nil
+}
 
-	httpReq.Header.Set("Content-Type", "application/json")
+// generate runs prompt against the provider. Output that is invalid, or that
+// check rejects, gets a bounded number of corrective retries. When they run
+// out and the last recipe that parsed still fails check, check's error is
+// returned; if none parsed, the raw text is returned as a degraded recipe.
+func (s *ChefService) generate(ctx context.Context, req GenerateRequest, prompt string, check func(*GenerateResponse) error) (*GenerateResponse, error) {
+	text := prompt
+	var lastOutput string
+	var rejected error
+	for attempt := 0; attempt <= s.parseRetries; attempt++ {
+		completion, err := s.generator.Generate(ctx, ia.Prompt{Text: text, Schema: recipeSchema})
+		if err != nil {
+			return nil, err
+		}
 
-	resp, err := s.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call Gemini API: %v", err)
-	}
-	defer resp.Body.Close()
+		result, status, err := parseRecipeOutput(completion.Text)
+		if err == nil {
+			result.Model = s.completionModel(completion)
+			if attempt > 0 {
+				status = ParseRepaired
+			}
+			result.ParseStatus = status
+			result.Language = req.Language
+			result.render()
+			if check == nil {
+				return result, nil
+			}
+			if err = check(result); err == nil {
+				return result, nil
+			}
+			rejected = err
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Gemini API error: %s - %s", resp.Status, string(bodyBytes))
+		log.Printf("ChefService.GenerateRecipe - attempt %d returned invalid recipe: %v", attempt+1, err)
+		lastOutput = completion.Text
+		text = correctionPrompt(prompt, completion.Text, err)
 	}
 
-	var geminiResp geminiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	if rejected != nil {
+		return nil, rejected
 	}
+	degraded := degradedRecipe(lastOutput, req)
+	degraded.Model = s.generator.Model()
+	return degraded, nil
+}
 
-	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no content returned from Gemini")
+// completionModel is the model that answered, which may be more specific
+// than the configured one (e.g. a dated snapshot).
+func (s *ChefService) completionModel(c *ia.Completion) string {
+	if c.Model != "" {
+		return c.Model
 	}
+	return s.generator.Model()
+}
 
-	content := geminiResp.Candidates[0].Content.Parts[0].Text
-
-	// Clean up potential markdown code blocks
-	content = strings.TrimPrefix(content, "```json")
-	content = strings.TrimPrefix(content, "```")
-	content = strings.TrimSuffix(content, "```")
-	content = strings.TrimSpace(content)
+// cacheKey identifies a generation by everything that shapes the output:
+// the request, the user's context, any extra instructions, the prompt
+// version and the model.
+func (s *ChefService) cacheKey(req GenerateRequest, plan *generationPlan, extra string) string {
+	model := s.generator.Provider() + "/" + s.generator.Model() + "@" + plan.prompts.Version
+	return cacheKey(req, plan.prompt+extra, model)
+}
 
-	var result GenerateResponse
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		// Fallback if JSON parsing fails
-		return &GenerateResponse{
-			Title:    fmt.Sprintf("Recipe with %s", req.Ingredients[0]),
-			Content:  content,
-			Calories: 0,
-		}, nil
+// cached returns the cached recipe for key, or nil on a miss, when the cache
+// is disabled or when the client asked to regenerate. Callers refund the
+// user's generation quota once the hit passes the plan, since a hit that is
+// rejected still costs a fresh generation.
+func (s *ChefService) cached(ctx context.Context, key string, req GenerateRequest) *GenerateResponse {
+	if s.cache == nil || req.Regenerate {
+		return nil
 	}
-
-	return &result, nil
+	resp, ok, err := s.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("ChefService cache get error: %v", err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	resp.Cached = true
+	return resp
 }
-func (s *ChefService) generateMockRecipe(req GenerateRequest) (*GenerateResponse, error) {
-	time.Sleep(2 * time.Second) // Simulate latency
 
-	title := fmt.Sprintf("Delicious %s with %s", req.Preferences, strings.Join(req.Ingredients, ", "))
-	if req.Preferences == "" {
-		title = fmt.Sprintf("Delicious Dish with %s", strings.Join(req.Ingredients, ", "))
+// store caches a successful recipe. Degraded output is never cached so a
+// retry gets a fresh chance.
+func (s *ChefService) store(ctx context.Context, key string, resp *GenerateResponse) {
+	if s.cache == nil || resp.ParseStatus == ParseDegraded {
+		return
 	}
+	if err := s.cache.Set(ctx, key, resp, s.cacheTTL); err != nil {
+		log.Printf("ChefService cache set error: %v", err)
+	}
+}
 
-	content := fmt.Sprintf(`
-# %s
-
-## Ingredients
-%s
-
-## Instructions
-1. Prepare the ingredients.
-2. Mix them together.
-3. Cook for 20 minutes.
-4. Serve hot.
-
-Enjoy your meal!
-`, title, formatIngredients(req.Ingredients))
-
-	return &GenerateResponse{
-		Title:    title,
-		Content:  content,
-		Calories: 450, // Mock value
-	}, nil
+// parseRetries reads LLM_PARSE_RETRIES, the number of corrective retries
+// allowed when the model returns an invalid recipe.
+func parseRetries() int {
+	n, err := strconv.Atoi(util.GetEnv("LLM_PARSE_RETRIES", "1"))
+	if err != nil || n < 0 {
+		return 1
+	}
+	return min(n, 3)
 }
 
-func formatIngredients(ingredients []string) string {
-	var sb strings.Builder
-	for _, ing := range ingredients {
-		sb.WriteString(fmt.Sprintf("- %s\n", ing))
+// render rebuilds Content and Nutrition from the structured details.
+func (r *GenerateResponse) render() {
+	if r.IsStructured() {
+		r.Content = recipe.RenderMarkdown(r.Title, r.Calories, r.Details, r.Language)
+		r.Nutrition = r.ComputeNutrition()
 	}
-	return sb.String()
 }
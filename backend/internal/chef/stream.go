@@ -0,0 +1,186 @@
+package chef
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quota"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/google/uuid"
+)
+
+// GenerateRecipeStream generates a markdown recipe and hands every partial
+// token to onToken as soon as the provider produces it. The parsed recipe is
+// returned once the stream ends. Providers that can't stream are called
+// normally and their output is delivered as a single token.
+func (s *ChefService) GenerateRecipeStream(ctx context.Context, userID uuid.UUID, req GenerateRequest, onToken func(string) error) (*GenerateResponse, error) {
+	plan, err := s.plan(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.generator == nil {
+		resp, err := s.mockRecipe(ctx, plan, req, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := streamMockRecipe(ctx, resp, onToken); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	return s.stream(ctx, req, plan, onToken)
+}
+
+// stream generates the recipe with the provider. Streamed output can't get a
+// corrective retry, so a recipe that breaks the plan fails once it ends.
+func (s *ChefService) stream(ctx context.Context, req GenerateRequest, plan *generationPlan, onToken func(string) error) (*GenerateResponse, error) {
+	// Streamed recipes are parsed from markdown, so they are cached apart
+	// from the JSON ones of GenerateRecipe.
+	key := s.cacheKey(req, plan, "\x00stream")
+	if cached := s.cached(ctx, key, req); cached != nil {
+		if err := plan.accept(cached); err == nil {
+			if err := onToken(cached.Content); err != nil {
+				return nil, err
+			}
+			quota.Refund(ctx, 1)
+			return cached, nil
+		}
+	}
+
+	text, err := plan.prompts.Stream(req, plan.prompt)
+	if err != nil {
+		return nil, err
+	}
+	prompt := ia.Prompt{Text: text}
+	guard := &dietGuard{diet: plan.diet, onToken: onToken}
+
+	var completion *ia.Completion
+	if streamer, ok := s.generator.(ia.StreamingGenerator); ok {
+		completion, err = streamer.GenerateStream(ctx, prompt, guard.write)
+	} else {
+		completion, err = s.generator.Generate(ctx, prompt)
+		if err == nil {
+			err = guard.write(completion.Text)
+		}
+	}
+	if err == nil {
+		err = guard.flush()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := parseMarkdownRecipe(completion.Text, req)
+	result.PromptVersion = plan.prompts.Version
+	result.Model = s.completionModel(completion)
+	if err := plan.accept(result); err != nil {
+		return nil, err
+	}
+	s.store(ctx, key, result)
+	return result, nil
+}
+
+// dietGuard sits between the provider stream and the client. Text is only
+// forwarded up to the last complete word, after the whole output so far has
+// been checked against the dietary profile, so a forbidden ingredient is
+// never shown to the user.
+type dietGuard struct {
+	diet    *dietary.Checker
+	onToken func(string) error
+	text    strings.Builder
+	sent    int
+}
+
+func (g *dietGuard) write(token string) error {
+	g.text.WriteString(token)
+	text := g.text.String()
+	end := strings.LastIndexFunc(text, unicode.IsSpace)
+	if end < g.sent {
+		return nil
+	}
+	return g.forward(text, end+1)
+}
+
+func (g *dietGuard) flush() error {
+	text := g.text.String()
+	return g.forward(text, len(text))
+}
+
+func (g *dietGuard) forward(text string, end int) error {
+	if end <= g.sent {
+		return nil
+	}
+	if v := dietary.Blocking(g.diet.Check(text[:end])); len(v) > 0 {
+		return &dietary.ViolationError{Subject: "the recipe", Violations: v}
+	}
+	chunk := text[g.sent:end]
+	g.sent = end
+	return g.onToken(chunk)
+}
+
+func streamMockRecipe(ctx context.Context, resp *GenerateResponse, onToken func(string) error) error {
+	for _, line := range strings.SplitAfter(resp.Content, "\n") {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := onToken(line); err != nil {
+			return err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil
+}
+
+var (
+	titleLine    = regexp.MustCompile(`^#\s+(.+)$`)
+	caloriesLine = regexp.MustCompile(`(?i)^\**\s*(?:calories|calorias|kcal)\s*\**\s*:?\s*\**\s*~?(\d+)`)
+)
+
+// parseMarkdownRecipe extracts the title, the calorie estimate and the
+// structured details from a markdown recipe produced by the stream prompt.
+func parseMarkdownRecipe(markdown string, req GenerateRequest) *GenerateResponse {
+	content := strings.TrimSpace(markdown)
+	content = strings.TrimPrefix(content, "```markdown")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	content = strings.TrimSpace(content)
+
+	result := &GenerateResponse{Content: content}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if result.Title == "" {
+			if m := titleLine.FindStringSubmatch(line); m != nil {
+				result.Title = strings.TrimSpace(m[1])
+				continue
+			}
+		}
+		if m := caloriesLine.FindStringSubmatch(line); m != nil {
+			result.Calories, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	result.ParseStatus = ParseClean
+	if result.Title == "" {
+		result.Title = fallbackTitle(req)
+		result.ParseStatus = ParseRepaired
+	}
+
+	result.Language = req.Language
+	result.Details = recipe.ParseMarkdown(content)
+	if !result.IsStructured() {
+		// Keep the streamed text as-is but flag it so it isn't saved.
+		result.ParseStatus = ParseDegraded
+		return result
+	}
+	result.render()
+
+	return result
+}
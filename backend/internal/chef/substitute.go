@@ -0,0 +1,284 @@
+package chef
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quota"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxSubstitutionContext is the longest context accepted, in runes.
+	MaxSubstitutionContext = 200
+	// maxSubstitutes caps the substitutions returned per ingredient.
+	maxSubstitutes = 5
+)
+
+// SubstitutionService suggests replacements for missing ingredients. The
+// offline table answers first; the model is only asked about ingredients
+// the table doesn't know.
+type SubstitutionService struct {
+	chef    *ChefService
+	recipes RecipeSource
+}
+
+func NewSubstitutionService(chef *ChefService, recipes RecipeSource) *SubstitutionService {
+	return &SubstitutionService{chef: chef, recipes: recipes}
+}
+
+func (s *SubstitutionService) Substitutions(ctx context.Context, userID uuid.UUID, req SubstitutionRequest) (*SubstitutionResponse, error) {
+	ingredients := []string{strings.TrimSpace(req.Ingredient)}
+	dish, language := strings.TrimSpace(req.Context), req.Language
+	missingOnly := false
+
+	if req.RecipeID != nil {
+		saved, err := s.recipes.GetRecipe(ctx, *req.RecipeID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if dish == "" {
+			dish = saved.Title
+		}
+		if language == "" {
+			language = saved.Language
+		}
+		if ingredients[0] == "" {
+			ingredients, missingOnly = savedIngredients(saved), true
+		}
+	}
+
+	return s.chef.substitutions(ctx, userID, ingredients, dish, locale.Resolve(language).Tag, missingOnly)
+}
+
+func savedIngredients(r *recipe.Recipe) []string {
+	if len(r.Ingredients) == 0 {
+		var used []string
+		if err := json.Unmarshal(r.IngredientsUsed, &used); err != nil {
+			log.Printf("recipe %s has invalid ingredients_used: %v", r.ID, err)
+		}
+		return used
+	}
+	names := make([]string, 0, len(r.Ingredients))
+	for _, ing := range r.Ingredients {
+		names = append(names, ing.Name)
+	}
+	return names
+}
+
+// substitutions looks up each ingredient, asking the model in one call
+// about those the table doesn't know. With missingOnly, ingredients the
+// pantry covers and basic staples are skipped. The request's quota is
+// refunded when the model isn't used.
+func (s *ChefService) substitutions(ctx context.Context, userID uuid.UUID, ingredients []string, dish, language string, missingOnly bool) (*SubstitutionResponse, error) {
+	plan, err := s.plan(ctx, userID, GenerateRequest{Language: language})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SubstitutionResponse{Results: []IngredientSubstitutions{}}
+	var unknown []int
+	for _, name := range ingredients {
+		if missingOnly && (basicStaples[strings.ToLower(name)] || plan.pantry.match(name) != nil) {
+			continue
+		}
+		result := IngredientSubstitutions{Ingredient: name, Source: SubstitutionNone, Substitutions: []Substitution{}}
+		if subs, ok := lookupSubstitutes(name); ok {
+			result.Source = SubstitutionTable
+			for _, sub := range subs {
+				result.Substitutions = append(result.Substitutions, Substitution{Name: sub.name, Ratio: sub.ratio, Notes: sub.notes})
+			}
+		} else {
+			unknown = append(unknown, len(resp.Results))
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	if len(unknown) > 0 && s.generator != nil {
+		if err := s.modelSubstitutions(ctx, plan, dish, language, resp, unknown); err != nil {
+			if len(unknown) == len(resp.Results) {
+				return nil, err
+			}
+			// The table answered for the rest; unknown items stay "none".
+			log.Printf("ChefService.substitutions - model fallback failed: %v", err)
+		}
+	}
+	if resp.Model == "" {
+		quota.Refund(ctx, 1)
+	}
+
+	for i := range resp.Results {
+		resp.Results[i].Substitutions = rankSubstitutions(resp.Results[i].Substitutions, plan)
+	}
+	return resp, nil
+}
+
+// modelSubstitutions fills in the results at the unknown indexes.
+func (s *ChefService) modelSubstitutions(ctx context.Context, plan *generationPlan, dish, language string, resp *SubstitutionResponse, unknown []int) error {
+	names := make([]string, len(unknown))
+	for i, idx := range unknown {
+		names[i] = resp.Results[idx].Ingredient
+	}
+	prompt, err := plan.prompts.Substitute(GenerateRequest{Language: language}, plan.prompt, names, dish)
+	if err != nil {
+		return err
+	}
+
+	completion, err := s.generator.Generate(ctx, ia.Prompt{Text: prompt, Schema: substitutionSchema})
+	if err != nil {
+		return err
+	}
+	answers, err := parseSubstitutionOutput(completion.Text)
+	if err != nil {
+		return err
+	}
+
+	for i, idx := range unknown {
+		subs, ok := answers[normalizeSubstitutionKey(names[i])]
+		if !ok {
+			continue
+		}
+		resp.Results[idx].Source = SubstitutionModel
+		resp.Results[idx].Substitutions = subs
+	}
+	resp.Model = s.completionModel(completion)
+	return nil
+}
+
+// rankSubstitutions drops substitutes the dietary profile forbids, puts
+// the ones the user already has first and keeps the best maxSubstitutes.
+func rankSubstitutions(subs []Substitution, plan *generationPlan) []Substitution {
+	ranked := make([]Substitution, 0, len(subs))
+	for _, sub := range subs {
+		if len(dietary.Blocking(plan.diet.Check(sub.Name))) > 0 {
+			continue
+		}
+		if item := plan.pantry.match(sub.Name); item != nil {
+			sub.InPantry, sub.PantryItemID = true, &item.ID
+		}
+		ranked = append(ranked, sub)
+	}
+	slices.SortStableFunc(ranked, func(a, b Substitution) int {
+		switch {
+		case a.InPantry == b.InPantry:
+			return 0
+		case a.InPantry:
+			return -1
+		}
+		return 1
+	})
+	if len(ranked) > maxSubstitutes {
+		ranked = ranked[:maxSubstitutes]
+	}
+	return ranked
+}
+
+// lookupSubstitutes finds name in the substitution table, by key or alias
+// first and then by the table ingredient it mentions ("fresh basil leaves").
+// When several match, the one nearest the end wins, since that is usually
+// the head noun ("almond milk" is milk).
+func lookupSubstitutes(name string) ([]substitute, bool) {
+	key := normalizeSubstitutionKey(name)
+	if alias, ok := substitutionAliases[key]; ok {
+		key = alias
+	}
+	if subs, ok := substitutionTable[key]; ok {
+		return subs, true
+	}
+
+	best, bestEnd, bestLen := "", -1, 0
+	padded := " " + key + " "
+	consider := func(term, target string) {
+		i := strings.LastIndex(padded, " "+term+" ")
+		if i < 0 {
+			return
+		}
+		end := i + len(term)
+		if end > bestEnd || (end == bestEnd && len(term) > bestLen) {
+			best, bestEnd, bestLen = target, end, len(term)
+		}
+	}
+	for term := range substitutionTable {
+		consider(term, term)
+	}
+	for term, target := range substitutionAliases {
+		consider(term, target)
+	}
+	if best == "" {
+		return nil, false
+	}
+	return substitutionTable[best], true
+}
+
+func normalizeSubstitutionKey(name string) string {
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(strings.ToLower(name))
+	return singular(strings.Join(strings.Fields(name), " "))
+}
+
+// parseSubstitutionOutput decodes the model's answer into substitutions by
+// normalized ingredient name.
+func parseSubstitutionOutput(text string) (map[string][]Substitution, error) {
+	var out struct {
+		Ingredients []struct {
+			Ingredient  string `json:"ingredient"`
+			Substitutes []struct {
+				Name  string     `json:"name"`
+				Ratio flexNumber `json:"ratio"`
+				Notes string     `json:"notes"`
+			} `json:"substitutes"`
+		} `json:"ingredients"`
+	}
+
+	trimmed := trimCodeFence(text)
+	if err := json.Unmarshal([]byte(trimmed), &out); err != nil {
+		object, err := extractJSONObject(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(repairJSON(object), &out); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	}
+	if len(out.Ingredients) == 0 {
+		return nil, errors.New("no substitutions in model output")
+	}
+
+	answers := make(map[string][]Substitution, len(out.Ingredients))
+	for _, ing := range out.Ingredients {
+		subs := []Substitution{}
+		for _, sub := range ing.Substitutes {
+			if name := strings.TrimSpace(sub.Name); name != "" {
+				ratio := float64(sub.Ratio)
+				if ratio <= 0 {
+					ratio = 1
+				}
+				subs = append(subs, Substitution{Name: name, Ratio: ratio, Notes: strings.TrimSpace(sub.Notes)})
+			}
+		}
+		answers[normalizeSubstitutionKey(ing.Ingredient)] = subs
+	}
+	return answers, nil
+}
+
+// validateSubstitutionRequest returns a message describing what is wrong
+// with req, or "" when it is valid.
+func validateSubstitutionRequest(req SubstitutionRequest) string {
+	switch {
+	case req.RecipeID == nil && strings.TrimSpace(req.Ingredient) == "":
+		return "ingredient or recipe_id is required"
+	case len([]rune(req.Context)) > MaxSubstitutionContext:
+		return fmt.Sprintf("context must be at most %d characters", MaxSubstitutionContext)
+	case !supportedLanguage(req.Language):
+		return fmt.Sprintf("language %q is not supported, use one of: %s", req.Language, strings.Join(locale.Tags(), ", "))
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package chef
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/google/uuid"
+)
+
+func TestLookupSubstitutes(t *testing.T) {
+	for name, want := range map[string]string{
+		"Unsalted Butter":    "margarine",
+		"eggs":               "flaxseed",
+		"almond milk":        "oat milk",
+		"fresh basil leaves": "oregano",
+		"All-Purpose Flour":  "bread flour",
+		"peanut butter":      "almond butter",
+		"manteiga":           "margarine",
+	} {
+		subs, ok := lookupSubstitutes(name)
+		if !ok || subs[0].name != want {
+			t.Errorf("lookupSubstitutes(%q) = %v, %v; want %s first", name, subs, ok, want)
+		}
+	}
+	if _, ok := lookupSubstitutes("dragon fruit"); ok {
+		t.Error("unknown ingredient found in the table")
+	}
+
+	for alias, key := range substitutionAliases {
+		if _, ok := substitutionTable[key]; !ok {
+			t.Errorf("alias %q points to missing entry %q", alias, key)
+		}
+	}
+}
+
+func TestRankSubstitutionsPrefersPantryAndRespectsDiet(t *testing.T) {
+	plan := dietPlan(&dietary.Profile{Allergens: []string{"tree_nuts"}})
+	plan.pantry = testPlan(PantryHint, 0)
+
+	got := rankSubstitutions([]Substitution{
+		{Name: "quinoa", Ratio: 1},
+		{Name: "cashew rice", Ratio: 1},
+		{Name: "couscous", Ratio: 1},
+		{Name: "cherry tomatoes", Ratio: 1},
+	}, plan)
+
+	var names []string
+	for _, sub := range got {
+		names = append(names, sub.Name)
+	}
+	if strings.Join(names, "|") != "cherry tomatoes|quinoa|couscous" {
+		t.Fatalf("ranked = %v", names)
+	}
+	if !got[0].InPantry || *got[0].PantryItemID != plan.pantry.items[1].ID {
+		t.Errorf("pantry item not marked: %+v", got[0])
+	}
+}
+
+func TestSubstitutionsUseModelOnlyForUnknownIngredients(t *testing.T) {
+	ctx := context.Background()
+	gen := &scriptedGenerator{outputs: []string{
+		`{"ingredients":[{"ingredient":"Gochujang","substitutes":[{"name":"sriracha","ratio":"0.5","notes":"Add a pinch of sugar."}]}]}`,
+	}}
+	s := &ChefService{generator: gen}
+
+	resp, err := s.substitutions(ctx, uuid.New(), []string{"butter"}, "", "en", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gen.prompts) != 0 || resp.Results[0].Source != SubstitutionTable || resp.Model != "" {
+		t.Errorf("table ingredient went to the model: %+v", resp)
+	}
+
+	resp, err = s.substitutions(ctx, uuid.New(), []string{"butter", "gochujang"}, "bibimbap", "en", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gen.prompts) != 1 || strings.Contains(gen.prompts[0], "- butter") || !strings.Contains(gen.prompts[0], "bibimbap") {
+		t.Errorf("prompt = %q", gen.prompts)
+	}
+	model := resp.Results[1]
+	if model.Source != SubstitutionModel || len(model.Substitutions) != 1 || model.Substitutions[0].Ratio != 0.5 || resp.Model != "scripted" {
+		t.Errorf("model result = %+v", resp)
+	}
+
+	offline, err := (&ChefService{}).substitutions(ctx, uuid.New(), []string{"gochujang"}, "", "en", false)
+	if err != nil || offline.Results[0].Source != SubstitutionNone {
+		t.Errorf("offline unknown = %+v, %v", offline, err)
+	}
+}
+
+func TestSubstitutionsForSavedRecipeSkipStaples(t *testing.T) {
+	userID := uuid.New()
+	used, _ := json.Marshal([]string{"buttermilk", "salt", "eggs"})
+	saved := &recipe.Recipe{ID: uuid.New(), UserID: userID, Title: "Pancakes", IngredientsUsed: used}
+	s := NewSubstitutionService(&ChefService{}, savedRecipes{saved.ID: saved})
+
+	resp, err := s.Substitutions(context.Background(), userID, SubstitutionRequest{RecipeID: &saved.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 2 || resp.Results[0].Ingredient != "buttermilk" || resp.Results[1].Ingredient != "eggs" {
+		t.Errorf("results = %+v", resp.Results)
+	}
+}
+
+func TestValidateSubstitutionRequest(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		req  SubstitutionRequest
+		want string
+	}{
+		{SubstitutionRequest{Ingredient: "butter"}, ""},
+		{SubstitutionRequest{RecipeID: &id}, ""},
+		{SubstitutionRequest{Ingredient: " "}, "required"},
+		{SubstitutionRequest{Ingredient: "butter", Context: strings.Repeat("a", MaxSubstitutionContext+1)}, "at most"},
+		{SubstitutionRequest{Ingredient: "butter", Language: "xx"}, "not supported"},
+	}
+	for _, tt := range tests {
+		got := validateSubstitutionRequest(tt.req)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("validateSubstitutionRequest(%+v) = %q, want %q", tt.req, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,445 @@
+package chef
+
+// substitute is an entry of the offline substitution table. ratio is how
+// much of the substitute replaces one unit of the original.
+type substitute struct {
+	name  string
+	ratio float64
+	notes string
+}
+
+// substitutionTable lists the usual replacements for common ingredients,
+// best first. Keys are lowercase and singular.
+var substitutionTable = map[string][]substitute{
+	"butter": {
+		{"margarine", 1, ""},
+		{"coconut oil", 1, "Solid at room temperature, so it creams like butter."},
+		{"olive oil", 0.75, "For cooking and savory baking; not for recipes that cream butter and sugar."},
+		{"vegetable oil", 0.75, ""},
+		{"applesauce", 0.5, "For cakes and muffins; makes them denser and less rich."},
+	},
+	"egg": {
+		{"flaxseed", 1, "Per egg: 1 tbsp ground flaxseed soaked in 3 tbsp water for 5 minutes. Binds, doesn't leaven."},
+		{"chia seed", 1, "Per egg: 1 tbsp chia seeds soaked in 3 tbsp water."},
+		{"banana", 1, "Per egg: half a mashed banana. Adds sweetness; for baking only."},
+		{"applesauce", 1, "Per egg: 60 g (1/4 cup). For baking only."},
+		{"yogurt", 1, "Per egg: 60 g (1/4 cup)."},
+	},
+	"milk": {
+		{"oat milk", 1, ""},
+		{"soy milk", 1, ""},
+		{"almond milk", 1, "Thinner and slightly nutty."},
+		{"water", 1, "Plus 1 tbsp butter per cup for richness."},
+		{"evaporated milk", 0.5, "Mixed with the same amount of water."},
+	},
+	"buttermilk": {
+		{"milk", 1, "Plus 1 tbsp lemon juice or vinegar per cup; let it stand 5 minutes."},
+		{"yogurt", 0.75, "Thinned with 1/4 part milk or water."},
+		{"kefir", 1, ""},
+	},
+	"heavy cream": {
+		{"milk", 0.75, "Plus 1/4 part melted butter. Won't whip."},
+		{"coconut cream", 1, "Whips when chilled; adds a coconut flavor."},
+		{"evaporated milk", 1, "Won't whip."},
+		{"cream cheese", 0.5, "Thinned with milk; for sauces."},
+	},
+	"sour cream": {
+		{"greek yogurt", 1, ""},
+		{"yogurt", 1, "Strain it first for a thicker result."},
+		{"cream cheese", 1, "Thinned with a little milk."},
+		{"crème fraîche", 1, ""},
+	},
+	"yogurt": {
+		{"sour cream", 1, ""},
+		{"buttermilk", 1, "Thinner; reduce other liquids."},
+		{"coconut yogurt", 1, ""},
+	},
+	"cream cheese": {
+		{"mascarpone", 1, ""},
+		{"ricotta", 1, "Blend until smooth; less tangy."},
+		{"greek yogurt", 1, "Strained overnight for spreads and dips."},
+	},
+	"parmesan": {
+		{"pecorino", 1, "Saltier; reduce added salt."},
+		{"grana padano", 1, ""},
+		{"nutritional yeast", 0.5, "Dairy-free; for sprinkling and sauces."},
+	},
+	"mozzarella": {
+		{"provolone", 1, ""},
+		{"monterey jack", 1, ""},
+		{"fontina", 1, ""},
+	},
+	"all purpose flour": {
+		{"bread flour", 1, "Chewier results."},
+		{"whole wheat flour", 0.75, "Denser; add a little more liquid."},
+		{"gluten free flour", 1, "Use a 1:1 blend with xanthan gum."},
+		{"oat flour", 1.25, "Lighter and more crumbly."},
+	},
+	"flour": {
+		{"gluten free flour", 1, "Use a 1:1 blend with xanthan gum."},
+		{"cornstarch", 0.5, "For thickening only."},
+		{"oat flour", 1.25, ""},
+	},
+	"cornstarch": {
+		{"flour", 2, "For thickening; cook a few minutes longer."},
+		{"arrowroot", 1, "Add at the end; doesn't hold up to long cooking."},
+		{"potato starch", 1, ""},
+		{"tapioca starch", 2, ""},
+	},
+	"baking powder": {
+		{"baking soda", 0.25, "Plus 1/2 part cream of tartar."},
+		{"self rising flour", 1, "Replace part of the flour and leave out the salt."},
+	},
+	"baking soda": {
+		{"baking powder", 3, "Weaker and adds some bitterness; cut acidic ingredients."},
+	},
+	"sugar": {
+		{"brown sugar", 1, "Moister, with a caramel note."},
+		{"honey", 0.75, "Reduce other liquids by 1/4 and bake 10 °C cooler."},
+		{"maple syrup", 0.75, "Reduce other liquids by 3 tbsp per cup."},
+		{"coconut sugar", 1, ""},
+	},
+	"brown sugar": {
+		{"sugar", 1, "Plus 1 tbsp molasses per cup."},
+		{"coconut sugar", 1, ""},
+		{"maple syrup", 0.75, "Reduce other liquids."},
+	},
+	"honey": {
+		{"maple syrup", 1, ""},
+		{"agave syrup", 1, ""},
+		{"golden syrup", 1, ""},
+		{"sugar", 1.25, "Plus 1/4 part water."},
+	},
+	"maple syrup": {
+		{"honey", 1, ""},
+		{"agave syrup", 1, ""},
+		{"brown sugar", 1, "Plus 1/4 part water."},
+	},
+	"lemon juice": {
+		{"lime juice", 1, ""},
+		{"white wine vinegar", 0.5, "For acidity only, not for flavor."},
+		{"orange juice", 1, "Sweeter and milder."},
+	},
+	"lime juice": {
+		{"lemon juice", 1, ""},
+		{"rice vinegar", 0.5, ""},
+	},
+	"white wine": {
+		{"chicken stock", 1, "Plus a splash of vinegar or lemon juice."},
+		{"vegetable stock", 1, "Plus a splash of vinegar or lemon juice."},
+		{"white grape juice", 1, "Sweeter; add a little vinegar."},
+		{"dry vermouth", 1, ""},
+	},
+	"red wine": {
+		{"beef stock", 1, "Plus 1 tbsp red wine vinegar per cup."},
+		{"grape juice", 1, "Plus a little vinegar."},
+		{"pomegranate juice", 1, ""},
+	},
+	"wine vinegar": {
+		{"apple cider vinegar", 1, ""},
+		{"lemon juice", 1, ""},
+		{"rice vinegar", 1, "Milder."},
+	},
+	"apple cider vinegar": {
+		{"white wine vinegar", 1, ""},
+		{"lemon juice", 1, ""},
+		{"rice vinegar", 1, ""},
+	},
+	"rice vinegar": {
+		{"apple cider vinegar", 1, "A pinch of sugar brings it closer."},
+		{"white wine vinegar", 1, ""},
+	},
+	"soy sauce": {
+		{"tamari", 1, "Usually gluten-free."},
+		{"coconut aminos", 1, "Soy-free and sweeter; add salt."},
+		{"worcestershire sauce", 0.5, "Not vegetarian."},
+	},
+	"fish sauce": {
+		{"soy sauce", 1, "Plus a squeeze of lime."},
+		{"anchovy", 1, "One mashed fillet per tsp."},
+		{"miso", 0.5, "Thinned with water; vegetarian."},
+	},
+	"chicken stock": {
+		{"vegetable stock", 1, ""},
+		{"beef stock", 1, "Stronger flavor."},
+		{"water", 1, "Plus a stock cube or more seasoning."},
+	},
+	"beef stock": {
+		{"chicken stock", 1, ""},
+		{"vegetable stock", 1, "Add 1 tsp soy sauce for depth."},
+		{"mushroom stock", 1, ""},
+	},
+	"vegetable stock": {
+		{"chicken stock", 1, ""},
+		{"water", 1, "Plus a stock cube or more seasoning."},
+	},
+	"chicken": {
+		{"turkey", 1, ""},
+		{"tofu", 1, "Pressed firm tofu; cooks faster."},
+		{"chickpea", 1, "For stews and curries."},
+		{"pork", 1, ""},
+	},
+	"beef": {
+		{"lamb", 1, ""},
+		{"pork", 1, ""},
+		{"mushroom", 1, "Meaty varieties such as portobello."},
+		{"lentil", 1, "Cooked lentils, for mince dishes."},
+	},
+	"ground beef": {
+		{"ground turkey", 1, "Leaner; add a little oil."},
+		{"ground pork", 1, ""},
+		{"lentil", 1, "Cooked; for sauces and fillings."},
+	},
+	"bacon": {
+		{"pancetta", 1, ""},
+		{"smoked ham", 1, ""},
+		{"smoked tofu", 1, "Plus a pinch of smoked paprika."},
+	},
+	"shrimp": {
+		{"scallop", 1, ""},
+		{"white fish", 1, ""},
+		{"chicken", 1, "Cut into bite-size pieces; cook longer."},
+	},
+	"salmon": {
+		{"trout", 1, ""},
+		{"arctic char", 1, ""},
+		{"mackerel", 1, "Stronger flavor."},
+	},
+	"tofu": {
+		{"tempeh", 1, ""},
+		{"paneer", 1, "Not vegan."},
+		{"chickpea", 1, ""},
+	},
+	"rice": {
+		{"quinoa", 1, ""},
+		{"couscous", 1, "Contains gluten."},
+		{"cauliflower rice", 1, "Low-carb; cook only a few minutes."},
+		{"bulgur", 1, "Contains gluten."},
+	},
+	"pasta": {
+		{"rice noodle", 1, "Gluten-free."},
+		{"zucchini noodle", 1, "Low-carb; don't boil."},
+		{"gluten free pasta", 1, ""},
+	},
+	"breadcrumb": {
+		{"panko", 1, ""},
+		{"crushed cracker", 1, ""},
+		{"rolled oat", 1, "Pulsed in a blender."},
+		{"ground almond", 1, "Gluten-free."},
+	},
+	"mayonnaise": {
+		{"greek yogurt", 1, ""},
+		{"sour cream", 1, ""},
+		{"avocado", 1, "Mashed; for sandwiches and dressings."},
+	},
+	"onion": {
+		{"shallot", 1, "Milder and sweeter."},
+		{"leek", 1, ""},
+		{"onion powder", 0.1, "1 tbsp per medium onion."},
+	},
+	"shallot": {
+		{"onion", 1, "Plus a little garlic."},
+		{"leek", 1, ""},
+	},
+	"garlic": {
+		{"garlic powder", 0.125, "1/8 tsp per clove."},
+		{"shallot", 1, ""},
+		{"garlic paste", 1, ""},
+	},
+	"ginger": {
+		{"ground ginger", 0.25, "1/4 tsp per tbsp of fresh ginger."},
+		{"galangal", 1, "More citrusy."},
+	},
+	"fresh herb": {
+		{"dried herb", 0.33, "1 tsp dried per tbsp of fresh."},
+	},
+	"basil": {
+		{"oregano", 1, ""},
+		{"parsley", 1, "Milder."},
+		{"dried basil", 0.33, ""},
+	},
+	"cilantro": {
+		{"parsley", 1, "Plus a squeeze of lime."},
+		{"basil", 1, "Thai basil is closest."},
+		{"mint", 0.5, ""},
+	},
+	"parsley": {
+		{"cilantro", 1, ""},
+		{"chervil", 1, ""},
+		{"dried parsley", 0.33, ""},
+	},
+	"thyme": {
+		{"oregano", 1, ""},
+		{"marjoram", 1, ""},
+		{"dried thyme", 0.33, ""},
+	},
+	"rosemary": {
+		{"thyme", 1, ""},
+		{"sage", 0.5, "Stronger."},
+		{"dried rosemary", 0.33, ""},
+	},
+	"tomato": {
+		{"canned tomato", 1, ""},
+		{"tomato paste", 0.25, "Thinned with water; for sauces."},
+		{"red bell pepper", 1, "For raw dishes."},
+	},
+	"tomato paste": {
+		{"tomato sauce", 3, "Reduce other liquids."},
+		{"ketchup", 1, "Sweeter."},
+	},
+	"bell pepper": {
+		{"poblano", 1, "Slightly spicy."},
+		{"zucchini", 1, ""},
+	},
+	"zucchini": {
+		{"yellow squash", 1, ""},
+		{"eggplant", 1, ""},
+		{"cucumber", 1, "Raw dishes only."},
+	},
+	"spinach": {
+		{"kale", 1, "Remove the stems; cook longer."},
+		{"chard", 1, ""},
+		{"arugula", 1, "Peppery; for salads."},
+	},
+	"eggplant": {
+		{"zucchini", 1, ""},
+		{"portobello mushroom", 1, ""},
+	},
+	"potato": {
+		{"sweet potato", 1, "Sweeter; cooks faster."},
+		{"cauliflower", 1, "For mash; lower in carbohydrates."},
+		{"parsnip", 1, ""},
+	},
+	"chili": {
+		{"chili flake", 0.25, "1/4 tsp per fresh chili."},
+		{"cayenne", 0.125, ""},
+		{"hot sauce", 0.5, ""},
+	},
+	"cumin": {
+		{"ground coriander", 1, ""},
+		{"chili powder", 0.5, "Spicier."},
+		{"caraway", 0.5, ""},
+	},
+	"paprika": {
+		{"chili powder", 0.5, "Spicier."},
+		{"cayenne", 0.125, "Much spicier."},
+	},
+	"cinnamon": {
+		{"allspice", 0.25, ""},
+		{"nutmeg", 0.25, ""},
+		{"cardamom", 0.5, ""},
+	},
+	"vanilla extract": {
+		{"vanilla bean", 1, "Seeds of 1 bean per tbsp."},
+		{"maple syrup", 1, ""},
+		{"almond extract", 0.5, "Stronger."},
+	},
+	"cocoa powder": {
+		{"dark chocolate", 3, "Reduce fat by 1 tbsp per 28 g of chocolate."},
+		{"carob powder", 1, "Caffeine-free and sweeter."},
+	},
+	"peanut butter": {
+		{"almond butter", 1, ""},
+		{"sunflower seed butter", 1, "Nut-free."},
+		{"tahini", 1, "Nut-free; less sweet."},
+	},
+	"almond": {
+		{"cashew", 1, ""},
+		{"hazelnut", 1, ""},
+		{"sunflower seed", 1, "Nut-free."},
+	},
+	"pine nut": {
+		{"walnut", 1, ""},
+		{"sunflower seed", 1, "Nut-free."},
+		{"almond", 1, ""},
+	},
+	"coconut milk": {
+		{"heavy cream", 1, ""},
+		{"cashew cream", 1, ""},
+		{"milk", 1, "Thinner; for curries, thicken with cornstarch."},
+	},
+	"olive oil": {
+		{"vegetable oil", 1, ""},
+		{"avocado oil", 1, ""},
+		{"butter", 1.25, "For cooking, not dressings."},
+	},
+	"vegetable oil": {
+		{"canola oil", 1, ""},
+		{"sunflower oil", 1, ""},
+		{"melted butter", 1, ""},
+		{"applesauce", 1, "For baking only."},
+	},
+}
+
+// substitutionAliases maps other names for table ingredients to their key.
+var substitutionAliases = map[string]string{
+	"unsalted butter":     "butter",
+	"salted butter":       "butter",
+	"whole milk":          "milk",
+	"cream":               "heavy cream",
+	"double cream":        "heavy cream",
+	"whipping cream":      "heavy cream",
+	"plain flour":         "all purpose flour",
+	"wheat flour":         "flour",
+	"cornflour":           "cornstarch",
+	"corn starch":         "cornstarch",
+	"bicarbonate of soda": "baking soda",
+	"caster sugar":        "sugar",
+	"white sugar":         "sugar",
+	"granulated sugar":    "sugar",
+	"lemon":               "lemon juice",
+	"lime":                "lime juice",
+	"coriander":           "cilantro",
+	"scallion":            "onion",
+	"green onion":         "onion",
+	"spring onion":        "onion",
+	"minced beef":         "ground beef",
+	"mince":               "ground beef",
+	"prawn":               "shrimp",
+	"parmigiano":          "parmesan",
+	"bread crumb":         "breadcrumb",
+	"mayo":                "mayonnaise",
+	"chicken broth":       "chicken stock",
+	"beef broth":          "beef stock",
+	"vegetable broth":     "vegetable stock",
+	"courgette":           "zucchini",
+	"aubergine":           "eggplant",
+	"capsicum":            "bell pepper",
+	"spaghetti":           "pasta",
+	"penne":               "pasta",
+	"noodle":              "pasta",
+	"red wine vinegar":    "wine vinegar",
+	"white wine vinegar":  "wine vinegar",
+	"sweet paprika":       "paprika",
+	"smoked paprika":      "paprika",
+	"chili pepper":        "chili",
+	"vanilla":             "vanilla extract",
+	"manteiga":            "butter",
+	"ovo":                 "egg",
+	"leite":               "milk",
+	"farinha de trigo":    "all purpose flour",
+	"açúcar":              "sugar",
+	"mel":                 "honey",
+	"alho":                "garlic",
+	"cebola":              "onion",
+	"frango":              "chicken",
+	"arroz":               "rice",
+	"mantequilla":         "butter",
+	"huevo":               "egg",
+	"leche":               "milk",
+	"harina":              "flour",
+	"azúcar":              "sugar",
+	"ajo":                 "garlic",
+	"cebolla":             "onion",
+	"pollo":               "chicken",
+	"beurre":              "butter",
+	"œuf":                 "egg",
+	"oeuf":                "egg",
+	"lait":                "milk",
+	"farine":              "flour",
+	"sucre":               "sugar",
+	"ail":                 "garlic",
+	"oignon":              "onion",
+	"poulet":              "chicken",
+	"riz":                 "rice",
+}
@@ -0,0 +1,223 @@
+package chef
+
+import (
+	"embed"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+	"github.com/google/uuid"
+)
+
+//go:embed prompts/*/*.tmpl
+var embeddedPrompts embed.FS
+
+// DefaultPromptVersion is served when PROMPT_VERSION is not set.
+const DefaultPromptVersion = "v1"
+
+// promptNames are the templates every prompt version must define.
+var promptNames = []string{"recipe", "stream", "refine", "substitute"}
+
+// PromptConfig selects which prompt versions are served. A share of users,
+// chosen by a stable hash of their ID, gets the experiment version.
+type PromptConfig struct {
+	// Dir is an optional directory loaded on top of the embedded prompts.
+	Dir               string
+	Version           string
+	Experiment        string
+	ExperimentPercent int
+}
+
+// LoadPromptConfig reads PROMPT_DIR, PROMPT_VERSION, PROMPT_EXPERIMENT_VERSION
+// and PROMPT_EXPERIMENT_PERCENT.
+func LoadPromptConfig() PromptConfig {
+	cfg := PromptConfig{
+		Dir:        util.GetEnv("PROMPT_DIR", ""),
+		Version:    util.GetEnv("PROMPT_VERSION", DefaultPromptVersion),
+		Experiment: util.GetEnv("PROMPT_EXPERIMENT_VERSION", ""),
+	}
+	if n, err := strconv.Atoi(util.GetEnv("PROMPT_EXPERIMENT_PERCENT", "")); err == nil {
+		cfg.ExperimentPercent = min(max(n, 0), 100)
+	}
+	return cfg
+}
+
+// PromptSet is one version of the generation prompts.
+type PromptSet struct {
+	Version   string
+	templates map[string]*template.Template
+}
+
+// promptData is what the templates can refer to.
+type promptData struct {
+	Ingredients string
+	Pantry      string
+	Preferences string
+	Language    string
+	Units       string
+	// Refinement only.
+	Recipe      string
+	History     []string
+	Instruction string
+	// Substitutions only.
+	Missing []string
+	Dish    string
+}
+
+func (p *PromptSet) Recipe(req GenerateRequest, pantry string) (string, error) {
+	return p.render("recipe", req, pantry)
+}
+
+func (p *PromptSet) Stream(req GenerateRequest, pantry string) (string, error) {
+	return p.render("stream", req, pantry)
+}
+
+// Refine asks for current, a recipe in markdown, to be revised following
+// instruction; history lists the instructions already applied.
+func (p *PromptSet) Refine(req GenerateRequest, pantry, current string, history []string, instruction string) (string, error) {
+	data := newPromptData(req, pantry)
+	data.Recipe, data.History, data.Instruction = current, history, instruction
+	return p.execute("refine", data)
+}
+
+// Substitute asks for substitutes for the missing ingredients, used in
+// dish when it is known.
+func (p *PromptSet) Substitute(req GenerateRequest, pantry string, missing []string, dish string) (string, error) {
+	data := newPromptData(req, pantry)
+	data.Missing, data.Dish = missing, dish
+	return p.execute("substitute", data)
+}
+
+func newPromptData(req GenerateRequest, pantry string) promptData {
+	return promptData{
+		Ingredients: mainIngredients(req),
+		Pantry:      pantry,
+		Preferences: req.Preferences,
+		Language:    languageInstruction(req.Language),
+		Units:       unitsInstruction(req.Language),
+	}
+}
+
+func (p *PromptSet) render(name string, req GenerateRequest, pantry string) (string, error) {
+	return p.execute(name, newPromptData(req, pantry))
+}
+
+func (p *PromptSet) execute(name string, data promptData) (string, error) {
+	var sb strings.Builder
+	if err := p.templates[name].Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("render %s prompt %s: %w", name, p.Version, err)
+	}
+	return sb.String(), nil
+}
+
+// Prompts holds every loaded prompt version and picks the one a user gets.
+type Prompts struct {
+	cfg  PromptConfig
+	sets map[string]*PromptSet
+}
+
+// LoadPrompts loads the embedded prompts and the override directory, and
+// checks that the configured versions exist.
+func LoadPrompts(cfg PromptConfig) (*Prompts, error) {
+	if cfg.Version == "" {
+		cfg.Version = DefaultPromptVersion
+	}
+
+	sources := map[string]map[string]string{}
+	embedded, _ := fs.Sub(embeddedPrompts, "prompts")
+	if err := readPrompts(embedded, sources); err != nil {
+		return nil, err
+	}
+	if cfg.Dir != "" {
+		if err := readPrompts(os.DirFS(cfg.Dir), sources); err != nil {
+			return nil, fmt.Errorf("prompt dir %s: %w", cfg.Dir, err)
+		}
+	}
+
+	p := &Prompts{cfg: cfg, sets: make(map[string]*PromptSet, len(sources))}
+	for version, files := range sources {
+		set := &PromptSet{Version: version, templates: map[string]*template.Template{}}
+		for _, name := range promptNames {
+			src, ok := files[name]
+			if !ok {
+				return nil, fmt.Errorf("prompt version %s has no %s.tmpl", version, name)
+			}
+			t, err := template.New(name).Option("missingkey=error").Parse(src)
+			if err != nil {
+				return nil, fmt.Errorf("prompt version %s: %w", version, err)
+			}
+			set.templates[name] = t
+		}
+		p.sets[version] = set
+	}
+
+	for _, version := range []string{cfg.Version, cfg.Experiment} {
+		if version != "" && p.sets[version] == nil {
+			return nil, fmt.Errorf("prompt version %q not found (have %s)", version, strings.Join(p.Versions(), ", "))
+		}
+	}
+	return p, nil
+}
+
+// readPrompts reads <version>/<name>.tmpl files from fsys into sources,
+// replacing files already there.
+func readPrompts(fsys fs.FS, sources map[string]map[string]string) error {
+	files, err := fs.Glob(fsys, "*/*.tmpl")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		version := path.Dir(file)
+		if sources[version] == nil {
+			sources[version] = map[string]string{}
+		}
+		sources[version][strings.TrimSuffix(path.Base(file), ".tmpl")] = string(data)
+	}
+	return nil
+}
+
+// For returns the prompt version served to userID. Experiment assignment is
+// stable, so a user keeps seeing the same version while it runs.
+func (p *Prompts) For(userID uuid.UUID) *PromptSet {
+	if p.cfg.Experiment != "" && p.cfg.ExperimentPercent > 0 {
+		h := fnv.New32a()
+		h.Write([]byte(p.cfg.Experiment))
+		h.Write(userID[:])
+		if int(h.Sum32()%100) < p.cfg.ExperimentPercent {
+			return p.sets[p.cfg.Experiment]
+		}
+	}
+	return p.sets[p.cfg.Version]
+}
+
+// Versions lists the loaded prompt versions.
+func (p *Prompts) Versions() []string {
+	versions := make([]string, 0, len(p.sets))
+	for v := range p.sets {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// builtinPrompts are the embedded prompts with the default configuration,
+// used by services built without a Prompts.
+var builtinPrompts = sync.OnceValue(func() *Prompts {
+	p, err := LoadPrompts(PromptConfig{})
+	if err != nil {
+		panic(err)
+	}
+	return p
+})
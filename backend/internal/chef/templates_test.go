@@ -0,0 +1,111 @@
+package chef
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func writePrompt(t *testing.T, dir, version, name, src string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, version), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, version, name+".tmpl"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPromptsOverrideDir(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "v2", "recipe", "v2 recipe for {{.Ingredients}} ({{.Language}})")
+	writePrompt(t, dir, "v2", "stream", "v2 stream")
+	writePrompt(t, dir, "v2", "refine", "v2 refine")
+	writePrompt(t, dir, "v2", "substitute", "v2 substitute")
+	writePrompt(t, dir, "v1", "stream", "patched v1 stream")
+
+	p, err := LoadPrompts(PromptConfig{Dir: dir, Version: "v2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "v1,v2" {
+		t.Errorf("versions = %s", got)
+	}
+
+	set := p.For(uuid.New())
+	if set.Version != "v2" {
+		t.Fatalf("served %s, want v2", set.Version)
+	}
+	prompt, err := set.Recipe(GenerateRequest{Ingredients: []string{"leek"}, Language: "fr"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompt != "v2 recipe for Create a recipe using these main ingredients: leek. (Respond in French (fr). Rédigez toute la recette en français.)" {
+		t.Errorf("prompt = %q", prompt)
+	}
+
+	v1 := p.sets["v1"]
+	if stream, _ := v1.Stream(GenerateRequest{}, ""); stream != "patched v1 stream" {
+		t.Errorf("override did not replace v1 stream: %q", stream)
+	}
+	if recipe, _ := v1.Recipe(GenerateRequest{}, ""); !strings.Contains(recipe, "Return ONLY a JSON object") {
+		t.Errorf("embedded v1 recipe lost: %q", recipe)
+	}
+}
+
+func TestLoadPromptsRejectsIncompleteOrUnknownVersions(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "v2", "recipe", "only a recipe")
+	if _, err := LoadPrompts(PromptConfig{Dir: dir}); err == nil {
+		t.Error("version without a stream template was accepted")
+	}
+	if _, err := LoadPrompts(PromptConfig{Version: "v9"}); err == nil {
+		t.Error("unknown version was accepted")
+	}
+}
+
+func TestPromptExperimentIsStablePerUser(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "v2", "recipe", "v2")
+	writePrompt(t, dir, "v2", "stream", "v2")
+	writePrompt(t, dir, "v2", "refine", "v2")
+	writePrompt(t, dir, "v2", "substitute", "v2")
+	p, err := LoadPrompts(PromptConfig{Dir: dir, Version: "v1", Experiment: "v2", ExperimentPercent: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	served := 0
+	for range 1000 {
+		user := uuid.New()
+		version := p.For(user).Version
+		if p.For(user).Version != version {
+			t.Fatal("user switched prompt versions")
+		}
+		if version == "v2" {
+			served++
+		}
+	}
+	if served < 230 || served > 370 {
+		t.Errorf("experiment served to %d of 1000 users, want about 300", served)
+	}
+}
+
+func TestGenerateRecipeRecordsPromptVersionAndModel(t *testing.T) {
+	gen := &scriptedGenerator{outputs: []string{
+		`{"title":"Rice","calories":300,"servings":2,"ingredients":["rice"],"steps":["Cook."]}`,
+	}}
+	s := &ChefService{generator: gen}
+
+	resp, err := s.GenerateRecipe(context.Background(), uuid.New(), GenerateRequest{Ingredients: []string{"rice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.PromptVersion != DefaultPromptVersion || resp.Model != "scripted" {
+		t.Errorf("prompt_version = %q, model = %q", resp.PromptVersion, resp.Model)
+	}
+}
@@ -0,0 +1,178 @@
+package collection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/middleware"
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type CollectionHandler struct {
+	service *CollectionService
+}
+
+func NewCollectionHandler(service *CollectionService) *CollectionHandler {
+	return &CollectionHandler{service: service}
+}
+
+func (h *CollectionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req CreateCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c, err := h.service.Create(r.Context(), userID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, c)
+}
+
+func (h *CollectionHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	collections, err := h.service.List(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, collections)
+}
+
+func (h *CollectionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid collection ID")
+		return
+	}
+
+	c, err := h.service.Get(r.Context(), id, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, c)
+}
+
+func (h *CollectionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid collection ID")
+		return
+	}
+
+	var req UpdateCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c, err := h.service.Update(r.Context(), id, userID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, c)
+}
+
+func (h *CollectionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid collection ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, userID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, map[string]string{"message": "collection deleted"})
+}
+
+func (h *CollectionHandler) AddRecipe(w http.ResponseWriter, r *http.Request) {
+	h.changeMembership(w, r, h.service.AddRecipe)
+}
+
+func (h *CollectionHandler) RemoveRecipe(w http.ResponseWriter, r *http.Request) {
+	h.changeMembership(w, r, h.service.RemoveRecipe)
+}
+
+func (h *CollectionHandler) changeMembership(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id, recipeID, userID uuid.UUID) (*Collection, error)) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid collection ID")
+		return
+	}
+	recipeID, err := uuid.Parse(chi.URLParam(r, "recipeID"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid recipe ID")
+		return
+	}
+
+	c, err := change(r.Context(), id, recipeID, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, c)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrCollectionNotFound), errors.Is(err, ErrRecipeNotFound):
+		util.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrDuplicateName):
+		util.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidCollection):
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,31 @@
+package collection
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Collection is a named group of the user's recipes, such as "Weeknight"
+// or "Christmas". A recipe can be in any number of collections.
+type Collection struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	RecipeCount int       `json:"recipe_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CreateCollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UpdateCollectionRequest renames or redescribes a collection; nil fields
+// are left as they are.
+type UpdateCollectionRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
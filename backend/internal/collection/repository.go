@@ -0,0 +1,193 @@
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const collectionColumns = `c.id, c.user_id, c.name, c.description, c.created_at, c.updated_at,
+	(SELECT count(*) FROM collection_recipes cr WHERE cr.collection_id = c.id)`
+
+type CollectionRepository struct {
+	db *sql.DB
+}
+
+func NewCollectionRepository(db *sql.DB) *CollectionRepository {
+	return &CollectionRepository{db: db}
+}
+
+func (r *CollectionRepository) Create(ctx context.Context, c *Collection) (*Collection, error) {
+	query := `
+		INSERT INTO collections (user_id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, c.UserID, c.Name, c.Description).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, uniqueName(fmt.Errorf("create collection: %w", err))
+	}
+	return c, nil
+}
+
+func (r *CollectionRepository) List(ctx context.Context, userID uuid.UUID) ([]*Collection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM collections c WHERE c.user_id = $1 ORDER BY lower(c.name)`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := []*Collection{}
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan collection: %w", err)
+		}
+		collections = append(collections, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	return collections, nil
+}
+
+// Get returns one of the user's collections, or ErrCollectionNotFound.
+func (r *CollectionRepository) Get(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Collection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM collections c WHERE c.id = $1 AND c.user_id = $2`
+	c, err := scanCollection(r.db.QueryRowContext(ctx, query, id, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get collection: %w", err)
+	}
+	return c, nil
+}
+
+func (r *CollectionRepository) Update(ctx context.Context, c *Collection) error {
+	query := `
+		UPDATE collections SET name = $3, description = $4, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, c.ID, c.UserID, c.Name, c.Description).Scan(&c.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCollectionNotFound
+	}
+	if err != nil {
+		return uniqueName(fmt.Errorf("update collection: %w", err))
+	}
+	return nil
+}
+
+// Delete removes a collection; its recipes are kept.
+func (r *CollectionRepository) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Recipes list their collections, so the ones that lose this one change.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE recipes SET updated_at = NOW()
+		WHERE id IN (
+			SELECT cr.recipe_id FROM collection_recipes cr
+			JOIN collections c ON c.id = cr.collection_id
+			WHERE c.id = $1 AND c.user_id = $2
+		)
+	`, id, userID)
+	if err != nil {
+		return fmt.Errorf("touch collection recipes: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM collections WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete collection: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("delete collection: %w", err)
+	} else if n == 0 {
+		return ErrCollectionNotFound
+	}
+	return tx.Commit()
+}
+
+// AddRecipe puts one of the user's recipes in one of their collections;
+// adding it twice is a no-op.
+func (r *CollectionRepository) AddRecipe(ctx context.Context, id, recipeID, userID uuid.UUID) error {
+	return r.changeMembership(ctx, id, recipeID, userID, `
+		INSERT INTO collection_recipes (collection_id, recipe_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`)
+}
+
+func (r *CollectionRepository) RemoveRecipe(ctx context.Context, id, recipeID, userID uuid.UUID) error {
+	return r.changeMembership(ctx, id, recipeID, userID, `
+		DELETE FROM collection_recipes WHERE collection_id = $1 AND recipe_id = $2
+	`)
+}
+
+// changeMembership checks that both the collection and the recipe belong to
+// the user, runs query with their IDs and bumps both updated_at.
+func (r *CollectionRepository) changeMembership(ctx context.Context, id, recipeID, userID uuid.UUID, query string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE collections SET updated_at = NOW() WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("touch collection: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("touch collection: %w", err)
+	} else if n == 0 {
+		return ErrCollectionNotFound
+	}
+
+	result, err = tx.ExecContext(ctx, `UPDATE recipes SET updated_at = NOW() WHERE id = $1 AND user_id = $2`, recipeID, userID)
+	if err != nil {
+		return fmt.Errorf("touch recipe: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("touch recipe: %w", err)
+	} else if n == 0 {
+		return ErrRecipeNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, query, id, recipeID); err != nil {
+		return fmt.Errorf("change collection recipes: %w", err)
+	}
+	return tx.Commit()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCollection(row scanner) (*Collection, error) {
+	var c Collection
+	err := row.Scan(&c.ID, &c.UserID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt, &c.RecipeCount)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// uniqueName turns a unique violation on the name index into
+// ErrDuplicateName.
+func uniqueName(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicateName
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// maxNameLength is the size of the name column.
+const maxNameLength = 100
+
+// maxDescriptionLength bounds a description.
+const maxDescriptionLength = 1000
+
+var (
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrRecipeNotFound     = errors.New("recipe not found")
+	ErrDuplicateName      = errors.New("a collection with that name already exists")
+	ErrInvalidCollection  = errors.New("invalid collection")
+)
+
+type CollectionService struct {
+	repo *CollectionRepository
+}
+
+func NewCollectionService(repo *CollectionRepository) *CollectionService {
+	return &CollectionService{repo: repo}
+}
+
+func (s *CollectionService) Create(ctx context.Context, userID uuid.UUID, req CreateCollectionRequest) (*Collection, error) {
+	c := &Collection{
+		UserID:      userID,
+		Name:        normalizeName(req.Name),
+		Description: strings.TrimSpace(req.Description),
+	}
+	if err := validate(c); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(ctx, c)
+}
+
+func (s *CollectionService) List(ctx context.Context, userID uuid.UUID) ([]*Collection, error) {
+	return s.repo.List(ctx, userID)
+}
+
+func (s *CollectionService) Get(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Collection, error) {
+	return s.repo.Get(ctx, id, userID)
+}
+
+func (s *CollectionService) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, req UpdateCollectionRequest) (*Collection, error) {
+	c, err := s.repo.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		c.Name = normalizeName(*req.Name)
+	}
+	if req.Description != nil {
+		c.Description = strings.TrimSpace(*req.Description)
+	}
+	if err := validate(c); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *CollectionService) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	return s.repo.Delete(ctx, id, userID)
+}
+
+func (s *CollectionService) AddRecipe(ctx context.Context, id, recipeID, userID uuid.UUID) (*Collection, error) {
+	if err := s.repo.AddRecipe(ctx, id, recipeID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, id, userID)
+}
+
+func (s *CollectionService) RemoveRecipe(ctx context.Context, id, recipeID, userID uuid.UUID) (*Collection, error) {
+	if err := s.repo.RemoveRecipe(ctx, id, recipeID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, id, userID)
+}
+
+// normalizeName trims a name and collapses its inner whitespace; case is
+// kept, though names differing only in case count as duplicates.
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func validate(c *Collection) error {
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidCollection)
+	case len([]rune(c.Name)) > maxNameLength:
+		return fmt.Errorf("%w: name is longer than %d characters", ErrInvalidCollection, maxNameLength)
+	case len([]rune(c.Description)) > maxDescriptionLength:
+		return fmt.Errorf("%w: description is longer than %d characters", ErrInvalidCollection, maxDescriptionLength)
+	}
+	return nil
+}
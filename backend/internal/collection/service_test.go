@@ -0,0 +1,24 @@
+package collection
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	c := &Collection{Name: normalizeName("  Christmas   dinners ")}
+	if err := validate(c); err != nil || c.Name != "Christmas dinners" {
+		t.Errorf("name = %q, %v; want %q", c.Name, err, "Christmas dinners")
+	}
+
+	for name, c := range map[string]*Collection{
+		"empty name":       {Name: normalizeName(" \t ")},
+		"long name":        {Name: strings.Repeat("a", maxNameLength+1)},
+		"long description": {Name: "Weeknight", Description: strings.Repeat("a", maxDescriptionLength+1)},
+	} {
+		if err := validate(c); !errors.Is(err, ErrInvalidCollection) {
+			t.Errorf("%s: error = %v; want ErrInvalidCollection", name, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+-- +goose Up
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS language VARCHAR(10) NOT NULL DEFAULT 'en';
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS servings INTEGER;
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS prep_time_minutes INTEGER;
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS cook_time_minutes INTEGER;
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS cuisine VARCHAR(100);
+
+CREATE TABLE IF NOT EXISTS recipe_ingredients (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    quantity NUMERIC,
+    unit VARCHAR(50),
+    note TEXT,
+    PRIMARY KEY (recipe_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS recipe_steps (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    instruction TEXT NOT NULL,
+    PRIMARY KEY (recipe_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS recipe_tags (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    tag VARCHAR(100) NOT NULL,
+    PRIMARY KEY (recipe_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_tags_tag ON recipe_tags(tag);
+
+-- +goose Down
+DROP TABLE IF EXISTS recipe_tags;
+DROP TABLE IF EXISTS recipe_steps;
+DROP TABLE IF EXISTS recipe_ingredients;
+ALTER TABLE recipes DROP COLUMN IF EXISTS cuisine;
+ALTER TABLE recipes DROP COLUMN IF EXISTS cook_time_minutes;
+ALTER TABLE recipes DROP COLUMN IF EXISTS prep_time_minutes;
+ALTER TABLE recipes DROP COLUMN IF EXISTS servings;
+ALTER TABLE recipes DROP COLUMN IF EXISTS language;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(50) NOT NULL DEFAULT 'viewer';
+
+-- One row per user per (UTC) day with the number of generations spent.
+CREATE TABLE IF NOT EXISTS generation_usage (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, day)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS generation_usage;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
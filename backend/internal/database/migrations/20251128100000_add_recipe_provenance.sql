@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS prompt_version VARCHAR(50);
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS model VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_recipes_prompt_version ON recipes(prompt_version);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_recipes_prompt_version;
+ALTER TABLE recipes DROP COLUMN IF EXISTS model;
+ALTER TABLE recipes DROP COLUMN IF EXISTS prompt_version;
@@ -0,0 +1,28 @@
+-- +goose Up
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS revision_of UUID REFERENCES recipes(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_recipes_revision_of ON recipes(revision_of);
+
+CREATE TABLE IF NOT EXISTS refinement_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id UUID REFERENCES recipes(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refinement_sessions_user_id ON refinement_sessions(user_id);
+
+CREATE TABLE IF NOT EXISTS refinement_turns (
+    session_id UUID NOT NULL REFERENCES refinement_sessions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    instruction TEXT NOT NULL DEFAULT '',
+    recipe JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (session_id, position)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS refinement_turns;
+DROP TABLE IF EXISTS refinement_sessions;
+DROP INDEX IF EXISTS idx_recipes_revision_of;
+ALTER TABLE recipes DROP COLUMN IF EXISTS revision_of;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS image_key TEXT;
+
+-- +goose Down
+ALTER TABLE recipes DROP COLUMN IF EXISTS image_key;
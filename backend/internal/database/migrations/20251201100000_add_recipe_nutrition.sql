@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS nutrition JSONB;
+
+-- +goose Down
+ALTER TABLE recipes DROP COLUMN IF EXISTS nutrition;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+UPDATE recipes SET updated_at = created_at;
+
+-- +goose Down
+ALTER TABLE recipes DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- recipe_search_config picks the text search configuration for a recipe's
+-- language tag, so stemming matches the language it was written in.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION recipe_search_config(language TEXT) RETURNS regconfig
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT CASE split_part(lower(language), '-', 1)
+        WHEN 'pt' THEN 'portuguese'::regconfig
+        WHEN 'es' THEN 'spanish'::regconfig
+        WHEN 'fr' THEN 'french'::regconfig
+        ELSE 'english'::regconfig
+    END
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(recipe_search_config(language), title), 'A') ||
+    setweight(jsonb_to_tsvector(recipe_search_config(language), ingredients_used, '["string"]'), 'B') ||
+    setweight(to_tsvector(recipe_search_config(language), content_markdown), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_search ON recipes USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_recipes_title_trgm ON recipes USING GIN (lower(title) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_recipes_ingredients_trgm ON recipes USING GIN (lower(ingredients_used::text) gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_recipes_ingredients_trgm;
+DROP INDEX IF EXISTS idx_recipes_title_trgm;
+DROP INDEX IF EXISTS idx_recipes_search;
+ALTER TABLE recipes DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS recipe_search_config(TEXT);
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Keyset pagination orders by the sort key, then id.
+CREATE INDEX IF NOT EXISTS idx_recipes_user_created ON recipes(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_recipes_user_title ON recipes(user_id, lower(title), id);
+CREATE INDEX IF NOT EXISTS idx_recipes_user_calories ON recipes(user_id, calories_estimate, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_recipes_user_calories;
+DROP INDEX IF EXISTS idx_recipes_user_title;
+DROP INDEX IF EXISTS idx_recipes_user_created;
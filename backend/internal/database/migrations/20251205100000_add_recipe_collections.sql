@@ -0,0 +1,32 @@
+-- +goose Up
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS is_favorite BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS last_cooked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_user_favorite ON recipes(user_id) WHERE is_favorite;
+
+CREATE TABLE IF NOT EXISTS collections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_user_name ON collections(user_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS collection_recipes (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (collection_id, recipe_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_recipes_recipe_id ON collection_recipes(recipe_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS collection_recipes;
+DROP TABLE IF EXISTS collections;
+DROP INDEX IF EXISTS idx_recipes_user_favorite;
+ALTER TABLE recipes DROP COLUMN IF EXISTS last_cooked_at;
+ALTER TABLE recipes DROP COLUMN IF EXISTS is_favorite;
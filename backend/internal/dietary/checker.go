@@ -0,0 +1,116 @@
+package dietary
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type rule struct {
+	name     string
+	blocking bool
+	terms    []string
+	safe     []string
+}
+
+// Checker matches recipe text against the rules derived from a profile.
+// Matching is keyword based: it errs on the side of flagging.
+type Checker struct {
+	profile *Profile
+	rules   []rule
+}
+
+// NewChecker builds the rules of p. A nil profile gives a checker that
+// accepts everything.
+func NewChecker(p *Profile) *Checker {
+	c := &Checker{profile: p}
+	if p == nil {
+		return c
+	}
+
+	for _, diet := range p.Diets {
+		for _, name := range Diets[diet] {
+			cat := categories[name]
+			c.rules = append(c.rules, rule{name: diet + " diet (" + cat.label + ")", blocking: true, terms: cat.keywords, safe: cat.safe})
+		}
+	}
+	for _, allergen := range p.Allergens {
+		if id, ok := allergenID(allergen); ok {
+			cat := categories[id]
+			c.rules = append(c.rules, rule{name: cat.label + " allergy", blocking: true, terms: cat.keywords, safe: cat.safe})
+			continue
+		}
+		term := Normalize(allergen)
+		c.rules = append(c.rules, rule{name: allergen + " allergy", blocking: true, terms: []string{term}, safe: []string{term + " free"}})
+	}
+	for _, dislike := range p.Dislikes {
+		c.rules = append(c.rules, rule{name: "dislike", terms: []string{Normalize(dislike)}})
+	}
+	return c
+}
+
+func (c *Checker) Empty() bool {
+	return len(c.rules) == 0
+}
+
+// Check returns the violations found in texts, at most one per rule.
+func (c *Checker) Check(texts ...string) []Violation {
+	if c.Empty() {
+		return nil
+	}
+	normalized := make([]string, len(texts))
+	for i, t := range texts {
+		normalized[i] = Normalize(t)
+	}
+
+	var violations []Violation
+	for _, r := range c.rules {
+		for _, text := range normalized {
+			if term := findTerm(text, r.terms, r.safe); term != "" {
+				violations = append(violations, Violation{Term: term, Rule: r.name, Blocking: r.blocking})
+				break
+			}
+		}
+	}
+	return violations
+}
+
+// Prompt describes the profile for the model, or returns "" when it is empty.
+func (c *Checker) Prompt() string {
+	if c.Empty() {
+		return ""
+	}
+	p := c.profile
+
+	var sb strings.Builder
+	sb.WriteString("Dietary profile (mandatory):")
+	if len(p.Diets) > 0 {
+		fmt.Fprintf(&sb, " the recipe must be %s.", strings.Join(p.Diets, ", "))
+	}
+	if len(p.Allergens) > 0 {
+		var names, examples []string
+		for _, a := range p.Allergens {
+			cat, ok := categories[a]
+			if !ok {
+				names = append(names, a)
+				continue
+			}
+			names = append(names, cat.label)
+			examples = append(examples, cat.keywords[:min(4, len(cat.keywords))]...)
+		}
+		fmt.Fprintf(&sb, " The user is allergic to %s: never use them or anything containing them", strings.Join(names, ", "))
+		if len(examples) > 0 {
+			fmt.Fprintf(&sb, " (e.g. %s)", strings.Join(examples, ", "))
+		}
+		sb.WriteString(", not even as a garnish.")
+	}
+	if len(p.Dislikes) > 0 {
+		fmt.Fprintf(&sb, " Avoid these disliked ingredients: %s.", strings.Join(p.Dislikes, ", "))
+	}
+	return sb.String()
+}
+
+// Blocking keeps only the violations that must reject a recipe.
+func Blocking(violations []Violation) []Violation {
+	return slices.DeleteFunc(slices.Clone(violations), func(v Violation) bool { return !v.Blocking })
+}
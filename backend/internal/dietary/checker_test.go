@@ -0,0 +1,109 @@
+package dietary
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckerAllergens(t *testing.T) {
+	c := NewChecker(&Profile{Allergens: []string{"tree_nuts", "kiwi"}})
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Toasted Cashews", "cashew"},
+		{"200 g castanha-de-caju", "castanha"},
+		{"1 tsp nutmeg, coconut milk and water chestnuts", ""},
+		{"A nut-free granola", ""},
+		{"Garnish with sliced kiwis.", "kiwi"},
+		{"Peanut butter", ""},
+	}
+	for _, tt := range tests {
+		var got string
+		if v := c.Check(tt.text); len(v) > 0 {
+			got = v[0].Term
+			if !v[0].Blocking {
+				t.Errorf("allergen violation for %q is not blocking", tt.text)
+			}
+		}
+		if got != tt.want {
+			t.Errorf("Check(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCheckerDiets(t *testing.T) {
+	vegan := NewChecker(&Profile{Diets: []string{"vegan"}})
+	for text, violates := range map[string]bool{
+		"oat milk and vegan butter": false,
+		"2 eggplants":               false,
+		"a knob of butter":          true,
+		"2 eggs":                    true,
+		"honey drizzle":             true,
+		"chicken thighs":            true,
+		"leche de coco":             false,
+		"100 g de mantequilla":      true,
+		"2 œufs battus":             true,
+		"blanc de poulet":           true,
+	} {
+		if got := len(vegan.Check(text)) > 0; got != violates {
+			t.Errorf("vegan Check(%q) = %v, want %v", text, got, violates)
+		}
+	}
+
+	glutenFree := NewChecker(&Profile{Diets: []string{"gluten-free"}})
+	if v := glutenFree.Check("gluten-free flour and rice noodles"); len(v) != 0 {
+		t.Errorf("gluten-free flour flagged: %v", v)
+	}
+	if v := glutenFree.Check("Spaghetti"); len(v) != 1 {
+		t.Errorf("spaghetti not flagged: %v", v)
+	}
+}
+
+func TestCheckerDislikesAreWarnings(t *testing.T) {
+	c := NewChecker(&Profile{Dislikes: []string{"Cilantro"}})
+	v := c.Check("chopped cilantro")
+	if len(v) != 1 || v[0].Blocking {
+		t.Fatalf("Check = %+v, want one non-blocking violation", v)
+	}
+	if len(Blocking(v)) != 0 {
+		t.Error("Blocking kept a dislike")
+	}
+}
+
+func TestCheckerPrompt(t *testing.T) {
+	if NewChecker(nil).Prompt() != "" || NewChecker(&Profile{}).Prompt() != "" {
+		t.Error("empty profile should not add to the prompt")
+	}
+	got := NewChecker(&Profile{Diets: []string{"vegan"}, Allergens: []string{"tree_nuts"}}).Prompt()
+	for _, want := range []string{"vegan", "tree nuts", "cashew"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Prompt() = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestCheckerAllergenNames(t *testing.T) {
+	c := NewChecker(&Profile{Allergens: []string{"Nuts", "milk", "shellfish", "amendoim"}})
+	for text, want := range map[string]string{
+		"Toasted cashews":           "cashew",
+		"A splash of cream":         "cream",
+		"Garlic shrimp":             "shrimp",
+		"Satay sauce":               "satay",
+		"Coconut milk and tofu":     "",
+		"Rice with nutmeg and peas": "",
+	} {
+		var got string
+		if v := c.Check(text); len(v) > 0 {
+			got = v[0].Term
+		}
+		if got != want {
+			t.Errorf("Check(%q) = %q, want %q", text, got, want)
+		}
+	}
+
+	if got := allergenIDsOf([]string{"tree nuts", "leite", "kiwi"}); strings.Join(got, ",") != "tree_nuts,dairy,kiwi" {
+		t.Errorf("allergenIDsOf = %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package dietary
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/middleware"
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+	"github.com/google/uuid"
+)
+
+type DietaryHandler struct {
+	service *DietaryService
+}
+
+func NewDietaryHandler(service *DietaryService) *DietaryHandler {
+	return &DietaryHandler{service: service}
+}
+
+func (h *DietaryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	profile, err := h.service.Get(r.Context(), userID)
+	if err != nil {
+		log.Printf("DietaryService.Get error: %v", err)
+		util.WriteError(w, http.StatusInternalServerError, "failed to get dietary profile")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, profile)
+}
+
+func (h *DietaryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	profile, err := h.service.Update(r.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidProfile) {
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("DietaryService.Update error: %v", err)
+		util.WriteError(w, http.StatusInternalServerError, "failed to update dietary profile")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, profile)
+}
+
+// Options lists the diets and allergens the ontology knows about.
+func (h *DietaryHandler) Options(w http.ResponseWriter, r *http.Request) {
+	diets := make([]string, 0, len(Diets))
+	for diet := range Diets {
+		diets = append(diets, diet)
+	}
+	slices.Sort(diets)
+
+	util.WriteJSON(w, http.StatusOK, map[string][]string{"diets": diets, "allergens": Allergens})
+}
@@ -0,0 +1,32 @@
+package dietary
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Profile is a user's persistent dietary profile. Diets and known allergens
+// use the identifiers of the ontology (see Diets and Allergens); unknown
+// allergens are matched literally.
+type Profile struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Diets     []string  `json:"diets"`
+	Allergens []string  `json:"allergens"`
+	Dislikes  []string  `json:"dislikes"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UpdateProfileRequest struct {
+	Diets     []string `json:"diets"`
+	Allergens []string `json:"allergens"`
+	Dislikes  []string `json:"dislikes"`
+}
+
+// Violation is an ingredient that conflicts with a profile rule.
+type Violation struct {
+	Term string `json:"term"`
+	Rule string `json:"rule"`
+	// Blocking is false for dislikes, which are only flagged.
+	Blocking bool `json:"blocking"`
+}
@@ -0,0 +1,261 @@
+package dietary
+
+import (
+	"strings"
+	"unicode"
+)
+
+// category is a group of ingredients sharing an allergen or a dietary
+// concern. Keywords and safe phrases are matched as whole words after
+// Normalize, so plurals and punctuation don't matter. Safe phrases are
+// removed before matching ("almond milk" is not dairy); "<keyword> free" is
+// always safe.
+type category struct {
+	label    string
+	keywords []string
+	safe     []string
+}
+
+var categories = map[string]category{
+	"tree_nuts": {
+		label: "tree nuts",
+		keywords: []string{
+			"almond", "cashew", "walnut", "pecan", "pistachio", "hazelnut", "macadamia", "brazil nut",
+			"pine nut", "chestnut", "praline", "marzipan", "nougat", "frangipane", "nut", "mixed nuts",
+			"amêndoa", "castanha", "caju", "noz", "nozes", "avelã", "pistache", "macadâmia", "pinhão",
+			"almendra", "anacardo", "nuez", "avellana", "pistacho", "piñón",
+			"amande", "noix", "noisette", "pignon", "châtaigne",
+		},
+		safe: []string{
+			"nutmeg", "coconut", "water chestnut", "castanha d água", "nuez moscada", "noix de coco", "noix de muscade",
+		},
+	},
+	"peanuts": {
+		label:    "peanuts",
+		keywords: []string{"peanut", "groundnut", "satay", "amendoim", "paçoca", "cacahuete", "cacahuate", "maní", "cacahuète", "arachide"},
+	},
+	"dairy": {
+		label: "dairy",
+		keywords: []string{
+			"milk", "butter", "cheese", "cream", "yogurt", "yoghurt", "ghee", "whey", "casein", "buttermilk",
+			"parmesan", "mozzarella", "cheddar", "ricotta", "feta", "mascarpone", "paneer", "brie", "gouda",
+			"gruyère", "halloumi", "pecorino", "crème fraîche", "custard", "dairy",
+			"leite", "manteiga", "queijo", "creme de leite", "iogurte", "requeijão", "nata", "parmesão",
+			"leche", "mantequilla", "queso", "crema de leche", "yogur", "parmesano", "requesón",
+			"lait", "beurre", "fromage", "crème", "yaourt",
+		},
+		safe: []string{
+			"almond milk", "oat milk", "soy milk", "soya milk", "coconut milk", "rice milk", "cashew milk",
+			"coconut cream", "coconut yogurt", "soy yogurt", "vegan butter", "vegan cheese", "plant based milk",
+			"plant based butter", "peanut butter", "almond butter", "cashew butter", "nut butter", "cocoa butter",
+			"apple butter", "cream of tartar", "butter bean", "butternut", "leite de coco", "creme de coco",
+			"leche de coco", "leche de almendra", "leche de soja", "lait de coco", "lait d amande", "lait de soja",
+			"crème de coco", "beurre de cacahuète", "beurre de cacao",
+		},
+	},
+	"eggs": {
+		label:    "eggs",
+		keywords: []string{"egg", "mayonnaise", "mayo", "meringue", "aioli", "ovo", "gema", "clara", "maionese", "huevo", "mayonesa", "œuf", "oeuf"},
+		safe:     []string{"eggplant", "vegan egg", "flax egg", "chia egg", "vegan mayo", "vegan mayonnaise", "clara de ovo vegana"},
+	},
+	"gluten": {
+		label: "gluten",
+		keywords: []string{
+			"wheat", "flour", "bread", "breadcrumb", "panko", "pasta", "spaghetti", "penne", "fusilli", "lasagna",
+			"macaroni", "noodle", "couscous", "semolina", "bulgur", "barley", "rye", "spelt", "seitan", "malt",
+			"beer", "cracker", "crouton", "soy sauce", "udon", "tortilla",
+			"trigo", "farinha", "pão", "macarrão", "cevada", "centeio", "molho de soja",
+			"harina", "fideo", "cebada", "centeno", "pan rallado", "salsa de soja",
+			"blé", "farine", "pâte", "nouille", "orge", "seigle", "chapelure", "sauce soja",
+		},
+		safe: []string{
+			"gluten free flour", "gluten free pasta", "gluten free bread", "gluten free soy sauce",
+			"gluten free noodle", "gluten free tortilla", "rice flour", "almond flour", "coconut flour",
+			"corn flour", "chickpea flour", "tapioca flour", "potato flour", "rice noodle", "rice cracker", "corn tortilla",
+			"tamari", "buckwheat", "farinha de mandioca", "farinha de arroz", "farinha de milho",
+			"farinha de amêndoa", "pão de queijo",
+			"harina de arroz", "harina de maíz", "farine de riz", "farine de maïs",
+		},
+	},
+	"soy": {
+		label:    "soy",
+		keywords: []string{"soy", "soya", "tofu", "tempeh", "edamame", "miso", "soy sauce", "tamari", "soja", "molho de soja", "salsa de soja", "sauce soja"},
+	},
+	"fish": {
+		label: "fish",
+		keywords: []string{
+			"fish", "salmon", "tuna", "cod", "anchovy", "sardine", "trout", "mackerel", "haddock", "tilapia",
+			"halibut", "sea bass", "fish sauce", "worcestershire",
+			"peixe", "salmão", "atum", "bacalhau", "anchova", "sardinha", "truta", "tilápia",
+			"pescado", "salmón", "atún", "bacalao", "anchoa", "sardina", "trucha",
+			"poisson", "saumon", "thon", "cabillaud", "morue", "anchois", "truite",
+		},
+	},
+	"shellfish": {
+		label: "shellfish",
+		keywords: []string{
+			"shrimp", "prawn", "crab", "lobster", "crayfish", "clam", "mussel", "oyster", "scallop", "squid",
+			"octopus", "calamari", "shellfish", "camarão", "caranguejo", "lagosta", "mexilhão", "ostra", "lula", "polvo", "marisco",
+			"gamba", "langostino", "cangrejo", "langosta", "mejillón", "calamar", "pulpo",
+			"crevette", "crabe", "homard", "moule", "huître", "poulpe", "fruits de mer",
+		},
+	},
+	"sesame": {
+		label:    "sesame",
+		keywords: []string{"sesame", "tahini", "gergelim", "tahine", "sésamo", "sésame"},
+	},
+	"mustard": {
+		label:    "mustard",
+		keywords: []string{"mustard", "mostarda", "mostaza", "moutarde"},
+	},
+	"celery": {
+		label:    "celery",
+		keywords: []string{"celery", "celeriac", "aipo", "salsão", "apio", "céleri"},
+	},
+	"meat": {
+		label: "meat",
+		keywords: []string{
+			"beef", "steak", "veal", "lamb", "mutton", "goat meat", "venison", "mince", "meatball", "sausage",
+			"burger", "salami", "chorizo", "pepperoni", "meat", "oxtail", "liver", "beef stock", "chicken stock",
+			"carne", "bife", "vitela", "cordeiro", "linguiça", "salsicha", "almôndega",
+			"ternera", "cordero", "salchicha", "albóndiga", "bistec",
+			"bœuf", "boeuf", "veau", "agneau", "saucisse", "viande",
+		},
+		safe: []string{"vegan sausage", "vegan burger", "veggie burger", "veggie sausage", "plant based", "vegetable stock"},
+	},
+	"poultry": {
+		label: "poultry",
+		keywords: []string{
+			"chicken", "turkey", "duck", "goose", "quail", "frango", "peru", "pato", "galinha",
+			"pollo", "pavo", "poulet", "dinde", "canard",
+		},
+	},
+	"pork": {
+		label: "pork",
+		keywords: []string{
+			"pork", "bacon", "ham", "prosciutto", "pancetta", "lard", "gammon", "chorizo", "salami", "pepperoni",
+			"porco", "toucinho", "presunto", "banha", "linguiça", "bacon",
+			"cerdo", "tocino", "jamón", "manteca de cerdo", "porc", "lardon", "jambon", "saindoux",
+		},
+	},
+	"gelatin": {
+		label:    "gelatin",
+		keywords: []string{"gelatin", "gelatine", "gelatina", "gélatine"},
+		safe:     []string{"vegan gelatin", "agar"},
+	},
+	"alcohol": {
+		label: "alcohol",
+		keywords: []string{
+			"wine", "beer", "rum", "vodka", "whisky", "whiskey", "brandy", "mirin", "liqueur", "vinho", "cerveja", "cachaça",
+			"vino", "cerveza", "ron", "vin", "bière", "rhum",
+		},
+		safe: []string{"wine vinegar", "vinagre de vinho", "vinagre de vino", "vinaigre de vin"},
+	},
+	"honey": {
+		label:    "honey",
+		keywords: []string{"honey", "mel", "miel"},
+	},
+}
+
+// Diets maps each supported diet to the categories it excludes.
+var Diets = map[string][]string{
+	"vegetarian":  {"meat", "poultry", "pork", "fish", "shellfish", "gelatin"},
+	"vegan":       {"meat", "poultry", "pork", "fish", "shellfish", "gelatin", "dairy", "eggs", "honey"},
+	"pescatarian": {"meat", "poultry", "pork", "gelatin"},
+	"gluten-free": {"gluten"},
+	"dairy-free":  {"dairy"},
+	"halal":       {"pork", "alcohol", "gelatin"},
+	"kosher":      {"pork", "shellfish"},
+}
+
+// Allergens lists the allergen identifiers known to the ontology.
+var Allergens = []string{"tree_nuts", "peanuts", "dairy", "eggs", "gluten", "soy", "fish", "shellfish", "sesame", "mustard", "celery"}
+
+// allergenNames are the everyday names of each allergen, so a profile that
+// says "nuts" or "milk" blocks the whole category and not just that word.
+// The identifier and the label are names too.
+var allergenNames = map[string][]string{
+	"tree_nuts": {"nuts", "nut", "nozes", "castanhas", "frutos secos", "fruits à coque"},
+	"peanuts":   {"groundnut", "amendoim", "cacahuete", "maní", "arachide"},
+	"dairy":     {"milk", "lactose", "cow's milk", "laticínios", "leite", "lácteos", "leche", "produits laitiers", "lait"},
+	"eggs":      {"egg", "ovo", "huevo", "œuf"},
+	"gluten":    {"wheat", "trigo", "blé"},
+	"soy":       {"soya", "soybean", "soja"},
+	"fish":      {"peixe", "pescado", "poisson"},
+	"shellfish": {"crustacean", "mollusc", "frutos do mar", "marisco", "fruits de mer", "crustacé"},
+	"sesame":    {"gergelim", "sésamo", "sésame"},
+	"mustard":   {"mostarda", "mostaza", "moutarde"},
+	"celery":    {"aipo", "apio", "céleri"},
+}
+
+// allergenIDs maps every normalized allergen name to its identifier.
+var allergenIDs = map[string]string{}
+
+func init() {
+	for name, c := range categories {
+		for i, k := range c.keywords {
+			c.keywords[i] = Normalize(k)
+		}
+		for i, s := range c.safe {
+			c.safe[i] = Normalize(s)
+		}
+		for _, k := range c.keywords {
+			c.safe = append(c.safe, k+" free")
+		}
+		categories[name] = c
+	}
+	for _, id := range Allergens {
+		for _, name := range append([]string{id, categories[id].label}, allergenNames[id]...) {
+			allergenIDs[Normalize(name)] = id
+		}
+	}
+}
+
+// allergenID returns the identifier of a known allergen name such as
+// "tree nuts" or "leite".
+func allergenID(name string) (string, bool) {
+	id, ok := allergenIDs[Normalize(name)]
+	return id, ok
+}
+
+// Normalize lowercases text, turns punctuation into spaces and crudely
+// singularizes every word, so "Cashews," and "cashew" compare equal.
+func Normalize(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = singular(w)
+	}
+	return strings.Join(words, " ")
+}
+
+func singular(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		return strings.TrimSuffix(w, "ies") + "y"
+	case len(w) > 4 && (strings.HasSuffix(w, "oes") || strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes")):
+		return strings.TrimSuffix(w, "es")
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		return strings.TrimSuffix(w, "s")
+	}
+	return w
+}
+
+// findTerm returns the first of terms found in normalized text as whole
+// words, after removing the safe phrases.
+func findTerm(text string, terms, safe []string) string {
+	padded := " " + text + " "
+	for _, s := range safe {
+		// Loop because adjacent matches share the separating space.
+		for strings.Contains(padded, " "+s+" ") {
+			padded = strings.ReplaceAll(padded, " "+s+" ", " | ")
+		}
+	}
+	for _, t := range terms {
+		if t != "" && strings.Contains(padded, " "+t+" ") {
+			return t
+		}
+	}
+	return ""
+}
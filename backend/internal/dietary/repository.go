@@ -0,0 +1,62 @@
+package dietary
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type DietaryRepository struct {
+	db *sql.DB
+}
+
+func NewDietaryRepository(db *sql.DB) *DietaryRepository {
+	return &DietaryRepository{db: db}
+}
+
+func (r *DietaryRepository) Get(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	query := `SELECT diets, allergens, dislikes, updated_at FROM dietary_profiles WHERE user_id = $1`
+
+	profile := &Profile{UserID: userID, Diets: []string{}, Allergens: []string{}, Dislikes: []string{}}
+	var diets, allergens, dislikes []byte
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&diets, &allergens, &dislikes, &profile.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return profile, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get dietary profile: %w", err)
+	}
+
+	for _, f := range []struct {
+		raw  []byte
+		dest *[]string
+	}{{diets, &profile.Diets}, {allergens, &profile.Allergens}, {dislikes, &profile.Dislikes}} {
+		if err := json.Unmarshal(f.raw, f.dest); err != nil {
+			return nil, fmt.Errorf("decode dietary profile: %w", err)
+		}
+	}
+	return profile, nil
+}
+
+func (r *DietaryRepository) Upsert(ctx context.Context, profile *Profile) (*Profile, error) {
+	diets, _ := json.Marshal(profile.Diets)
+	allergens, _ := json.Marshal(profile.Allergens)
+	dislikes, _ := json.Marshal(profile.Dislikes)
+
+	query := `
+		INSERT INTO dietary_profiles (user_id, diets, allergens, dislikes)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET diets = EXCLUDED.diets, allergens = EXCLUDED.allergens, dislikes = EXCLUDED.dislikes, updated_at = NOW()
+		RETURNING updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, profile.UserID, diets, allergens, dislikes).Scan(&profile.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("upsert dietary profile: %w", err)
+	}
+	return profile, nil
+}
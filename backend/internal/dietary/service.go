@@ -0,0 +1,94 @@
+package dietary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProfileViolation = errors.New("dietary profile violation")
+	ErrInvalidProfile   = errors.New("invalid dietary profile")
+)
+
+// ViolationError is returned when a recipe, or the ingredients requested
+// for it, break blocking profile rules.
+type ViolationError struct {
+	// Subject is what was checked, e.g. "recipe".
+	Subject    string
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	var parts []string
+	for _, v := range e.Violations {
+		parts = append(parts, fmt.Sprintf("%s (%s)", v.Term, v.Rule))
+	}
+	return fmt.Sprintf("%s conflicts with your dietary profile: %s", e.Subject, strings.Join(parts, ", "))
+}
+
+func (e *ViolationError) Unwrap() error { return ErrProfileViolation }
+
+const maxProfileEntries = 30
+
+type DietaryService struct {
+	repo *DietaryRepository
+}
+
+func NewDietaryService(repo *DietaryRepository) *DietaryService {
+	return &DietaryService{repo: repo}
+}
+
+// Get returns the user's profile; users without one get an empty profile.
+func (s *DietaryService) Get(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	return s.repo.Get(ctx, userID)
+}
+
+func (s *DietaryService) Update(ctx context.Context, userID uuid.UUID, req UpdateProfileRequest) (*Profile, error) {
+	profile := &Profile{
+		UserID:    userID,
+		Diets:     clean(req.Diets),
+		Allergens: clean(allergenIDsOf(req.Allergens)),
+		Dislikes:  clean(req.Dislikes),
+	}
+
+	for _, diet := range profile.Diets {
+		if _, ok := Diets[diet]; !ok {
+			return nil, fmt.Errorf("%w: unknown diet %q", ErrInvalidProfile, diet)
+		}
+	}
+	if len(profile.Allergens) > maxProfileEntries || len(profile.Dislikes) > maxProfileEntries {
+		return nil, fmt.Errorf("%w: at most %d allergens and %d dislikes are allowed", ErrInvalidProfile, maxProfileEntries, maxProfileEntries)
+	}
+
+	return s.repo.Upsert(ctx, profile)
+}
+
+// allergenIDsOf replaces the known allergen names in allergens with their
+// identifiers. Other entries are kept and matched as written.
+func allergenIDsOf(allergens []string) []string {
+	out := make([]string, len(allergens))
+	for i, a := range allergens {
+		out[i] = a
+		if id, ok := allergenID(a); ok {
+			out[i] = id
+		}
+	}
+	return out
+}
+
+// clean lowercases, trims and de-duplicates entries.
+func clean(values []string) []string {
+	out := []string{}
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v != "" && !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
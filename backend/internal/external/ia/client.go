@@ -1 +1,216 @@
 package ia
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+)
+
+// ErrNotConfigured is returned by New when the selected provider is missing
+// the credentials it needs. Callers fall back to offline generation.
+var ErrNotConfigured = errors.New("ai provider not configured")
+
+// Prompt is a single generation request sent to a provider.
+type Prompt struct {
+	System string
+	Text   string
+	// JSON asks the provider to constrain its output to a JSON document.
+	JSON bool
+	// Schema, when set, describes the JSON document the provider must return.
+	// It implies JSON.
+	Schema *Schema
+}
+
+// Schema is the subset of JSON Schema / OpenAPI understood by every provider.
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+}
+
+// Completion is the text returned by a provider and the model that produced it.
+type Completion struct {
+	Text  string
+	Model string
+}
+
+// RecipeGenerator is implemented by every LLM backend used by the chef.
+type RecipeGenerator interface {
+	Generate(ctx context.Context, prompt Prompt) (*Completion, error)
+	Provider() string
+	Model() string
+}
+
+// StreamingGenerator is implemented by providers that can emit partial output
+// while the completion is still being produced. onChunk receives each text
+// delta in order; returning an error from it aborts the stream.
+type StreamingGenerator interface {
+	RecipeGenerator
+	GenerateStream(ctx context.Context, prompt Prompt, onChunk func(string) error) (*Completion, error)
+}
+
+const (
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+	ProviderMock   = "mock"
+)
+
+type Config struct {
+	Provider string
+	Model    string
+	BaseURL  string
+	APIKey   string
+	Timeout  time.Duration
+	Retry    RetryConfig
+	Breaker  BreakerConfig
+	// The image provider defaults to Provider and shares its key and base
+	// URL when it is the same provider.
+	ImageProvider string
+	ImageModel    string
+	ImageBaseURL  string
+	ImageAPIKey   string
+}
+
+// LoadConfig reads the provider configuration from the environment.
+// GEMINI_API_KEY and OPENAI_API_KEY are still honored when LLM_API_KEY is empty.
+func LoadConfig() Config {
+	cfg := Config{
+		Provider: strings.ToLower(util.GetEnv("LLM_PROVIDER", ProviderGemini)),
+		Model:    util.GetEnv("LLM_MODEL", ""),
+		BaseURL:  strings.TrimRight(util.GetEnv("LLM_BASE_URL", ""), "/"),
+		APIKey:   util.GetEnv("LLM_API_KEY", ""),
+		Timeout:  60 * time.Second,
+		Retry: RetryConfig{
+			MaxAttempts: 3,
+			BaseDelay:   500 * time.Millisecond,
+			MaxDelay:    8 * time.Second,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold: 5,
+			Cooldown:         30 * time.Second,
+		},
+	}
+
+	if cfg.APIKey == "" {
+		switch cfg.Provider {
+		case ProviderGemini:
+			cfg.APIKey = util.GetEnv("GEMINI_API_KEY", "")
+		case ProviderOpenAI:
+			cfg.APIKey = util.GetEnv("OPENAI_API_KEY", "")
+		}
+	}
+
+	cfg.ImageProvider = strings.ToLower(util.GetEnv("IMAGE_PROVIDER", cfg.Provider))
+	cfg.ImageModel = util.GetEnv("IMAGE_MODEL", "")
+	if cfg.ImageProvider == cfg.Provider {
+		cfg.ImageBaseURL, cfg.ImageAPIKey = cfg.BaseURL, cfg.APIKey
+	} else {
+		switch cfg.ImageProvider {
+		case ProviderGemini:
+			cfg.ImageAPIKey = util.GetEnv("GEMINI_API_KEY", "")
+		case ProviderOpenAI:
+			cfg.ImageAPIKey = util.GetEnv("OPENAI_API_KEY", "")
+		}
+	}
+
+	if d, err := time.ParseDuration(util.GetEnv("LLM_TIMEOUT", "")); err == nil && d > 0 {
+		cfg.Timeout = d
+	}
+	if n, err := strconv.Atoi(util.GetEnv("LLM_MAX_RETRIES", "")); err == nil && n >= 0 {
+		cfg.Retry.MaxAttempts = n + 1
+	}
+	if d, err := time.ParseDuration(util.GetEnv("LLM_RETRY_BASE_DELAY", "")); err == nil && d > 0 {
+		cfg.Retry.BaseDelay = d
+	}
+	if d, err := time.ParseDuration(util.GetEnv("LLM_RETRY_MAX_DELAY", "")); err == nil && d > 0 {
+		cfg.Retry.MaxDelay = d
+	}
+	if n, err := strconv.Atoi(util.GetEnv("LLM_BREAKER_THRESHOLD", "")); err == nil && n > 0 {
+		cfg.Breaker.FailureThreshold = n
+	}
+	if d, err := time.ParseDuration(util.GetEnv("LLM_BREAKER_COOLDOWN", "")); err == nil && d > 0 {
+		cfg.Breaker.Cooldown = d
+	}
+
+	return cfg
+}
+
+// New builds the generator selected by cfg.Provider. Its outbound calls are
+// retried according to cfg.Retry and guarded by breaker, which may be shared
+// so its state can be reported elsewhere.
+func New(cfg Config, breaker *CircuitBreaker) (RecipeGenerator, error) {
+	client := &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: newResilientTransport(http.DefaultTransport, cfg.Retry, breaker),
+	}
+
+	switch cfg.Provider {
+	case ProviderGemini:
+		if cfg.APIKey == "" {
+			return nil, ErrNotConfigured
+		}
+		return newGeminiClient(cfg, client), nil
+	case ProviderOpenAI:
+		// Self-hosted OpenAI-compatible servers usually don't need a key,
+		// so only the public endpoint requires one.
+		if cfg.APIKey == "" && cfg.BaseURL == "" {
+			return nil, ErrNotConfigured
+		}
+		return newOpenAIClient(cfg, client), nil
+	case ProviderOllama:
+		return newOllamaClient(cfg, client), nil
+	case ProviderMock, "":
+		return nil, ErrNotConfigured
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.Provider)
+	}
+}
+
+// postJSON sends body to url and returns the response only when the
+// provider answered 200 OK. The caller owns the response body.
+func postJSON(ctx context.Context, client *http.Client, provider, url string, header http.Header, body any) (*http.Response, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	for k, v := range header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s API: %w", provider, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, &APIError{
+			Provider:   provider,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       string(bodyBytes),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,196 @@
+package ia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	defaultGeminiModel   = "gemini-2.5-flash"
+)
+
+type geminiClient struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+func newGeminiClient(cfg Config, client *http.Client) *geminiClient {
+	c := &geminiClient{
+		baseURL: cfg.BaseURL,
+		apiKey:  cfg.APIKey,
+		model:   cfg.Model,
+		client:  client,
+	}
+	if c.baseURL == "" {
+		c.baseURL = defaultGeminiBaseURL
+	}
+	if c.model == "" {
+		c.model = defaultGeminiModel
+	}
+	return c
+}
+
+// Gemini API Structures
+type geminiRequest struct {
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiGenerationConfig struct {
+	ResponseMimeType string  `json:"responseMimeType,omitempty"`
+	ResponseSchema   *Schema `json:"responseSchema,omitempty"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"content"`
+	} `json:"candidates"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+func (c *geminiClient) Provider() string { return ProviderGemini }
+
+func (c *geminiClient) Model() string { return c.model }
+
+func (c *geminiClient) Generate(ctx context.Context, prompt Prompt) (*Completion, error) {
+	url := fmt.Sprintf("%s/models/%s:generateContent", c.baseURL, c.model)
+
+	resp, err := postJSON(ctx, c.client, "Gemini", url, c.header(), c.buildRequest(prompt))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var geminiResp geminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	text := geminiResp.text()
+	if text == "" {
+		return nil, fmt.Errorf("no content returned from Gemini")
+	}
+
+	return &Completion{Text: text, Model: geminiResp.model(c.model)}, nil
+}
+
+// GenerateStream proxies streamGenerateContent, which emits one
+// geminiResponse chunk per SSE event.
+func (c *geminiClient) GenerateStream(ctx context.Context, prompt Prompt, onChunk func(string) error) (*Completion, error) {
+	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", c.baseURL, c.model)
+
+	resp, err := postJSON(ctx, c.client, "Gemini", url, c.header(), c.buildRequest(prompt))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	model := c.model
+	err = readSSE(resp.Body, func(data []byte) error {
+		var chunk geminiResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %v", err)
+		}
+		model = chunk.model(model)
+		delta := chunk.text()
+		if delta == "" {
+			return nil
+		}
+		text.WriteString(delta)
+		return onChunk(delta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no content returned from Gemini")
+	}
+
+	return &Completion{Text: text.String(), Model: model}, nil
+}
+
+func (c *geminiClient) header() http.Header {
+	return http.Header{"X-Goog-Api-Key": {c.apiKey}}
+}
+
+func (r geminiResponse) text() string {
+	if len(r.Candidates) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range r.Candidates[0].Content.Parts {
+		sb.WriteString(part.Text)
+	}
+	return sb.String()
+}
+
+func (r geminiResponse) model(fallback string) string {
+	if r.ModelVersion == "" {
+		return fallback
+	}
+	return r.ModelVersion
+}
+
+func (c *geminiClient) buildRequest(prompt Prompt) geminiRequest {
+	req := geminiRequest{
+		Contents: []geminiContent{
+			{
+				Role:  "user",
+				Parts: []geminiPart{{Text: prompt.Text}},
+			},
+		},
+	}
+	if prompt.System != "" {
+		req.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: prompt.System}}}
+	}
+	if prompt.JSON || prompt.Schema != nil {
+		req.GenerationConfig = &geminiGenerationConfig{
+			ResponseMimeType: "application/json",
+			ResponseSchema:   geminiSchema(prompt.Schema),
+		}
+	}
+	return req
+}
+
+// geminiSchema converts s to Gemini's OpenAPI dialect, which spells types in
+// upper case.
+func geminiSchema(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	out := &Schema{
+		Type:        strings.ToUpper(s.Type),
+		Description: s.Description,
+		Items:       geminiSchema(s.Items),
+		Required:    s.Required,
+	}
+	if len(s.Properties) > 0 {
+		out.Properties = make(map[string]*Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			out.Properties[name] = geminiSchema(prop)
+		}
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package ia
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenAIImageModel = "gpt-image-1"
+	defaultGeminiImageModel = "imagen-4.0-generate-001"
+	// ProviderNone disables image generation.
+	ProviderNone = "none"
+)
+
+// Image is a generated picture.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Model       string
+}
+
+// ImageGenerator is implemented by providers that can draw a picture from a
+// text prompt.
+type ImageGenerator interface {
+	GenerateImage(ctx context.Context, prompt string) (*Image, error)
+	Provider() string
+	Model() string
+}
+
+// NewImageGenerator builds the image generator selected by
+// cfg.ImageProvider. It returns ErrNotConfigured when the provider has no
+// image API (Ollama), is disabled or is missing its key.
+func NewImageGenerator(cfg Config, breaker *CircuitBreaker) (ImageGenerator, error) {
+	client := &http.Client{
+		// Images take much longer than text.
+		Timeout:   max(cfg.Timeout, 2*time.Minute),
+		Transport: newResilientTransport(http.DefaultTransport, cfg.Retry, breaker),
+	}
+
+	switch cfg.ImageProvider {
+	case ProviderGemini:
+		if cfg.ImageAPIKey == "" {
+			return nil, ErrNotConfigured
+		}
+		c := newGeminiClient(Config{BaseURL: cfg.ImageBaseURL, APIKey: cfg.ImageAPIKey, Model: cfg.ImageModel}, client)
+		if cfg.ImageModel == "" {
+			c.model = defaultGeminiImageModel
+		}
+		return c, nil
+	case ProviderOpenAI:
+		if cfg.ImageAPIKey == "" && cfg.ImageBaseURL == "" {
+			return nil, ErrNotConfigured
+		}
+		c := newOpenAIClient(Config{BaseURL: cfg.ImageBaseURL, APIKey: cfg.ImageAPIKey, Model: cfg.ImageModel}, client)
+		if cfg.ImageModel == "" {
+			c.model = defaultOpenAIImageModel
+		}
+		return c, nil
+	case ProviderOllama, ProviderMock, ProviderNone, "":
+		return nil, ErrNotConfigured
+	default:
+		return nil, fmt.Errorf("unknown image provider %q", cfg.ImageProvider)
+	}
+}
+
+type openAIImageRequest struct {
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n"`
+	Size           string `json:"size"`
+	ResponseFormat string `json:"response_format,omitempty"`
+}
+
+type openAIImageResponse struct {
+	Data []struct {
+		B64JSON string `json:"b64_json"`
+	} `json:"data"`
+}
+
+func (c *openAIClient) GenerateImage(ctx context.Context, prompt string) (*Image, error) {
+	req := openAIImageRequest{Model: c.model, Prompt: prompt, N: 1, Size: "1024x1024"}
+	// DALL-E answers with URLs unless asked otherwise; gpt-image-1 always
+	// returns base64 and rejects the parameter.
+	if strings.HasPrefix(c.model, "dall-e") {
+		req.ResponseFormat = "b64_json"
+	}
+
+	resp, err := postJSON(ctx, c.client, "OpenAI", c.baseURL+"/images/generations", c.header(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var imageResp openAIImageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&imageResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	if len(imageResp.Data) == 0 || imageResp.Data[0].B64JSON == "" {
+		return nil, fmt.Errorf("no image returned from OpenAI")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(imageResp.Data[0].B64JSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+	return &Image{Data: data, ContentType: http.DetectContentType(data), Model: c.model}, nil
+}
+
+type geminiImageRequest struct {
+	Instances  []geminiImageInstance `json:"instances"`
+	Parameters geminiImageParameters `json:"parameters"`
+}
+
+type geminiImageInstance struct {
+	Prompt string `json:"prompt"`
+}
+
+type geminiImageParameters struct {
+	SampleCount int    `json:"sampleCount"`
+	AspectRatio string `json:"aspectRatio,omitempty"`
+}
+
+type geminiImageResponse struct {
+	Predictions []struct {
+		BytesBase64Encoded string `json:"bytesBase64Encoded"`
+		MimeType           string `json:"mimeType"`
+	} `json:"predictions"`
+}
+
+// GenerateImage calls the Imagen predict endpoint.
+func (c *geminiClient) GenerateImage(ctx context.Context, prompt string) (*Image, error) {
+	url := fmt.Sprintf("%s/models/%s:predict", c.baseURL, c.model)
+	req := geminiImageRequest{
+		Instances:  []geminiImageInstance{{Prompt: prompt}},
+		Parameters: geminiImageParameters{SampleCount: 1, AspectRatio: "4:3"},
+	}
+
+	resp, err := postJSON(ctx, c.client, "Gemini", url, c.header(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var imageResp geminiImageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&imageResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	if len(imageResp.Predictions) == 0 || imageResp.Predictions[0].BytesBase64Encoded == "" {
+		return nil, fmt.Errorf("no image returned from Gemini")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(imageResp.Predictions[0].BytesBase64Encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+	contentType := imageResp.Predictions[0].MimeType
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return &Image{Data: data, ContentType: contentType, Model: c.model}, nil
+}
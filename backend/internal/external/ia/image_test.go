@@ -0,0 +1,52 @@
+package ia
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+func TestGenerateImage(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(pngHeader)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/images/generations":
+			if _, ok := body["response_format"]; ok || body["model"] != defaultOpenAIImageModel {
+				t.Errorf("openai request = %v", body)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": []map[string]string{{"b64_json": encoded}}})
+		case "/models/" + defaultGeminiImageModel + ":predict":
+			json.NewEncoder(w).Encode(map[string]any{"predictions": []map[string]string{{"bytesBase64Encoded": encoded, "mimeType": "image/png"}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(BreakerConfig{FailureThreshold: 5, Cooldown: time.Second})
+	for _, provider := range []string{ProviderOpenAI, ProviderGemini} {
+		gen, err := NewImageGenerator(Config{ImageProvider: provider, ImageBaseURL: server.URL, ImageAPIKey: "key"}, breaker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := gen.GenerateImage(context.Background(), "a bowl of soup")
+		if err != nil {
+			t.Fatalf("%s: %v", provider, err)
+		}
+		if img.ContentType != "image/png" || string(img.Data) != string(pngHeader) || img.Model == "" {
+			t.Errorf("%s: image = %s, %d bytes, model %q", provider, img.ContentType, len(img.Data), img.Model)
+		}
+	}
+
+	if _, err := NewImageGenerator(Config{ImageProvider: ProviderOllama}, breaker); err != ErrNotConfigured {
+		t.Errorf("ollama: err = %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package ia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultOllamaModel   = "llama3.1"
+)
+
+// ollamaClient talks to a local Ollama server through its native /api/chat endpoint.
+type ollamaClient struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func newOllamaClient(cfg Config, client *http.Client) *ollamaClient {
+	c := &ollamaClient{
+		baseURL: cfg.BaseURL,
+		model:   cfg.Model,
+		client:  client,
+	}
+	if c.baseURL == "" {
+		c.baseURL = defaultOllamaBaseURL
+	}
+	if c.model == "" {
+		c.model = defaultOllamaModel
+	}
+	return c
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	// Format is either "json" or a JSON schema object.
+	Format any `json:"format,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaResponse struct {
+	Model   string        `json:"model"`
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+}
+
+func (c *ollamaClient) Provider() string { return ProviderOllama }
+
+func (c *ollamaClient) Model() string { return c.model }
+
+func (c *ollamaClient) Generate(ctx context.Context, prompt Prompt) (*Completion, error) {
+	resp, err := postJSON(ctx, c.client, "Ollama", c.baseURL+"/api/chat", nil, c.buildRequest(prompt, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var ollamaResp ollamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	if ollamaResp.Message.Content == "" {
+		return nil, fmt.Errorf("no content returned from Ollama")
+	}
+
+	model := ollamaResp.Model
+	if model == "" {
+		model = c.model
+	}
+
+	return &Completion{Text: ollamaResp.Message.Content, Model: model}, nil
+}
+
+// GenerateStream reads Ollama's newline-delimited JSON stream.
+func (c *ollamaClient) GenerateStream(ctx context.Context, prompt Prompt, onChunk func(string) error) (*Completion, error) {
+	resp, err := postJSON(ctx, c.client, "Ollama", c.baseURL+"/api/chat", nil, c.buildRequest(prompt, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	model := c.model
+	err = readLines(resp.Body, func(line []byte) error {
+		var chunk ollamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %v", err)
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Message.Content != "" {
+			text.WriteString(chunk.Message.Content)
+			if err := onChunk(chunk.Message.Content); err != nil {
+				return err
+			}
+		}
+		if chunk.Done {
+			return io.EOF
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no content returned from Ollama")
+	}
+
+	return &Completion{Text: text.String(), Model: model}, nil
+}
+
+func (c *ollamaClient) buildRequest(prompt Prompt, stream bool) ollamaRequest {
+	req := ollamaRequest{Model: c.model, Stream: stream}
+	if prompt.System != "" {
+		req.Messages = append(req.Messages, ollamaMessage{Role: "system", Content: prompt.System})
+	}
+	req.Messages = append(req.Messages, ollamaMessage{Role: "user", Content: prompt.Text})
+	switch {
+	case prompt.Schema != nil:
+		req.Format = prompt.Schema
+	case prompt.JSON:
+		req.Format = "json"
+	}
+	return req
+}
@@ -0,0 +1,163 @@
+package ia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-4o-mini"
+)
+
+// openAIClient talks to any server implementing the OpenAI chat completions
+// API (OpenAI itself, vLLM, LM Studio, llama.cpp server, ...).
+type openAIClient struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+func newOpenAIClient(cfg Config, client *http.Client) *openAIClient {
+	c := &openAIClient{
+		baseURL: cfg.BaseURL,
+		apiKey:  cfg.APIKey,
+		model:   cfg.Model,
+		client:  client,
+	}
+	if c.baseURL == "" {
+		c.baseURL = defaultOpenAIBaseURL
+	}
+	if c.model == "" {
+		c.model = defaultOpenAIModel
+	}
+	return c
+}
+
+type openAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	Stream         bool                  `json:"stream,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string  `json:"name"`
+	Schema *Schema `json:"schema"`
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+type openAIStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta openAIMessage `json:"delta"`
+	} `json:"choices"`
+}
+
+func (c *openAIClient) Provider() string { return ProviderOpenAI }
+
+func (c *openAIClient) Model() string { return c.model }
+
+func (c *openAIClient) Generate(ctx context.Context, prompt Prompt) (*Completion, error) {
+	resp, err := postJSON(ctx, c.client, "OpenAI", c.baseURL+"/chat/completions", c.header(), c.buildRequest(prompt, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var openAIResp openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&openAIResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	if len(openAIResp.Choices) == 0 || openAIResp.Choices[0].Message.Content == "" {
+		return nil, fmt.Errorf("no content returned from OpenAI")
+	}
+
+	model := openAIResp.Model
+	if model == "" {
+		model = c.model
+	}
+
+	return &Completion{Text: openAIResp.Choices[0].Message.Content, Model: model}, nil
+}
+
+func (c *openAIClient) GenerateStream(ctx context.Context, prompt Prompt, onChunk func(string) error) (*Completion, error) {
+	resp, err := postJSON(ctx, c.client, "OpenAI", c.baseURL+"/chat/completions", c.header(), c.buildRequest(prompt, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	model := c.model
+	err = readSSE(resp.Body, func(data []byte) error {
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %v", err)
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+		delta := chunk.Choices[0].Delta.Content
+		text.WriteString(delta)
+		return onChunk(delta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no content returned from OpenAI")
+	}
+
+	return &Completion{Text: text.String(), Model: model}, nil
+}
+
+func (c *openAIClient) header() http.Header {
+	if c.apiKey == "" {
+		return nil
+	}
+	return http.Header{"Authorization": {"Bearer " + c.apiKey}}
+}
+
+func (c *openAIClient) buildRequest(prompt Prompt, stream bool) openAIRequest {
+	req := openAIRequest{Model: c.model, Stream: stream}
+	if prompt.System != "" {
+		req.Messages = append(req.Messages, openAIMessage{Role: "system", Content: prompt.System})
+	}
+	req.Messages = append(req.Messages, openAIMessage{Role: "user", Content: prompt.Text})
+	switch {
+	case prompt.Schema != nil:
+		req.ResponseFormat = &openAIResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &openAIJSONSchema{Name: "response", Schema: prompt.Schema},
+		}
+	case prompt.JSON:
+		req.ResponseFormat = &openAIResponseFormat{Type: "json_object"}
+	}
+	return req
+}
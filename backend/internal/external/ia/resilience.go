@@ -0,0 +1,356 @@
+package ia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched (via errors.Is) by the error returned while the
+// circuit breaker rejects calls.
+var ErrCircuitOpen = errors.New("ai provider circuit breaker is open")
+
+// CircuitOpenError is returned instead of calling the provider while the
+// breaker is open.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v (retry in %s)", ErrCircuitOpen, e.RetryAfter.Round(time.Second))
+}
+
+func (e *CircuitOpenError) Is(target error) bool { return target == ErrCircuitOpen }
+
+// APIError is a non-200 answer from a provider.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Status     string
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error: %s - %s", e.Provider, e.Status, e.Body)
+}
+
+// RetryAfter returns how long the caller should wait before trying again,
+// or zero when err doesn't carry that information.
+func RetryAfter(err error) time.Duration {
+	var openErr *CircuitOpenError
+	if errors.As(err, &openErr) {
+		return openErr.RetryAfter
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter understands both forms of the Retry-After header.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+type RetryConfig struct {
+	// MaxAttempts is the total number of tries, including the first one.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// backoff returns a "full jitter" delay for the given retry (0-based).
+func (c RetryConfig) backoff(retry int) time.Duration {
+	ceiling := c.BaseDelay << retry
+	if ceiling <= 0 || ceiling > c.MaxDelay {
+		ceiling = c.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+type BreakerConfig struct {
+	// FailureThreshold consecutive failures open the circuit.
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before a probe is allowed.
+	Cooldown time.Duration
+}
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker stops outbound calls after repeated failures. After the
+// cooldown a single probe is let through: success closes the circuit,
+// failure opens it again.
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	cfg      BreakerConfig
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+	now      func() time.Time
+}
+
+func NewCircuitBreaker(cfg BreakerConfig) *CircuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	return &CircuitBreaker{cfg: cfg, now: time.Now}
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by Success or Failure.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		remaining := b.cfg.Cooldown - b.now().Sub(b.openedAt)
+		if remaining > 0 {
+			return &CircuitOpenError{RetryAfter: remaining}
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return &CircuitOpenError{RetryAfter: time.Second}
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Release gives back an allowed call that ended without telling anything
+// about the provider's health (e.g. the client went away).
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cfg.Cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Health returns the breaker state for the /health endpoint.
+func (b *CircuitBreaker) Health() map[string]string {
+	state := b.State()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := map[string]string{
+		"circuit":          state.String(),
+		"circuit_failures": strconv.Itoa(b.failures),
+	}
+	if state != BreakerClosed {
+		stats["circuit_opened_at"] = b.openedAt.UTC().Format(time.RFC3339)
+	}
+	return stats
+}
+
+// resilientTransport retries transient failures with jittered exponential
+// backoff, honors Retry-After and reports outcomes to a circuit breaker.
+type resilientTransport struct {
+	base    http.RoundTripper
+	retry   RetryConfig
+	breaker *CircuitBreaker
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+func newResilientTransport(base http.RoundTripper, retry RetryConfig, breaker *CircuitBreaker) *resilientTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = 1
+	}
+	return &resilientTransport{base: base, retry: retry, breaker: breaker, sleep: sleepCtx}
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if t.breaker != nil {
+			if err := t.breaker.Allow(); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptReq := req
+		if attempt > 0 {
+			if req.GetBody == nil && req.Body != nil {
+				return nil, fmt.Errorf("cannot retry request without GetBody")
+			}
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		last := attempt+1 >= t.retry.MaxAttempts
+
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				t.release()
+				return nil, err
+			}
+			t.failure()
+			if last {
+				return nil, err
+			}
+			delay = t.retry.backoff(attempt)
+		case retryableStatus(resp.StatusCode):
+			// Rate limiting means the provider is up, so it doesn't count
+			// against the breaker.
+			if resp.StatusCode == http.StatusTooManyRequests {
+				t.success()
+			} else {
+				t.failure()
+			}
+			retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			if last || (retryAfter > 0 && retryAfter > t.retry.MaxDelay) {
+				// Out of attempts, or the provider asked for a longer pause
+				// than we are willing to hold the request for.
+				return resp, nil
+			}
+			drain(resp)
+			delay = retryAfter
+			if delay == 0 {
+				delay = t.retry.backoff(attempt)
+			}
+		default:
+			t.success()
+			return resp, nil
+		}
+
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *resilientTransport) success() {
+	if t.breaker != nil {
+		t.breaker.Success()
+	}
+}
+
+func (t *resilientTransport) failure() {
+	if t.breaker != nil {
+		t.breaker.Failure()
+	}
+}
+
+func (t *resilientTransport) release() {
+	if t.breaker != nil {
+		t.breaker.Release()
+	}
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,194 @@
+package ia
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	b := NewCircuitBreaker(BreakerConfig{FailureThreshold: 2, Cooldown: 10 * time.Second})
+	b.now = clock.now
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("closed breaker rejected call %d: %v", i, err)
+		}
+		b.Failure()
+	}
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+
+	err := b.Allow()
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("open breaker returned %v, want ErrCircuitOpen", err)
+	}
+	if got := RetryAfter(err); got != 10*time.Second {
+		t.Errorf("RetryAfter = %s, want 10s", got)
+	}
+
+	clock.t = clock.t.Add(10 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe rejected after cooldown: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second call during probe returned %v, want ErrCircuitOpen", err)
+	}
+
+	// Failed probe reopens the circuit.
+	b.Failure()
+	if b.State() != BreakerOpen {
+		t.Fatalf("state after failed probe = %s, want open", b.State())
+	}
+
+	clock.t = clock.t.Add(10 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe rejected after second cooldown: %v", err)
+	}
+	b.Success()
+	if b.State() != BreakerClosed {
+		t.Fatalf("state after successful probe = %s, want closed", b.State())
+	}
+	if h := b.Health(); h["circuit"] != "closed" || h["circuit_failures"] != "0" {
+		t.Errorf("Health() = %v", h)
+	}
+}
+
+func newTestTransport(retry RetryConfig, breaker *CircuitBreaker) (*resilientTransport, *[]time.Duration) {
+	var sleeps []time.Duration
+	tr := newResilientTransport(http.DefaultTransport, retry, breaker)
+	tr.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return ctx.Err()
+	}
+	return tr, &sleeps
+}
+
+func post(t *testing.T, client *http.Client, url string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(`{"prompt":"x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.Do(req)
+}
+
+func TestResilientTransportRetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	breaker := NewCircuitBreaker(BreakerConfig{FailureThreshold: 5})
+	tr, sleeps := newTestTransport(RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second}, breaker)
+
+	resp, err := post(t, &http.Client{Transport: tr}, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if calls.Load() != 3 || len(*sleeps) != 2 {
+		t.Errorf("calls = %d, sleeps = %d; want 3 calls and 2 sleeps", calls.Load(), len(*sleeps))
+	}
+	if breaker.State() != BreakerClosed {
+		t.Errorf("breaker = %s, want closed after success", breaker.State())
+	}
+}
+
+func TestResilientTransportHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	breaker := NewCircuitBreaker(BreakerConfig{FailureThreshold: 1})
+	tr, sleeps := newTestTransport(RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second}, breaker)
+
+	resp, err := post(t, &http.Client{Transport: tr}, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(*sleeps) != 1 || (*sleeps)[0] != 2*time.Second {
+		t.Errorf("sleeps = %v, want [2s]", *sleeps)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Errorf("429 tripped the breaker: %s", breaker.State())
+	}
+}
+
+func TestResilientTransportFailsFastWhenOpen(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	breaker := NewCircuitBreaker(BreakerConfig{FailureThreshold: 2, Cooldown: time.Minute})
+	tr, _ := newTestTransport(RetryConfig{MaxAttempts: 2}, breaker)
+	client := &http.Client{Transport: tr}
+
+	resp, err := post(t, client, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502 on the last attempt", resp.StatusCode)
+	}
+
+	_, err = post(t, client, srv.URL)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("provider called %d times, want 2", calls.Load())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"garbage", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
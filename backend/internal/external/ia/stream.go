@@ -0,0 +1,47 @@
+package ia
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+const maxStreamLine = 1024 * 1024
+
+// readSSE calls onData with the payload of every "data:" line of a
+// Server-Sent Events stream until EOF or a "[DONE]" sentinel.
+func readSSE(r io.Reader, onData func([]byte) error) error {
+	return readLines(r, func(line []byte) error {
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			return nil
+		}
+		data := bytes.TrimSpace(line[len("data:"):])
+		if len(data) == 0 {
+			return nil
+		}
+		if bytes.Equal(data, []byte("[DONE]")) {
+			return io.EOF
+		}
+		return onData(data)
+	})
+}
+
+// readLines calls onLine with every non-empty line of r. Returning io.EOF
+// from onLine stops reading without an error.
+func readLines(r io.Reader, onLine func([]byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := onLine(line); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	// maxPageSize bounds the pages and documents read for an import.
+	maxPageSize  = 5 << 20
+	fetchTimeout = 15 * time.Second
+	maxRedirects = 5
+)
+
+var (
+	ErrFetch = errors.New("could not fetch the page")
+	// ErrBlockedAddress is returned for URLs that resolve to loopback,
+	// private or otherwise internal addresses.
+	ErrBlockedAddress  = errors.New("url points to a private network address")
+	ErrPageTooLarge    = errors.New("page is too large to import")
+	ErrUnsupportedPage = errors.New("page is not HTML or JSON-LD")
+)
+
+// Page is a fetched document. URL is where it was found after redirects.
+type Page struct {
+	URL         string
+	ContentType string
+	Body        []byte
+}
+
+// IsJSON reports whether the page is a JSON (or JSON-LD) document rather
+// than HTML.
+func (p *Page) IsJSON() bool {
+	return p.ContentType == "application/json" || p.ContentType == "application/ld+json"
+}
+
+// Fetcher loads the page at a URL.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (*Page, error)
+}
+
+// HTTPFetcher fetches pages over HTTP with Client.
+type HTTPFetcher struct {
+	Client *http.Client
+}
+
+// NewHTTPFetcher returns a fetcher whose client refuses to connect to
+// internal addresses, so imports cannot be used to probe the server's
+// network.
+func NewHTTPFetcher() *HTTPFetcher {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+				return ErrBlockedAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &HTTPFetcher{Client: &http.Client{Transport: transport, Timeout: fetchTimeout}}
+}
+
+// blockedPrefixes are the special-purpose ranges net.IP has no predicate
+// for: carrier-grade NAT, often used for internal cloud networks, IETF
+// protocol assignments and benchmarking.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+func isPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	if _, err := parseWebURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	client := *f.Client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("%w: too many redirects", ErrFetch)
+		}
+		if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+			return fmt.Errorf("%w: redirected to a %s URL", ErrFetch, req.URL.Scheme)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	req.Header.Set("Accept", "text/html, application/ld+json;q=0.9, application/json;q=0.8")
+	req.Header.Set("User-Agent", "FridgeChef recipe importer")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return nil, ErrBlockedAddress
+		}
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: the site answered %s", ErrFetch, resp.Status)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch contentType {
+	case "text/html", "application/xhtml+xml", "application/json", "application/ld+json":
+	case "":
+		contentType = "text/html"
+	default:
+		return nil, ErrUnsupportedPage
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	if len(body) > maxPageSize {
+		return nil, ErrPageTooLarge
+	}
+
+	return &Page{URL: resp.Request.URL.String(), ContentType: contentType, Body: body}, nil
+}
+
+// parseWebURL accepts absolute http and https URLs.
+func parseWebURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an http or https address", ErrInvalidImport)
+	}
+	return u, nil
+}
@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pancakes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(yoastPage))
+	})
+	mux.HandleFunc("/recipe.jsonld", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/ld+json")
+		w.Write([]byte(`{"@type":"Recipe","name":"Toast","recipeIngredient":["2 slices bread"],"recipeInstructions":"Toast the bread."}`))
+	})
+	mux.HandleFunc("/blog", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><nav>Home | About</nav><article><h1>Grandma's soup</h1>
+<p>You need 1 onion and 2 carrots.</p><p>Simmer everything for an hour.</p></article>
+<script>track()</script></body></html>`))
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/pancakes", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/photo.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	})
+	mux.HandleFunc("/huge", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(strings.Repeat("a", maxPageSize+1)))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	server := newFixtureServer(t)
+	fetcher := &HTTPFetcher{Client: server.Client()}
+	ctx := context.Background()
+
+	page, err := fetcher.Fetch(ctx, server.URL+"/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.URL != server.URL+"/pancakes" || page.ContentType != "text/html" || page.IsJSON() {
+		t.Errorf("page = %s %s; want the redirect target as HTML", page.URL, page.ContentType)
+	}
+
+	for path, want := range map[string]error{
+		"/missing":   ErrFetch,
+		"/photo.png": ErrUnsupportedPage,
+		"/huge":      ErrPageTooLarge,
+	} {
+		if _, err := fetcher.Fetch(ctx, server.URL+path); !errors.Is(err, want) {
+			t.Errorf("%s: error = %v; want %v", path, err, want)
+		}
+	}
+	if _, err := fetcher.Fetch(ctx, "file:///etc/passwd"); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("file URL: error = %v; want ErrInvalidImport", err)
+	}
+
+	// The production fetcher refuses the loopback address the fixture
+	// server listens on.
+	if _, err := NewHTTPFetcher().Fetch(ctx, server.URL+"/pancakes"); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("loopback: error = %v; want ErrBlockedAddress", err)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":     true,
+		"2606:4700::1111":   true,
+		"127.0.0.1":         false,
+		"10.1.2.3":          false,
+		"169.254.169.254":   false,
+		"100.64.0.1":        false,
+		"100.127.255.254":   false,
+		"100.128.0.1":       true,
+		"192.0.0.8":         false,
+		"198.18.0.1":        false,
+		"198.19.255.255":    false,
+		"::ffff:100.64.0.1": false,
+		"fd00::1":           false,
+		"::1":               false,
+		"::":                false,
+	} {
+		if got := isPublic(net.ParseIP(addr)); got != want {
+			t.Errorf("isPublic(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
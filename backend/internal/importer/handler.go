@@ -0,0 +1,62 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/Igorlimaponce/fridgeChef/backend/middleware"
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+	"github.com/google/uuid"
+)
+
+type ImportHandler struct {
+	service *ImportService
+}
+
+func NewImportHandler(service *ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// Import saves a recipe read from a URL, an HTML page or a JSON-LD
+// document.
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req ImportRequest
+	// Leave room for the JSON encoding of a page of maxPageSize.
+	r.Body = http.MaxBytesReader(w, r.Body, 2*maxPageSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			util.WriteError(w, http.StatusRequestEntityTooLarge, ErrPageTooLarge.Error())
+			return
+		}
+		util.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	result, err := h.service.Import(r.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidImport), errors.Is(err, ErrBlockedAddress), errors.Is(err, recipe.ErrInvalidRecipe):
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrPageTooLarge):
+			util.WriteError(w, http.StatusRequestEntityTooLarge, err.Error())
+		case errors.Is(err, ErrNoRecipe), errors.Is(err, ErrUnsupportedPage):
+			util.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, ErrFetch):
+			util.WriteError(w, http.StatusBadGateway, err.Error())
+		default:
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, result)
+}
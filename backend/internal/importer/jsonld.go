@@ -0,0 +1,255 @@
+package importer
+
+import (
+	"encoding/json"
+	"html"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+)
+
+const (
+	// maxTitleLength is the size of the recipes.title column.
+	maxTitleLength = 255
+	maxTags        = 10
+)
+
+var (
+	ldScript = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script\s*>`)
+	htmlTag  = regexp.MustCompile(`(?s)<[^>]*>`)
+	// blockTag matches the tags that break instructions into lines.
+	blockTag   = regexp.MustCompile(`(?i)<\s*/?\s*(?:br|p|li|div|ol|ul|h[1-6])\b[^>]*>`)
+	stepNumber = regexp.MustCompile(`(?i)^(?:step\s*\d+\s*[.):-]?|\d+[.)])\s*`)
+	number     = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+	// spaceBeforePunct is left where a tag is stripped before punctuation.
+	spaceBeforePunct = regexp.MustCompile(`\s+([.,;:!?)])`)
+	// isoDuration matches the ISO 8601 durations schema.org uses for times,
+	// such as PT1H30M or P0DT0H20M.
+	isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+)
+
+// findJSONLD returns the JSON-LD documents embedded in an HTML page.
+func findJSONLD(page []byte) [][]byte {
+	var docs [][]byte
+	for _, m := range ldScript.FindAllSubmatch(page, -1) {
+		doc := strings.TrimSpace(string(m[1]))
+		// Some sites wrap the JSON in an HTML comment or CDATA section.
+		doc = strings.TrimPrefix(strings.TrimSuffix(doc, "-->"), "<!--")
+		doc = strings.TrimPrefix(strings.TrimSuffix(doc, "]]>"), "<![CDATA[")
+		docs = append(docs, []byte(strings.TrimSpace(doc)))
+	}
+	return docs
+}
+
+// findRecipe returns the first schema.org Recipe node of a JSON-LD
+// document, looking through arrays, @graph and mainEntity.
+func findRecipe(doc []byte) map[string]any {
+	var v any
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return nil
+	}
+	return walkRecipe(v, 0)
+}
+
+func walkRecipe(v any, depth int) map[string]any {
+	if depth > 8 {
+		return nil
+	}
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if node := walkRecipe(item, depth+1); node != nil {
+				return node
+			}
+		}
+	case map[string]any:
+		if slices.Contains(texts(v["@type"]), "Recipe") {
+			return v
+		}
+		for _, key := range []string{"@graph", "mainEntity", "mainEntityOfPage"} {
+			if node := walkRecipe(v[key], depth+1); node != nil {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+// fromSchema converts a schema.org Recipe node. It returns false when the
+// node lacks a name, ingredients or instructions.
+func fromSchema(node map[string]any, language string) (recipe.CreateRecipeRequest, bool) {
+	req := recipe.CreateRecipeRequest{
+		Title:    truncate(cleanText(text(node["name"])), maxTitleLength),
+		Language: language,
+	}
+	if lang := text(node["inLanguage"]); lang != "" {
+		req.Language = lang
+	}
+
+	for _, line := range texts(node["recipeIngredient"]) {
+		if line = cleanText(line); line != "" {
+			req.Ingredients = append(req.Ingredients, recipe.ParseIngredientLine(line))
+		}
+	}
+	// recipeIngredient replaced the older ingredients property.
+	if len(req.Ingredients) == 0 {
+		for _, line := range texts(node["ingredients"]) {
+			if line = cleanText(line); line != "" {
+				req.Ingredients = append(req.Ingredients, recipe.ParseIngredientLine(line))
+			}
+		}
+	}
+	req.Steps = instructions(node["recipeInstructions"], 0)
+
+	if req.Title == "" || len(req.Ingredients) == 0 || len(req.Steps) == 0 {
+		return req, false
+	}
+
+	req.Servings = servings(node["recipeYield"])
+	req.PrepTimeMinutes = duration(text(node["prepTime"]))
+	req.CookTimeMinutes = duration(text(node["cookTime"]))
+	if total := duration(text(node["totalTime"])); req.CookTimeMinutes == 0 && total > req.PrepTimeMinutes {
+		req.CookTimeMinutes = total - req.PrepTimeMinutes
+	}
+	if nutrition, ok := node["nutrition"].(map[string]any); ok {
+		req.CaloriesEstimate = int(math.Round(firstNumber(text(nutrition["calories"]))))
+	}
+	req.Cuisine = truncate(cleanText(text(node["recipeCuisine"])), 100)
+	req.Tags = tags(node)
+	return req, true
+}
+
+// instructions flattens recipeInstructions, which may be a block of text,
+// a list of strings, HowToSteps or HowToSections of steps.
+func instructions(v any, depth int) []string {
+	if depth > 4 {
+		return nil
+	}
+	var steps []string
+	switch v := v.(type) {
+	case string:
+		for _, line := range strings.Split(blockTag.ReplaceAllString(v, "\n"), "\n") {
+			if line = stepNumber.ReplaceAllString(cleanText(line), ""); line != "" {
+				steps = append(steps, line)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			steps = append(steps, instructions(item, depth+1)...)
+		}
+	case map[string]any:
+		if list, ok := v["itemListElement"]; ok {
+			return instructions(list, depth+1)
+		}
+		step := text(v["text"])
+		if step == "" {
+			step = text(v["name"])
+		}
+		if step = stepNumber.ReplaceAllString(cleanText(step), ""); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// servings reads recipeYield such as 4, "4", "Serves 4-6" or
+// ["4", "4 servings"], keeping the first count it finds.
+func servings(v any) int {
+	for _, yield := range texts(v) {
+		if n := int(firstNumber(yield)); n > 0 {
+			return min(n, recipe.MaxServings)
+		}
+	}
+	return 0
+}
+
+// duration converts an ISO 8601 duration to whole minutes.
+func duration(s string) int {
+	m := isoDuration.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return 0
+	}
+	var minutes float64
+	for i, scale := range []float64{24 * 60, 60, 1, 1.0 / 60} {
+		if m[i+1] != "" {
+			n, _ := strconv.ParseFloat(m[i+1], 64)
+			minutes += n * scale
+		}
+	}
+	return int(math.Round(minutes))
+}
+
+// tags collects keywords and categories as normalized tags.
+func tags(node map[string]any) []string {
+	var out []string
+	for _, key := range []string{"recipeCategory", "keywords"} {
+		for _, value := range texts(node[key]) {
+			for _, raw := range strings.Split(value, ",") {
+				tag, err := recipe.NormalizeTag(cleanText(raw))
+				if err == nil && !slices.Contains(out, tag) && len(out) < maxTags {
+					out = append(out, tag)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func firstNumber(s string) float64 {
+	n, _ := strconv.ParseFloat(strings.ReplaceAll(number.FindString(s), ",", "."), 64)
+	return n
+}
+
+// text reads a JSON-LD value as a string: strings and numbers as they
+// are, the first item of a list and the value, text or name of a node.
+func text(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		if len(v) > 0 {
+			return text(v[0])
+		}
+	case map[string]any:
+		for _, key := range []string{"@value", "text", "name"} {
+			if s := text(v[key]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// texts reads a JSON-LD value that may be a single value or a list.
+func texts(v any) []string {
+	list, ok := v.([]any)
+	if !ok {
+		list = []any{v}
+	}
+	var out []string
+	for _, item := range list {
+		if s := text(item); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// cleanText strips markup and entities and collapses whitespace.
+func cleanText(s string) string {
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, " "))
+	return spaceBeforePunct.ReplaceAllString(strings.Join(strings.Fields(s), " "), "$1")
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return strings.TrimSpace(string(r[:n]))
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"slices"
+	"testing"
+)
+
+// yoastPage is shaped like the pages WordPress recipe plugins publish: the
+// recipe sits in an @graph next to the page and site nodes.
+const yoastPage = `<!doctype html>
+<html><head>
+<title>Best Pancakes</title>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"WebSite","name":"Cooking Blog"}</script>
+<script type="application/ld+json" class="yoast-schema-graph">
+{"@context":"https://schema.org","@graph":[
+  {"@type":"WebPage","@id":"https://example.com/pancakes","name":"Best Pancakes"},
+  {"@type":["Recipe","NewsArticle"],
+   "name":"Fluffy Pancakes &amp; Syrup",
+   "inLanguage":"en-US",
+   "recipeYield":["4","4 servings"],
+   "prepTime":"PT10M","totalTime":"PT30M",
+   "recipeCuisine":["American"],
+   "recipeCategory":"Breakfast",
+   "keywords":"pancakes, Quick , breakfast",
+   "nutrition":{"@type":"NutritionInformation","calories":"320 kcal"},
+   "recipeIngredient":["2 cups flour","2 eggs","1 1/2 cups <b>milk</b>"],
+   "recipeInstructions":[
+     {"@type":"HowToSection","name":"Batter","itemListElement":[
+       {"@type":"HowToStep","text":"Whisk the flour, eggs and milk."},
+       {"@type":"HowToStep","text":"Rest for 5 minutes."}]},
+     {"@type":"HowToStep","name":"Cook","text":"Step 3: Fry ladlefuls until golden."}]}
+]}
+</script>
+</head><body><h1>Best Pancakes</h1></body></html>`
+
+func TestFromSchema(t *testing.T) {
+	var node map[string]any
+	for _, doc := range findJSONLD([]byte(yoastPage)) {
+		if node = findRecipe(doc); node != nil {
+			break
+		}
+	}
+	if node == nil {
+		t.Fatal("no recipe found in the page")
+	}
+
+	req, ok := fromSchema(node, "pt")
+	if !ok {
+		t.Fatalf("fromSchema rejected the recipe: %+v", req)
+	}
+	if req.Title != "Fluffy Pancakes & Syrup" || req.Language != "en-US" || req.Cuisine != "American" {
+		t.Errorf("title/language/cuisine = %q %q %q", req.Title, req.Language, req.Cuisine)
+	}
+	if req.Servings != 4 || req.PrepTimeMinutes != 10 || req.CookTimeMinutes != 20 || req.CaloriesEstimate != 320 {
+		t.Errorf("servings/prep/cook/calories = %d %d %d %d", req.Servings, req.PrepTimeMinutes, req.CookTimeMinutes, req.CaloriesEstimate)
+	}
+	if len(req.Ingredients) != 3 || req.Ingredients[2].Name != "milk" || req.Ingredients[2].Quantity != 1.5 || req.Ingredients[2].Unit != "cups" {
+		t.Errorf("ingredients = %+v", req.Ingredients)
+	}
+	want := []string{"Whisk the flour, eggs and milk.", "Rest for 5 minutes.", "Fry ladlefuls until golden."}
+	if !slices.Equal(req.Steps, want) {
+		t.Errorf("steps = %q; want %q", req.Steps, want)
+	}
+	if !slices.Equal(req.Tags, []string{"breakfast", "pancakes", "quick"}) {
+		t.Errorf("tags = %q", req.Tags)
+	}
+
+	if _, ok := fromSchema(map[string]any{"@type": "Recipe", "name": "Toast"}, "en"); ok {
+		t.Error("recipe without ingredients or steps accepted")
+	}
+}
+
+func TestInstructionText(t *testing.T) {
+	got := instructions("<p>1. Boil the pasta.</p><p>2) Drain it.<br>Serve with <em>cheese</em>.</p>", 0)
+	want := []string{"Boil the pasta.", "Drain it.", "Serve with cheese."}
+	if !slices.Equal(got, want) {
+		t.Errorf("instructions = %q; want %q", got, want)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	for in, want := range map[string]int{
+		"PT20M":     20,
+		"PT1H30M":   90,
+		"P0DT0H45M": 45,
+		"PT1.5H":    90,
+		"P1D":       1440,
+		"pt90s":     2,
+		"20 min":    0,
+		"":          0,
+	} {
+		if got := duration(in); got != want {
+			t.Errorf("duration(%q) = %d; want %d", in, got, want)
+		}
+	}
+}
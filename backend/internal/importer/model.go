@@ -0,0 +1,30 @@
+package importer
+
+import (
+	"encoding/json"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+)
+
+// Sources of an imported recipe.
+const (
+	SourceJSONLD = "json-ld"
+	SourceModel  = "model"
+)
+
+// ImportRequest names what to import: a page to fetch, an HTML page or a
+// JSON-LD document. Exactly one of URL, HTML and JSONLD is set.
+type ImportRequest struct {
+	URL    string          `json:"url,omitempty"`
+	HTML   string          `json:"html,omitempty"`
+	JSONLD json.RawMessage `json:"json_ld,omitempty"`
+	// Language is used when the document does not say.
+	Language string `json:"language,omitempty"`
+}
+
+// ImportResult is the saved recipe and whether it was read from the page's
+// recipe markup or extracted by the model.
+type ImportResult struct {
+	Recipe *recipe.Recipe `json:"recipe"`
+	Source string         `json:"source"`
+}
@@ -0,0 +1,33 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxPageText bounds the page text sent to the model.
+const maxPageText = 20000
+
+var (
+	// hiddenBlock matches elements whose content is never page text.
+	hiddenBlock = regexp.MustCompile(`(?is)<(script|style|noscript|svg|template|iframe)\b.*?</(?:script|style|noscript|svg|template|iframe)\s*>`)
+	comment     = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// chrome matches page furniture that rarely holds the recipe.
+	chrome = regexp.MustCompile(`(?is)<(nav|header|footer|aside|form)\b.*?</(?:nav|header|footer|aside|form)\s*>`)
+)
+
+// pageText returns the readable text of an HTML page, one block per line.
+func pageText(page []byte) string {
+	s := comment.ReplaceAllString(string(page), " ")
+	s = hiddenBlock.ReplaceAllString(s, " ")
+	s = chrome.ReplaceAllString(s, " ")
+	s = blockTag.ReplaceAllString(s, "\n")
+
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = cleanText(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return truncate(strings.Join(lines, "\n"), maxPageText)
+}
@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quota"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidImport = errors.New("invalid import")
+	// ErrNoRecipe is returned when the document has no recipe that could be
+	// read.
+	ErrNoRecipe = errors.New("no recipe found on the page")
+)
+
+type ImportService struct {
+	fetcher Fetcher
+	recipes *recipe.RecipeService
+	// generator extracts recipes from pages without recipe markup; when
+	// nil such pages cannot be imported.
+	generator ia.RecipeGenerator
+}
+
+func NewImportService(fetcher Fetcher, recipes *recipe.RecipeService, generator ia.RecipeGenerator) *ImportService {
+	return &ImportService{fetcher: fetcher, recipes: recipes, generator: generator}
+}
+
+// Import reads a recipe and saves it for the user. The generation spent by
+// the quota middleware is refunded unless the model had to read the page.
+func (s *ImportService) Import(ctx context.Context, userID uuid.UUID, req ImportRequest) (*ImportResult, error) {
+	create, source, err := s.read(ctx, req)
+	if source != SourceModel {
+		quota.Refund(ctx, 1)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.recipes.CreateRecipe(ctx, userID, create)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportResult{Recipe: saved, Source: source}, nil
+}
+
+// read turns the request into a recipe to save and tells where it came
+// from. The source is set whenever the model was called, even on failure.
+func (s *ImportService) read(ctx context.Context, req ImportRequest) (recipe.CreateRecipeRequest, string, error) {
+	var none recipe.CreateRecipeRequest
+	given := 0
+	for _, set := range []bool{req.URL != "", req.HTML != "", len(req.JSONLD) > 0} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return none, "", fmt.Errorf("%w: give exactly one of url, html and json_ld", ErrInvalidImport)
+	}
+
+	switch {
+	case len(req.JSONLD) > 0:
+		return s.readJSONLD(req.JSONLD, req.Language)
+	case req.HTML != "":
+		return s.readHTML(ctx, []byte(req.HTML), req.Language)
+	}
+
+	page, err := s.fetcher.Fetch(ctx, req.URL)
+	if err != nil {
+		return none, "", err
+	}
+	var create recipe.CreateRecipeRequest
+	var source string
+	if page.IsJSON() {
+		create, source, err = s.readJSONLD(page.Body, req.Language)
+	} else {
+		create, source, err = s.readHTML(ctx, page.Body, req.Language)
+	}
+	create.SourceURL = page.URL
+	return create, source, err
+}
+
+func (s *ImportService) readJSONLD(doc []byte, language string) (recipe.CreateRecipeRequest, string, error) {
+	if node := findRecipe(doc); node != nil {
+		if create, ok := fromSchema(node, language); ok {
+			return create, SourceJSONLD, nil
+		}
+	}
+	return recipe.CreateRecipeRequest{}, "", ErrNoRecipe
+}
+
+// readHTML reads the page's JSON-LD recipe markup and falls back to the
+// model when there is none.
+func (s *ImportService) readHTML(ctx context.Context, page []byte, language string) (recipe.CreateRecipeRequest, string, error) {
+	for _, doc := range findJSONLD(page) {
+		if create, source, err := s.readJSONLD(doc, language); err == nil {
+			return create, source, nil
+		}
+	}
+	if s.generator == nil {
+		return recipe.CreateRecipeRequest{}, "", ErrNoRecipe
+	}
+
+	create, ok, err := extract(ctx, s.generator, page, language)
+	if err != nil {
+		return create, SourceModel, err
+	}
+	if !ok {
+		return create, SourceModel, ErrNoRecipe
+	}
+	return create, SourceModel, nil
+}
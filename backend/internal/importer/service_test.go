@@ -0,0 +1,85 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+)
+
+type stubGenerator struct {
+	output string
+	prompt string
+}
+
+func (g *stubGenerator) Generate(_ context.Context, prompt ia.Prompt) (*ia.Completion, error) {
+	g.prompt = prompt.Text
+	return &ia.Completion{Text: g.output}, nil
+}
+
+func (g *stubGenerator) Provider() string { return "stub" }
+
+func (g *stubGenerator) Model() string { return "stub-1" }
+
+func TestRead(t *testing.T) {
+	server := newFixtureServer(t)
+	s := NewImportService(&HTTPFetcher{Client: server.Client()}, nil, nil)
+	ctx := context.Background()
+
+	req, source, err := s.read(ctx, ImportRequest{URL: server.URL + "/pancakes"})
+	if err != nil || source != SourceJSONLD {
+		t.Fatalf("read = %s, %v", source, err)
+	}
+	if req.Title != "Fluffy Pancakes & Syrup" || req.SourceURL != server.URL+"/pancakes" {
+		t.Errorf("title/source = %q %q", req.Title, req.SourceURL)
+	}
+
+	req, _, err = s.read(ctx, ImportRequest{URL: server.URL + "/recipe.jsonld", Language: "en"})
+	if err != nil || req.Title != "Toast" || len(req.Steps) != 1 || req.Language != "en" {
+		t.Errorf("JSON-LD document = %+v, %v", req, err)
+	}
+
+	if _, _, err := s.read(ctx, ImportRequest{URL: server.URL + "/blog"}); !errors.Is(err, ErrNoRecipe) {
+		t.Errorf("page without markup and no model: error = %v; want ErrNoRecipe", err)
+	}
+
+	for name, bad := range map[string]ImportRequest{
+		"nothing": {},
+		"two":     {URL: server.URL + "/pancakes", HTML: yoastPage},
+	} {
+		if _, _, err := s.read(ctx, bad); !errors.Is(err, ErrInvalidImport) {
+			t.Errorf("%s: error = %v; want ErrInvalidImport", name, err)
+		}
+	}
+}
+
+func TestReadWithModel(t *testing.T) {
+	server := newFixtureServer(t)
+	gen := &stubGenerator{output: "```json\n" + `{"title":"Grandma's soup","servings":0,"ingredients":["1 onion","2 carrots"],"steps":["Simmer everything for an hour."]}` + "\n```"}
+	s := NewImportService(&HTTPFetcher{Client: server.Client()}, nil, gen)
+	ctx := context.Background()
+
+	req, source, err := s.read(ctx, ImportRequest{URL: server.URL + "/blog"})
+	if err != nil || source != SourceModel {
+		t.Fatalf("read = %s, %v", source, err)
+	}
+	if req.Title != "Grandma's soup" || len(req.Ingredients) != 2 || req.Ingredients[1].Quantity != 2 || req.Model != "stub-1" {
+		t.Errorf("extracted = %+v", req)
+	}
+	if !strings.Contains(gen.prompt, "1 onion and 2 carrots") || strings.Contains(gen.prompt, "track()") || strings.Contains(gen.prompt, "About") {
+		t.Errorf("prompt does not carry just the page text:\n%s", gen.prompt)
+	}
+
+	// Pages with recipe markup never reach the model.
+	gen.prompt = ""
+	if _, source, err := s.read(ctx, ImportRequest{HTML: yoastPage}); err != nil || source != SourceJSONLD || gen.prompt != "" {
+		t.Errorf("markup page: source %s, err %v, prompted %v", source, err, gen.prompt != "")
+	}
+
+	gen.output = `{"title":"","ingredients":[],"steps":[]}`
+	if _, source, err := s.read(ctx, ImportRequest{URL: server.URL + "/blog"}); !errors.Is(err, ErrNoRecipe) || source != SourceModel {
+		t.Errorf("no recipe: source %s, error %v; want ErrNoRecipe from the model", source, err)
+	}
+}
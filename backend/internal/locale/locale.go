@@ -0,0 +1,135 @@
+// Package locale is the registry of languages recipes can be generated in.
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnsupported = errors.New("unsupported language")
+
+// UnitSystem is the measurement convention recipes use.
+type UnitSystem string
+
+const (
+	Metric   UnitSystem = "metric"
+	Imperial UnitSystem = "imperial"
+)
+
+// Language is a supported language, identified by its canonical BCP-47 tag.
+type Language struct {
+	Tag string `json:"tag"`
+	// Name is the English name, used in prompts.
+	Name string `json:"name"`
+	// Native is the name in the language itself.
+	Native string     `json:"native"`
+	Units  UnitSystem `json:"units"`
+}
+
+// Base returns the primary language subtag, e.g. "pt" for "pt-BR".
+// Localized content (labels, messages, mock recipes) is keyed by it.
+func (l Language) Base() string {
+	base, _, _ := strings.Cut(l.Tag, "-")
+	return base
+}
+
+// Default is used when a request doesn't specify a language.
+var Default = Language{Tag: "en", Name: "English", Native: "English", Units: Metric}
+
+var languages = []Language{
+	Default,
+	{Tag: "en-US", Name: "American English", Native: "English (US)", Units: Imperial},
+	{Tag: "en-GB", Name: "British English", Native: "English (UK)", Units: Metric},
+	{Tag: "pt-BR", Name: "Brazilian Portuguese", Native: "Português (Brasil)", Units: Metric},
+	{Tag: "pt-PT", Name: "European Portuguese", Native: "Português (Portugal)", Units: Metric},
+	{Tag: "es", Name: "Spanish", Native: "Español", Units: Metric},
+	{Tag: "es-ES", Name: "Spanish (Spain)", Native: "Español (España)", Units: Metric},
+	{Tag: "es-MX", Name: "Mexican Spanish", Native: "Español (México)", Units: Metric},
+	{Tag: "fr", Name: "French", Native: "Français", Units: Metric},
+	{Tag: "fr-FR", Name: "French (France)", Native: "Français (France)", Units: Metric},
+	{Tag: "fr-CA", Name: "Canadian French", Native: "Français (Canada)", Units: Metric},
+}
+
+// aliases map tags without a registry entry of their own to the language
+// they have always meant in this app.
+var aliases = map[string]string{
+	"pt": "pt-BR",
+}
+
+var registry = func() map[string]Language {
+	m := make(map[string]Language, len(languages))
+	for _, l := range languages {
+		m[strings.ToLower(l.Tag)] = l
+	}
+	for alias, tag := range aliases {
+		m[alias] = m[strings.ToLower(tag)]
+	}
+	return m
+}()
+
+// Supported returns every registered language.
+func Supported() []Language {
+	return append([]Language(nil), languages...)
+}
+
+// Lookup resolves a BCP-47 tag. Tags are matched case-insensitively and,
+// as in RFC 4647 lookup, subtags are dropped from the end until a
+// registered language is found, so "es-AR" resolves to "es". An empty tag
+// is the Default language.
+func Lookup(tag string) (Language, error) {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	if tag == "" {
+		return Default, nil
+	}
+	if !wellFormed(tag) {
+		return Language{}, fmt.Errorf("%w: %q is not a valid language tag", ErrUnsupported, tag)
+	}
+
+	subtags := strings.Split(strings.ToLower(tag), "-")
+	for n := len(subtags); n > 0; n-- {
+		if l, ok := registry[strings.Join(subtags[:n], "-")]; ok {
+			return l, nil
+		}
+	}
+	return Language{}, fmt.Errorf("%w: %q", ErrUnsupported, tag)
+}
+
+// Resolve is Lookup for values that were already validated; unknown tags
+// fall back to Default.
+func Resolve(tag string) Language {
+	l, err := Lookup(tag)
+	if err != nil {
+		return Default
+	}
+	return l
+}
+
+// Tags lists the canonical tags of the supported languages.
+func Tags() []string {
+	tags := make([]string, len(languages))
+	for i, l := range languages {
+		tags[i] = l.Tag
+	}
+	return tags
+}
+
+// wellFormed checks the basic BCP-47 shape: a 2-3 letter primary subtag
+// followed by alphanumeric subtags of 1 to 8 characters.
+func wellFormed(tag string) bool {
+	for i, sub := range strings.Split(tag, "-") {
+		if len(sub) == 0 || len(sub) > 8 {
+			return false
+		}
+		for _, r := range sub {
+			isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+			if !isLetter && (i == 0 || r < '0' || r > '9') {
+				return false
+			}
+		}
+		if i == 0 && (len(sub) < 2 || len(sub) > 3) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package locale
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"", "en"},
+		{"en", "en"},
+		{"EN-us", "en-US"},
+		{"en_GB", "en-GB"},
+		{"pt", "pt-BR"},
+		{"pt-PT", "pt-PT"},
+		{"es-AR", "es"},
+		{"fr-CA-u-ca-gregory", "fr-CA"},
+	}
+	for _, tt := range tests {
+		l, err := Lookup(tt.tag)
+		if err != nil {
+			t.Errorf("Lookup(%q): %v", tt.tag, err)
+			continue
+		}
+		if l.Tag != tt.want {
+			t.Errorf("Lookup(%q) = %s, want %s", tt.tag, l.Tag, tt.want)
+		}
+	}
+}
+
+func TestLookupRejectsUnsupported(t *testing.T) {
+	for _, tag := range []string{"de", "ja-JP", "english", "e", "pt--BR", "zz-1"} {
+		if _, err := Lookup(tag); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Lookup(%q) err = %v, want ErrUnsupported", tag, err)
+		}
+	}
+}
+
+func TestUnits(t *testing.T) {
+	if Resolve("en-US").Units != Imperial || Resolve("en").Units != Metric {
+		t.Error("en-US should be imperial and en metric")
+	}
+	if Resolve("de").Tag != Default.Tag {
+		t.Error("Resolve should fall back to the default language")
+	}
+}
@@ -0,0 +1,237 @@
+package nutrition
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quantity"
+)
+
+//go:embed foods.csv
+var foodsCSV string
+
+// Nutrients are amounts of energy and macronutrients, either per 100 g of a
+// food or per serving of a recipe.
+type Nutrients struct {
+	Calories float64 `json:"calories"`
+	Protein  float64 `json:"protein_g"`
+	Fat      float64 `json:"fat_g"`
+	Carbs    float64 `json:"carbs_g"`
+	Fiber    float64 `json:"fiber_g"`
+	Sodium   float64 `json:"sodium_mg"`
+}
+
+func (n Nutrients) add(o Nutrients, grams float64) Nutrients {
+	f := grams / 100
+	return Nutrients{
+		Calories: n.Calories + o.Calories*f,
+		Protein:  n.Protein + o.Protein*f,
+		Fat:      n.Fat + o.Fat*f,
+		Carbs:    n.Carbs + o.Carbs*f,
+		Fiber:    n.Fiber + o.Fiber*f,
+		Sodium:   n.Sodium + o.Sodium*f,
+	}
+}
+
+func (n Nutrients) rounded() Nutrients {
+	return Nutrients{
+		Calories: math.Round(n.Calories),
+		Protein:  round1(n.Protein),
+		Fat:      round1(n.Fat),
+		Carbs:    round1(n.Carbs),
+		Fiber:    round1(n.Fiber),
+		Sodium:   math.Round(n.Sodium),
+	}
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// Food is a row of the nutrient table.
+type Food struct {
+	Name    string
+	Per100g Nutrients
+	// Density in g/ml converts volumes; PieceGrams is the weight of one
+	// unit ("2 eggs"). Zero means unknown.
+	Density    float64
+	PieceGrams float64
+}
+
+// Confidence tells how much of a recipe the computed nutrition covers.
+type Confidence string
+
+const (
+	// ConfidenceHigh: every quantified ingredient was matched.
+	ConfidenceHigh Confidence = "high"
+	// ConfidenceMedium: most were; the numbers are an underestimate.
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceLow    Confidence = "low"
+)
+
+// mediumCoverage is the share of quantified ingredients that must be
+// matched for medium confidence.
+const mediumCoverage = 0.75
+
+// Facts is the nutrition computed for a recipe. Ingredients without a
+// quantity ("salt to taste") are left out of the totals.
+type Facts struct {
+	PerServing Nutrients  `json:"per_serving"`
+	Servings   int        `json:"servings"`
+	Confidence Confidence `json:"confidence"`
+	// Unmatched lists the ingredients that are missing from the table or
+	// whose unit couldn't be converted to grams.
+	Unmatched []string `json:"unmatched,omitempty"`
+}
+
+// Line is an ingredient line to compute nutrition for.
+type Line struct {
+	Name     string
+	Quantity float64
+	Unit     string
+}
+
+// Table is a nutrient database indexed by normalized food names and
+// aliases.
+type Table struct {
+	foods   []Food
+	index   map[string]int
+	longest int
+}
+
+// Default returns the table embedded in the binary.
+var Default = sync.OnceValue(func() *Table {
+	t, err := Load(strings.NewReader(foodsCSV))
+	if err != nil {
+		panic(err)
+	}
+	return t
+})
+
+// Load reads a nutrient table in the format of foods.csv: a header, then
+// name, aliases separated by "|", kcal, protein, fat, carbs and fiber in
+// grams and sodium in milligrams per 100 g, density in g/ml and the weight
+// of one piece in grams.
+func Load(r io.Reader) (*Table, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read nutrient table: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("nutrient table is empty")
+	}
+
+	t := &Table{index: make(map[string]int)}
+	for i, rec := range records[1:] {
+		line := i + 2
+		if len(rec) != 10 {
+			return nil, fmt.Errorf("nutrient table line %d: want 10 fields, got %d", line, len(rec))
+		}
+		values := make([]float64, 8)
+		for j, field := range rec[2:] {
+			if field == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("nutrient table line %d: invalid number %q", line, field)
+			}
+			values[j] = v
+		}
+		food := Food{
+			Name:       rec[0],
+			Per100g:    Nutrients{values[0], values[1], values[2], values[3], values[4], values[5]},
+			Density:    values[6],
+			PieceGrams: values[7],
+		}
+
+		names := []string{rec[0]}
+		if rec[1] != "" {
+			names = append(names, strings.Split(rec[1], "|")...)
+		}
+		for _, name := range names {
+			key := dietary.Normalize(name)
+			if prev, ok := t.index[key]; ok && prev != len(t.foods) {
+				return nil, fmt.Errorf("nutrient table line %d: %q already names %s", line, name, t.foods[prev].Name)
+			}
+			t.index[key] = len(t.foods)
+			t.longest = max(t.longest, len(strings.Fields(key)))
+		}
+		t.foods = append(t.foods, food)
+	}
+	return t, nil
+}
+
+// Lookup finds the food an ingredient name refers to. Descriptions around
+// the food are ignored by trying the longest run of words that names one,
+// so "boneless chicken breast, diced" is a chicken breast.
+func (t *Table) Lookup(name string) (Food, bool) {
+	if i := strings.Index(name, "("); i >= 0 {
+		name = name[:i]
+	}
+	words := strings.Fields(dietary.Normalize(name))
+	for n := min(len(words), t.longest); n > 0; n-- {
+		for start := 0; start+n <= len(words); start++ {
+			if i, ok := t.index[strings.Join(words[start:start+n], " ")]; ok {
+				return t.foods[i], true
+			}
+		}
+	}
+	return Food{}, false
+}
+
+// Calculate computes the nutrition per serving of a recipe. It returns nil
+// when no line has a quantity, since there is nothing to compute from.
+func (t *Table) Calculate(lines []Line, servings int) *Facts {
+	servings = max(servings, 1)
+
+	var total Nutrients
+	var quantified, matched int
+	var unmatched []string
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			continue
+		}
+		quantified++
+		food, ok := t.Lookup(line.Name)
+		if !ok {
+			unmatched = append(unmatched, line.Name)
+			continue
+		}
+		grams, ok := quantity.Quantity{Amount: line.Quantity, Unit: line.Unit}.Grams(food.Density, food.PieceGrams)
+		if !ok {
+			unmatched = append(unmatched, line.Name)
+			continue
+		}
+		matched++
+		total = total.add(food.Per100g, grams)
+	}
+	if quantified == 0 {
+		return nil
+	}
+
+	confidence := ConfidenceLow
+	switch coverage := float64(matched) / float64(quantified); {
+	case coverage == 1:
+		confidence = ConfidenceHigh
+	case coverage >= mediumCoverage:
+		confidence = ConfidenceMedium
+	}
+
+	// add scales per 100 g, so this divides the total by servings.
+	perServing := Nutrients{}.add(total, 100/float64(servings))
+
+	return &Facts{
+		PerServing: perServing.rounded(),
+		Servings:   servings,
+		Confidence: confidence,
+		Unmatched:  unmatched,
+	}
+}
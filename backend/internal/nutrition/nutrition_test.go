@@ -0,0 +1,66 @@
+package nutrition
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := map[string]string{
+		"boneless chicken breast, diced": "chicken breast",
+		"Chicken thighs":                 "chicken",
+		"2 ripe tomatoes (peeled)":       "tomato",
+		"azeite de oliva extra virgem":   "olive oil",
+		"peanut butter":                  "peanut butter",
+		"red peppers":                    "bell pepper",
+		"cebolla":                        "onion",
+	}
+	for name, want := range tests {
+		food, ok := Default().Lookup(name)
+		if !ok || food.Name != want {
+			t.Errorf("Lookup(%q) = %q, %v; want %q", name, food.Name, ok, want)
+		}
+	}
+	if food, ok := Default().Lookup("dragon fruit"); ok {
+		t.Errorf("Lookup(dragon fruit) = %q", food.Name)
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	facts := Default().Calculate([]Line{
+		{Name: "chicken breast", Quantity: 400, Unit: "g"},
+		{Name: "olive oil", Quantity: 2, Unit: "tbsp"},
+		{Name: "eggs", Quantity: 2},
+		{Name: "salt to taste"},
+	}, 2)
+
+	// 400 g chicken = 480 kcal, 26.9 g oil = 238 kcal, 100 g egg = 143 kcal.
+	if facts.PerServing.Calories != 430 || facts.PerServing.Protein != 51.3 {
+		t.Errorf("per serving = %+v", facts.PerServing)
+	}
+	if facts.Confidence != ConfidenceHigh || len(facts.Unmatched) != 0 || facts.Servings != 2 {
+		t.Errorf("facts = %+v", facts)
+	}
+
+	facts = Default().Calculate([]Line{
+		{Name: "rice", Quantity: 1, Unit: "cup"},
+		{Name: "dragon fruit", Quantity: 1},
+		{Name: "rice", Quantity: 1, Unit: "handful"},
+	}, 0)
+	if facts.Confidence != ConfidenceLow || strings.Join(facts.Unmatched, ",") != "dragon fruit,rice" || facts.Servings != 1 {
+		t.Errorf("facts = %+v", facts)
+	}
+
+	if facts := Default().Calculate([]Line{{Name: "salt to taste"}}, 2); facts != nil {
+		t.Errorf("facts without quantities = %+v", facts)
+	}
+}
+
+func TestLoadRejectsDuplicateNames(t *testing.T) {
+	csv := "name,aliases,kcal,protein_g,fat_g,carbs_g,fiber_g,sodium_mg,density_g_ml,piece_g\n" +
+		"tomato,,18,0.9,0.2,3.9,1.2,5,,\n" +
+		"cherry tomato,tomatoes,18,0.9,0.2,3.9,1.2,5,,\n"
+	if _, err := Load(strings.NewReader(csv)); err == nil {
+		t.Error("duplicate alias accepted")
+	}
+}
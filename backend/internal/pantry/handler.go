@@ -2,6 +2,7 @@ package pantry
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/Igorlimaponce/fridgeChef/backend/middleware"
@@ -33,6 +34,10 @@ func (h *PantryHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	item, err := h.service.Create(r.Context(), userID, req)
 	if err != nil {
+		if errors.Is(err, ErrInvalidQuantity) {
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -1,8 +1,10 @@
 package pantry
 
 import (
+	"strings"
 	"time"
 
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quantity"
 	"github.com/google/uuid"
 )
 
@@ -15,6 +17,17 @@ type PantryItem struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Amount returns how much of the item the pantry holds. ok is false when
+// no quantity was entered, or for items saved before quantities were
+// validated that can't be read.
+func (i *PantryItem) Amount() (q quantity.Quantity, ok bool) {
+	if strings.TrimSpace(i.Quantity) == "" {
+		return quantity.Quantity{}, false
+	}
+	q, err := quantity.Parse(i.Quantity + " " + i.Unit)
+	return q, err == nil
+}
+
 type CreatePantryItemRequest struct {
 	Name     string `json:"name"`
 	Quantity string `json:"quantity"`
@@ -2,10 +2,16 @@ package pantry
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quantity"
 	"github.com/google/uuid"
 )
 
+var ErrInvalidQuantity = errors.New("invalid quantity")
+
 type PantryService struct {
 	repo *PantryRepository
 }
@@ -15,15 +21,36 @@ func NewPantryService(repo *PantryRepository) *PantryService {
 }
 
 func (s *PantryService) Create(ctx context.Context, userID uuid.UUID, req CreatePantryItemRequest) (*PantryItem, error) {
+	amount, unit, err := normalizeQuantity(req.Quantity, req.Unit)
+	if err != nil {
+		return nil, err
+	}
 	item := &PantryItem{
 		UserID:   userID,
 		Name:     req.Name,
-		Quantity: req.Quantity,
-		Unit:     req.Unit,
+		Quantity: amount,
+		Unit:     unit,
 	}
 	return s.repo.Create(ctx, item)
 }
 
+// normalizeQuantity validates an amount and unit as entered ("200g" with
+// no unit, or "1 1/2" and "cups") and returns them in canonical form.
+func normalizeQuantity(amount, unit string) (string, string, error) {
+	amount, unit = strings.TrimSpace(amount), strings.TrimSpace(unit)
+	if amount == "" {
+		if _, ok := quantity.LookupUnit(unit); !ok {
+			return "", "", fmt.Errorf("%w: unknown unit %q", ErrInvalidQuantity, unit)
+		}
+		return "", quantity.NormalizeUnit(unit), nil
+	}
+	q, err := quantity.Parse(amount + " " + unit)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidQuantity, err)
+	}
+	return quantity.FormatAmount(q.Amount), q.Unit, nil
+}
+
 func (s *PantryService) List(ctx context.Context, userID uuid.UUID) ([]*PantryItem, error) {
 	return s.repo.List(ctx, userID)
 }
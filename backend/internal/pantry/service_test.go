@@ -0,0 +1,32 @@
+package pantry
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeQuantity(t *testing.T) {
+	tests := []struct {
+		amount, unit         string
+		wantAmount, wantUnit string
+	}{
+		{"200g", "", "200", "g"},
+		{"1 1/2", "cups", "1 1/2", "cup"},
+		{"0,5", "Kg", "1/2", "kg"},
+		{"3", "unit", "3", ""},
+		{"", "unit", "", ""},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		amount, unit, err := normalizeQuantity(tt.amount, tt.unit)
+		if err != nil || amount != tt.wantAmount || unit != tt.wantUnit {
+			t.Errorf("normalizeQuantity(%q, %q) = %q, %q, %v", tt.amount, tt.unit, amount, unit, err)
+		}
+	}
+
+	for _, bad := range [][2]string{{"some", ""}, {"2", "handfuls"}, {"", "handfuls"}} {
+		if _, _, err := normalizeQuantity(bad[0], bad[1]); !errors.Is(err, ErrInvalidQuantity) {
+			t.Errorf("normalizeQuantity(%q, %q) error = %v", bad[0], bad[1], err)
+		}
+	}
+}
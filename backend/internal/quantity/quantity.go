@@ -0,0 +1,214 @@
+package quantity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount = errors.New("invalid amount")
+	ErrUnknownUnit   = errors.New("unknown unit")
+	// ErrIncompatible is returned when converting between units that measure
+	// different things, such as cups to grams without a density.
+	ErrIncompatible = errors.New("incompatible units")
+)
+
+// Quantity is an amount in a canonical unit.
+type Quantity struct {
+	Amount float64 `json:"amount"`
+	Unit   string  `json:"unit,omitempty"`
+}
+
+// amountPrefix matches a leading amount: "2", "1.5", "0,5", "1/2",
+// "1 1/2", "½" or "1½", optionally followed by the upper end of a range
+// ("2-3"), which is dropped.
+var amountPrefix = regexp.MustCompile(`^(\d+(?:[.,]\d+)?\s+\d+/\d+|\d+\s*[¼½¾⅓⅔⅛]|\d+/\d+|\d+(?:[.,]\d+)?|[¼½¾⅓⅔⅛])(?:\s*(?:-|–|to)\s*\d+(?:[.,]\d+)?)?`)
+
+var vulgarFractions = map[rune]float64{'¼': 0.25, '½': 0.5, '¾': 0.75, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '⅛': 0.125}
+
+// amountWords stand for an amount, as in "a pinch of salt".
+var amountWords = map[string]float64{
+	"a": 1, "an": 1, "one": 1, "um": 1, "uma": 1, "un": 1, "una": 1, "une": 1,
+	"half": 0.5, "meio": 0.5, "meia": 0.5, "medio": 0.5, "media": 0.5, "demi": 0.5, "demie": 0.5,
+}
+
+// Parse reads a whole quantity such as "200g", "2 cups", "1 1/2 tbsp",
+// "3" or "a pinch".
+func Parse(s string) (Quantity, error) {
+	amount, rest, ok := splitAmount(strings.TrimSpace(s))
+	if !ok {
+		return Quantity{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	u, ok := LookupUnit(rest)
+	if !ok {
+		return Quantity{}, fmt.Errorf("%w: %q", ErrUnknownUnit, rest)
+	}
+	return Quantity{Amount: amount, Unit: u.Symbol}, nil
+}
+
+// Split separates the leading quantity of an ingredient line from the rest,
+// so "1 1/2 cups flour" gives 1.5 cups and "flour". The unit is returned as
+// written; only lines that keep a name after it get one. ok is false when
+// the line doesn't start with an amount.
+func Split(line string) (amount float64, unit, rest string, ok bool) {
+	amount, rest, ok = splitAmount(strings.TrimSpace(line))
+	if !ok {
+		return 0, "", line, false
+	}
+	words := strings.Fields(rest)
+	for n := min(longestUnit, len(words)-1); n > 0; n-- {
+		spelling := strings.Join(words[:n], " ")
+		if _, known := LookupUnit(spelling); known {
+			return amount, spelling, strings.Join(words[n:], " "), true
+		}
+	}
+	// A spelled-out amount needs a unit: "a pinch of salt", but not "an
+	// onion" or "un poco de sal".
+	if !amountPrefix.MatchString(strings.TrimSpace(line)) {
+		return 0, "", line, false
+	}
+	return amount, "", rest, true
+}
+
+func splitAmount(s string) (float64, string, bool) {
+	if m := amountPrefix.FindStringSubmatch(s); m != nil {
+		amount, ok := ParseAmount(m[1])
+		return amount, strings.TrimSpace(s[len(m[0]):]), ok
+	}
+	word, rest, _ := strings.Cut(s, " ")
+	if amount, ok := amountWords[strings.ToLower(word)]; ok && rest != "" {
+		return amount, strings.TrimSpace(rest), true
+	}
+	return 0, s, false
+}
+
+// ParseAmount reads a number written as "2", "1.5", "0,5", "3/4", "1 1/2"
+// or with a fraction character ("1½").
+func ParseAmount(s string) (float64, bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	if s == "" {
+		return 0, false
+	}
+	var total float64
+	for _, part := range strings.Fields(s) {
+		for r, v := range vulgarFractions {
+			if whole, ok := strings.CutSuffix(part, string(r)); ok {
+				part = whole
+				total += v
+				break
+			}
+		}
+		if part == "" {
+			continue
+		}
+		if num, den, ok := strings.Cut(part, "/"); ok {
+			n, err1 := strconv.ParseFloat(num, 64)
+			d, err2 := strconv.ParseFloat(den, 64)
+			if err1 != nil || err2 != nil || d == 0 {
+				return 0, false
+			}
+			total += n / d
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, false
+		}
+		total += v
+	}
+	return total, total >= 0 && !math.IsInf(total, 0)
+}
+
+// Scale multiplies the amount by factor.
+func (q Quantity) Scale(factor float64) Quantity {
+	return Quantity{Amount: q.Amount * factor, Unit: q.Unit}
+}
+
+// Convert expresses q in another unit. Mass and volume convert into each
+// other through density, in g/ml; a zero density makes them incompatible.
+func (q Quantity) Convert(to string, density float64) (Quantity, error) {
+	from, ok := LookupUnit(q.Unit)
+	if !ok {
+		return Quantity{}, fmt.Errorf("%w: %q", ErrUnknownUnit, q.Unit)
+	}
+	target, ok := LookupUnit(to)
+	if !ok {
+		return Quantity{}, fmt.Errorf("%w: %q", ErrUnknownUnit, to)
+	}
+
+	base := q.Amount * from.Factor
+	switch {
+	case from.Dimension == target.Dimension && (from.Dimension != Count || from.Symbol == target.Symbol):
+	case from.Dimension == Volume && target.Dimension == Mass && density > 0:
+		base *= density
+	case from.Dimension == Mass && target.Dimension == Volume && density > 0:
+		base /= density
+	default:
+		return Quantity{}, fmt.Errorf("%w: %s to %s", ErrIncompatible, displayUnit(from.Symbol), displayUnit(target.Symbol))
+	}
+	return Quantity{Amount: base / target.Factor, Unit: target.Symbol}, nil
+}
+
+// Add returns q plus o, in q's unit.
+func (q Quantity) Add(o Quantity, density float64) (Quantity, error) {
+	converted, err := o.Convert(q.Unit, density)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Amount: q.Amount + converted.Amount, Unit: converted.Unit}, nil
+}
+
+// Grams weighs q. Volumes need the ingredient's density and counts the
+// weight of one piece; zero means unknown.
+func (q Quantity) Grams(density, pieceGrams float64) (float64, bool) {
+	u, ok := LookupUnit(q.Unit)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case u.Dimension == Mass:
+		return q.Amount * u.Factor, true
+	case u.Dimension == Volume && density > 0:
+		return q.Amount * u.Factor * density, true
+	case u.Dimension == Count && pieceGrams > 0:
+		return q.Amount * pieceGrams, true
+	}
+	return 0, false
+}
+
+func (q Quantity) String() string {
+	if q.Unit == "" {
+		return FormatAmount(q.Amount)
+	}
+	return FormatAmount(q.Amount) + " " + q.Unit
+}
+
+// FormatAmount writes an amount the way a recipe would: whole numbers
+// plainly, common fractions as such ("1 1/2") and anything else with up to
+// two decimals.
+func FormatAmount(amount float64) string {
+	whole, frac := math.Modf(amount)
+	for _, f := range []struct {
+		value float64
+		text  string
+	}{{0.25, "1/4"}, {1.0 / 3, "1/3"}, {0.5, "1/2"}, {2.0 / 3, "2/3"}, {0.75, "3/4"}} {
+		if math.Abs(frac-f.value) < 0.01 {
+			if whole == 0 {
+				return f.text
+			}
+			return strconv.FormatFloat(whole, 'f', 0, 64) + " " + f.text
+		}
+	}
+	return strconv.FormatFloat(math.Round(amount*100)/100, 'f', -1, 64)
+}
+
+func displayUnit(symbol string) string {
+	if symbol == "" {
+		return "pieces"
+	}
+	return symbol
+}
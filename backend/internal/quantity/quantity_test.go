@@ -0,0 +1,129 @@
+package quantity
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quantity
+	}{
+		{"200g", Quantity{200, "g"}},
+		{"2 cups", Quantity{2, "cup"}},
+		{"1 1/2", Quantity{1.5, ""}},
+		{"1½ Tbsp.", Quantity{1.5, "tbsp"}},
+		{"0,5 kg", Quantity{0.5, "kg"}},
+		{"a pinch", Quantity{1, "pinch"}},
+		{"3/4 colher de sopa", Quantity{0.75, "tbsp"}},
+		{"2-3 cloves", Quantity{2, "clove"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for in, want := range map[string]error{"some": ErrInvalidAmount, "": ErrInvalidAmount, "2 handfuls": ErrUnknownUnit, "1/0 g": ErrInvalidAmount} {
+		if _, err := Parse(in); !errors.Is(err, want) {
+			t.Errorf("Parse(%q) error = %v; want %v", in, err, want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line, unit, rest string
+		amount           float64
+		ok               bool
+	}{
+		{"1 1/2 cups flour", "cups", "flour", 1.5, true},
+		{"200g chicken breast", "g", "chicken breast", 200, true},
+		{"2 colheres de sopa de azeite", "colheres de sopa", "de azeite", 2, true},
+		{"a pinch of salt", "pinch", "of salt", 1, true},
+		{"2 tomatoes", "", "tomatoes", 2, true},
+		{"an onion", "", "an onion", 0, false},
+		{"salt to taste", "", "salt to taste", 0, false},
+	}
+	for _, tt := range tests {
+		amount, unit, rest, ok := Split(tt.line)
+		if amount != tt.amount || unit != tt.unit || rest != tt.rest || ok != tt.ok {
+			t.Errorf("Split(%q) = %v, %q, %q, %v", tt.line, amount, unit, rest, ok)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		q       Quantity
+		to      string
+		density float64
+		want    float64
+	}{
+		{Quantity{16, "tbsp"}, "cup", 0, 1},
+		{Quantity{1, "lb"}, "g", 0, 453.592},
+		{Quantity{1, "cup"}, "g", 0.53, 125.39},
+		{Quantity{100, "g"}, "ml", 0.5, 200},
+	}
+	for _, tt := range tests {
+		got, err := tt.q.Convert(tt.to, tt.density)
+		if err != nil || math.Abs(got.Amount-tt.want) > 0.01 {
+			t.Errorf("%v in %s = %v, %v; want %v", tt.q, tt.to, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []struct {
+		q  Quantity
+		to string
+	}{{Quantity{1, "cup"}, "g"}, {Quantity{2, "clove"}, "slice"}, {Quantity{1, "g"}, "handful"}} {
+		if _, err := bad.q.Convert(bad.to, 0); err == nil {
+			t.Errorf("%v in %s converted", bad.q, bad.to)
+		}
+	}
+}
+
+func TestAddAndScale(t *testing.T) {
+	sum, err := Quantity{1, "kg"}.Add(Quantity{250, "g"}, 0)
+	if err != nil || sum != (Quantity{1.25, "kg"}) {
+		t.Errorf("1 kg + 250 g = %v, %v", sum, err)
+	}
+	if got := (Quantity{1.5, "cup"}).Scale(2); got != (Quantity{3, "cup"}) {
+		t.Errorf("Scale = %v", got)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	for amount, want := range map[float64]string{2: "2", 1.5: "1 1/2", 0.25: "1/4", 1.0 / 3: "1/3", 0.1: "0.1", 2.456: "2.46"} {
+		if got := FormatAmount(amount); got != want {
+			t.Errorf("FormatAmount(%v) = %q; want %q", amount, got, want)
+		}
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		in, want Quantity
+	}{
+		{Quantity{16, "tbsp"}, Quantity{1, "cup"}},
+		{Quantity{3, "tsp"}, Quantity{1, "tbsp"}},
+		{Quantity{6, "tbsp"}, Quantity{6, "tbsp"}},
+		{Quantity{12, "tbsp"}, Quantity{0.75, "cup"}},
+		{Quantity{0.125, "cup"}, Quantity{2, "tbsp"}},
+		{Quantity{1500, "g"}, Quantity{1.5, "kg"}},
+		{Quantity{0.25, "kg"}, Quantity{250, "g"}},
+		{Quantity{333.33, "g"}, Quantity{335, "g"}},
+		{Quantity{24, "oz"}, Quantity{1.5, "lb"}},
+		{Quantity{1.8, "pinch"}, Quantity{1.75, "pinch"}},
+		{Quantity{1.7, "pinch"}, Quantity{5.0 / 3, "pinch"}},
+		{Quantity{2.6, "clove"}, Quantity{2.5, "clove"}},
+	}
+	for _, tt := range tests {
+		got := Simplify(tt.in)
+		if got.Unit != tt.want.Unit || math.Abs(got.Amount-tt.want.Amount) > 1e-9 {
+			t.Errorf("Simplify(%v) = %v; want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package quantity
+
+import "math"
+
+// ladders are units a quantity moves between as it grows or shrinks,
+// smallest first, each with the least amount worth writing in it.
+var ladders = [][]struct {
+	symbol string
+	min    float64
+}{
+	{{"tsp", 0}, {"tbsp", 1}, {"cup", 0.25}},
+	{{"g", 0}, {"kg", 1}},
+	{{"ml", 0}, {"l", 1}},
+	{{"oz", 0}, {"lb", 1}},
+}
+
+// Simplify rounds q to an amount a cook can measure, moving it to a larger
+// or smaller unit of the same system when that reads better: 16 tbsp is
+// 1 cup and 0.25 kg is 250 g.
+func Simplify(q Quantity) Quantity {
+	q.Unit = NormalizeUnit(q.Unit)
+	for _, ladder := range ladders {
+		in := false
+		for _, rung := range ladder {
+			in = in || rung.symbol == q.Unit
+		}
+		if !in {
+			continue
+		}
+		for i := len(ladder) - 1; i >= 0; i-- {
+			converted, err := q.Convert(ladder[i].symbol, 0)
+			// The slack in the minimum absorbs conversion factors that
+			// don't divide evenly, so 3 tsp still makes a tablespoon.
+			if err != nil || i > 0 && !(converted.Amount >= ladder[i].min-0.01 && measurable(converted)) {
+				continue
+			}
+			q = converted
+			break
+		}
+		break
+	}
+	q.Amount = Round(q.Amount, q.Unit)
+	return q
+}
+
+// measurable reports whether q survives rounding in its unit with little
+// loss, so 6 tbsp stays put instead of becoming 1/3 cup.
+func measurable(q Quantity) bool {
+	return math.Abs(Round(q.Amount, q.Unit)-q.Amount) <= 0.05*q.Amount
+}
+
+// Round rounds an amount to the precision its unit is measured with:
+// metric units to a sensible step for their size, counts to halves and
+// kitchen measures to quarters or thirds.
+func Round(amount float64, unit string) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	u, known := LookupUnit(unit)
+	switch {
+	case known && u.Dimension == Count:
+		return math.Max(0.5, roundTo(amount, 0.5))
+	case u.Symbol == "g" || u.Symbol == "ml" || u.Symbol == "mg":
+		switch {
+		case amount < 10:
+			return math.Max(0.5, roundTo(amount, 0.5))
+		case amount < 100:
+			return roundTo(amount, 1)
+		case amount < 1000:
+			return roundTo(amount, 5)
+		}
+		return roundTo(amount, 10)
+	case u.Symbol == "kg" || u.Symbol == "l":
+		return math.Max(0.05, roundTo(amount, 0.05))
+	}
+
+	quarters, thirds := roundTo(amount, 0.25), roundTo(amount, 1.0/3)
+	best := quarters
+	if math.Abs(thirds-amount) < math.Abs(quarters-amount) {
+		best = thirds
+	}
+	if best == 0 {
+		return math.Round(amount*100) / 100
+	}
+	return best
+}
+
+func roundTo(amount, step float64) float64 {
+	return math.Round(amount/step) * step
+}
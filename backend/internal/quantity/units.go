@@ -0,0 +1,133 @@
+package quantity
+
+import "strings"
+
+// Dimension is what a unit measures. Amounts convert freely within mass
+// and volume, between the two with a density, and count units only to
+// themselves.
+type Dimension int
+
+const (
+	Count Dimension = iota
+	Mass
+	Volume
+)
+
+// Unit is a canonical unit. Factor is its size in grams for mass, in
+// milliliters for volume and 1 for count units.
+type Unit struct {
+	Symbol    string
+	Dimension Dimension
+	Factor    float64
+}
+
+// units are the canonical units with the spellings they are written as in
+// the languages the app supports. The empty symbol is a plain count ("2
+// eggs").
+var units = []struct {
+	Unit
+	aliases []string
+}{
+	{Unit{"g", Mass, 1}, []string{"gr", "gram", "grams", "gramme", "grammes", "grama", "gramas", "gramo", "gramos"}},
+	{Unit{"kg", Mass, 1000}, []string{"kgs", "kilo", "kilos", "kilogram", "kilograms", "kilogramme", "kilogrammes", "kilograma", "kilogramas", "kilogramo", "kilogramos"}},
+	{Unit{"mg", Mass, 0.001}, []string{"milligram", "milligrams", "miligrama", "miligramas", "miligramo", "miligramos"}},
+	{Unit{"oz", Mass, 28.3495}, []string{"ounce", "ounces", "onça", "onças", "onza", "onzas", "once", "onces"}},
+	{Unit{"lb", Mass, 453.592}, []string{"lbs", "pound", "pounds", "libra", "libras", "livre", "livres"}},
+
+	{Unit{"ml", Volume, 1}, []string{"milliliter", "milliliters", "millilitre", "millilitres", "mililitro", "mililitros"}},
+	{Unit{"cl", Volume, 10}, []string{"centiliter", "centiliters", "centilitre", "centilitres"}},
+	{Unit{"dl", Volume, 100}, []string{"deciliter", "deciliters", "decilitre", "decilitres"}},
+	{Unit{"l", Volume, 1000}, []string{"lt", "liter", "liters", "litre", "litres", "litro", "litros"}},
+	{Unit{"tsp", Volume, 4.92892}, []string{"teaspoon", "teaspoons", "colher de chá", "colheres de chá", "colher de cha", "colheres de cha",
+		"cucharadita", "cucharaditas", "cuillère à café", "cuillères à café", "c. à café", "cuillere a cafe"}},
+	{Unit{"tbsp", Volume, 14.7868}, []string{"tbs", "tbl", "tablespoon", "tablespoons", "colher de sopa", "colheres de sopa",
+		"cucharada", "cucharadas", "cuillère à soupe", "cuillères à soupe", "c. à soupe", "cuillere a soupe"}},
+	{Unit{"fl oz", Volume, 29.5735}, []string{"fl. oz", "fluid ounce", "fluid ounces"}},
+	{Unit{"cup", Volume, 236.588}, []string{"cups", "xícara", "xícaras", "xicara", "xicaras", "taza", "tazas", "tasse", "tasses"}},
+	{Unit{"pint", Volume, 473.176}, []string{"pints", "pt"}},
+	{Unit{"quart", Volume, 946.353}, []string{"quarts", "qt"}},
+	{Unit{"gallon", Volume, 3785.41}, []string{"gallons", "gal"}},
+	{Unit{"pinch", Volume, 0.31}, []string{"pinches", "pitada", "pitadas", "pizca", "pizcas", "pincée", "pincées"}},
+	{Unit{"dash", Volume, 0.62}, []string{"dashes"}},
+
+	{Unit{"", Count, 1}, []string{"piece", "pieces", "pc", "pcs", "unit", "units", "whole", "small", "medium", "large",
+		"unidade", "unidades", "unidad", "pièce", "pièces"}},
+	{Unit{"clove", Count, 1}, []string{"cloves", "dente", "dentes", "diente", "dientes", "gousse", "gousses"}},
+	{Unit{"slice", Count, 1}, []string{"slices", "fatia", "fatias", "rebanada", "rebanadas", "tranche", "tranches"}},
+	{Unit{"can", Count, 1}, []string{"cans", "tin", "tins", "lata", "latas", "boîte", "boîtes"}},
+	{Unit{"bunch", Count, 1}, []string{"bunches", "maço", "maços", "manojo", "manojos", "botte", "bottes"}},
+	{Unit{"sprig", Count, 1}, []string{"sprigs", "ramo", "ramos", "ramita", "ramitas", "brin", "brins"}},
+}
+
+var (
+	bySpelling = map[string]Unit{}
+	// longestUnit is the most words a spelling has.
+	longestUnit int
+)
+
+func init() {
+	for _, u := range units {
+		for _, spelling := range append([]string{u.Symbol}, u.aliases...) {
+			bySpelling[spelling] = u.Unit
+			longestUnit = max(longestUnit, len(strings.Fields(spelling)))
+		}
+	}
+}
+
+// LookupUnit finds the canonical unit for a spelling such as "Cups",
+// "tbsp." or "colheres de sopa".
+func LookupUnit(spelling string) (Unit, bool) {
+	s := strings.ToLower(strings.Join(strings.Fields(spelling), " "))
+	if u, ok := bySpelling[s]; ok {
+		return u, true
+	}
+	u, ok := bySpelling[strings.TrimSuffix(s, ".")]
+	return u, ok
+}
+
+// spellings are how canonical units are written out per language, singular
+// then plural. Symbols missing here are written as is.
+var spellings = map[string]map[string][2]string{
+	"en": {
+		"cup": {"cup", "cups"}, "pint": {"pint", "pints"}, "quart": {"quart", "quarts"}, "gallon": {"gallon", "gallons"},
+		"pinch": {"pinch", "pinches"}, "dash": {"dash", "dashes"}, "clove": {"clove", "cloves"}, "slice": {"slice", "slices"},
+		"can": {"can", "cans"}, "bunch": {"bunch", "bunches"}, "sprig": {"sprig", "sprigs"},
+	},
+	"pt": {
+		"tsp": {"colher de chá", "colheres de chá"}, "tbsp": {"colher de sopa", "colheres de sopa"}, "cup": {"xícara", "xícaras"},
+		"pinch": {"pitada", "pitadas"}, "clove": {"dente", "dentes"}, "slice": {"fatia", "fatias"}, "can": {"lata", "latas"},
+		"bunch": {"maço", "maços"}, "sprig": {"ramo", "ramos"},
+	},
+	"es": {
+		"tsp": {"cucharadita", "cucharaditas"}, "tbsp": {"cucharada", "cucharadas"}, "cup": {"taza", "tazas"},
+		"pinch": {"pizca", "pizcas"}, "clove": {"diente", "dientes"}, "slice": {"rebanada", "rebanadas"}, "can": {"lata", "latas"},
+		"bunch": {"manojo", "manojos"}, "sprig": {"ramita", "ramitas"},
+	},
+	"fr": {
+		"tsp": {"cuillère à café", "cuillères à café"}, "tbsp": {"cuillère à soupe", "cuillères à soupe"}, "cup": {"tasse", "tasses"},
+		"pinch": {"pincée", "pincées"}, "clove": {"gousse", "gousses"}, "slice": {"tranche", "tranches"}, "can": {"boîte", "boîtes"},
+		"bunch": {"botte", "bottes"}, "sprig": {"brin", "brins"},
+	},
+}
+
+// Spell writes a canonical unit for an amount in a language ("en", "pt",
+// ...), so 2 cup becomes "cups" or "xícaras".
+func Spell(symbol, language string, amount float64) string {
+	s, ok := spellings[language][symbol]
+	if !ok {
+		return symbol
+	}
+	if amount > 1 {
+		return s[1]
+	}
+	return s[0]
+}
+
+// NormalizeUnit returns the canonical symbol for a unit spelling, or the
+// spelling unchanged when it isn't a known unit.
+func NormalizeUnit(spelling string) string {
+	if u, ok := LookupUnit(spelling); ok {
+		return u.Symbol
+	}
+	return spelling
+}
@@ -0,0 +1,148 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	appMiddleware "github.com/Igorlimaponce/fridgeChef/backend/middleware"
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+type usageKey struct{}
+
+// usage tracks the generations spent by the current request.
+type usage struct {
+	mu       sync.Mutex
+	spent    int
+	refunded int
+}
+
+// Track returns a context in which n generations are spent, and a func
+// reporting how many of them have been refunded with Refund since. Limit
+// tracks every request it lets through.
+func Track(ctx context.Context, n int) (context.Context, func() int) {
+	u := &usage{spent: n}
+	refunded := func() int {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		return u.refunded
+	}
+	return context.WithValue(ctx, usageKey{}, u), refunded
+}
+
+// Refund marks n of the generations spent by the request in ctx as unused,
+// e.g. because they were served from a cache. It is a no-op outside Track.
+func Refund(ctx context.Context, n int) {
+	u, ok := ctx.Value(usageKey{}).(*usage)
+	if !ok {
+		return
+	}
+	u.mu.Lock()
+	u.refunded = min(u.refunded+n, u.spent)
+	u.mu.Unlock()
+}
+
+// Refundable reports whether a generation that ended with status should be
+// given back: server errors and upstream rate limiting are not the user's
+// doing.
+func Refundable(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+}
+
+type QuotaHandler struct {
+	service *QuotaService
+}
+
+func NewQuotaHandler(service *QuotaService) *QuotaHandler {
+	return &QuotaHandler{service: service}
+}
+
+func (h *QuotaHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := appMiddleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	status, err := h.service.Status(r.Context(), userID)
+	if err != nil {
+		log.Printf("QuotaService.Status error: %v", err)
+		util.WriteError(w, http.StatusInternalServerError, "failed to get quota")
+		return
+	}
+
+	setHeaders(w, status)
+	util.WriteJSON(w, http.StatusOK, status)
+}
+
+// Limit returns a middleware that spends cost(r) generations (one when cost
+// is nil) before calling next and answers 429 when the user is over their
+// limits. Everything is refunded when next fails with a Refundable status,
+// so provider outages and rate limits don't eat the quota; handlers refund
+// partial work, or failures after the status is sent, with Refund.
+func (h *QuotaHandler) Limit(cost func(*http.Request) int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := appMiddleware.GetUserIDFromCtx(r.Context())
+			if userID == uuid.Nil {
+				util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+
+			n := 1
+			if cost != nil {
+				n = max(cost(r), 1)
+			}
+
+			status, err := h.service.Consume(r.Context(), userID, n)
+			if status != nil {
+				setHeaders(w, status)
+			}
+			if err != nil {
+				var limitErr *LimitError
+				if errors.As(err, &limitErr) {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds()))))
+					util.WriteError(w, http.StatusTooManyRequests, limitErr.Err.Error())
+					return
+				}
+				log.Printf("QuotaService.Consume error: %v", err)
+				util.WriteError(w, http.StatusInternalServerError, "failed to check quota")
+				return
+			}
+
+			ctx, refunded := Track(r.Context(), n)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			refund := refunded()
+			if Refundable(ww.Status()) {
+				refund = n
+			}
+			if refund == 0 {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 2*time.Second)
+			defer cancel()
+			if err := h.service.Refund(ctx, userID, refund); err != nil {
+				log.Printf("QuotaService.Refund error: %v", err)
+			}
+		})
+	}
+}
+
+func setHeaders(w http.ResponseWriter, status *Status) {
+	if status.Unlimited {
+		return
+	}
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(status.ResetAt.Unix(), 10))
+}
@@ -0,0 +1,21 @@
+package quota
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRefundable(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusUnprocessableEntity: false,
+		http.StatusTooManyRequests:     true,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusGatewayTimeout:      true,
+	} {
+		if got := Refundable(status); got != want {
+			t.Errorf("Refundable(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package quota
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxIdleBuckets bounds the limiter memory; once exceeded, buckets that
+// have refilled completely are dropped since they carry no state.
+const maxIdleBuckets = 10000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is an in-memory token bucket per user. It smooths bursts; the
+// durable daily quota lives in Postgres.
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[uuid.UUID]*bucket
+	now     func() time.Time
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{buckets: make(map[uuid.UUID]*bucket), now: time.Now}
+}
+
+// Take removes a token from the user's bucket. When the bucket is empty it
+// returns false and how long until the next token is available.
+func (l *Limiter) Take(userID uuid.UUID, limits Limits) (bool, time.Duration) {
+	if limits.RatePerMinute <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(userID, limits)
+	if b.tokens < 1 {
+		perToken := time.Duration(float64(time.Minute) / limits.RatePerMinute)
+		wait := time.Duration((1 - b.tokens) * float64(perToken))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Return puts back a token taken by Take.
+func (l *Limiter) Return(userID uuid.UUID, limits Limits) {
+	if limits.RatePerMinute <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(userID, limits)
+	b.tokens = math.Min(b.tokens+1, float64(burst(limits)))
+}
+
+func (l *Limiter) refill(userID uuid.UUID, limits Limits) *bucket {
+	now := l.now()
+	capacity := float64(burst(limits))
+
+	b, ok := l.buckets[userID]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.evictFull(limits)
+		}
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[userID] = b
+		return b
+	}
+
+	elapsed := now.Sub(b.last).Minutes()
+	b.tokens = math.Min(capacity, b.tokens+elapsed*limits.RatePerMinute)
+	b.last = now
+	return b
+}
+
+func (l *Limiter) evictFull(limits Limits) {
+	now := l.now()
+	for id, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Minutes()*limits.RatePerMinute >= float64(burst(limits)) {
+			delete(l.buckets, id)
+		}
+	}
+}
+
+func burst(limits Limits) int {
+	if limits.Burst < 1 {
+		return 1
+	}
+	return limits.Burst
+}
@@ -0,0 +1,72 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter()
+	l.now = func() time.Time { return now }
+
+	user := uuid.New()
+	limits := Limits{RatePerMinute: 2, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Take(user, limits); !ok {
+			t.Fatalf("take %d refused within burst", i)
+		}
+	}
+	ok, wait := l.Take(user, limits)
+	if ok {
+		t.Fatal("take allowed after burst was spent")
+	}
+	if wait != 30*time.Second {
+		t.Errorf("wait = %s, want 30s", wait)
+	}
+
+	now = now.Add(30 * time.Second)
+	if ok, _ := l.Take(user, limits); !ok {
+		t.Fatal("take refused after refill")
+	}
+
+	// Other users have their own bucket.
+	if ok, _ := l.Take(uuid.New(), limits); !ok {
+		t.Fatal("independent user was limited")
+	}
+}
+
+func TestLimiterReturn(t *testing.T) {
+	l := NewLimiter()
+	l.now = func() time.Time { return time.Unix(0, 0) }
+
+	user := uuid.New()
+	limits := Limits{RatePerMinute: 1, Burst: 1}
+
+	if ok, _ := l.Take(user, limits); !ok {
+		t.Fatal("first take refused")
+	}
+	l.Return(user, limits)
+	if ok, _ := l.Take(user, limits); !ok {
+		t.Fatal("take refused after token was returned")
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l := NewLimiter()
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Take(uuid.New(), Limits{}); !ok {
+			t.Fatal("limiter without a rate refused a call")
+		}
+	}
+}
+
+func TestConfigFallsBackToDefaultRole(t *testing.T) {
+	cfg := LoadConfig()
+	if got, want := cfg.For("unknown"), cfg.For("viewer"); got != want {
+		t.Errorf("For(unknown) = %+v, want viewer limits %+v", got, want)
+	}
+}
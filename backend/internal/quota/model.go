@@ -0,0 +1,89 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+)
+
+var (
+	ErrRateLimited   = errors.New("too many generation requests")
+	ErrQuotaExceeded = errors.New("daily generation quota exceeded")
+)
+
+// Limits are the generation limits applied to one role.
+type Limits struct {
+	// Daily is the number of generations allowed per UTC day; 0 means unlimited.
+	Daily int
+	// RatePerMinute is the token bucket refill rate; 0 disables the bucket.
+	RatePerMinute float64
+	// Burst is the bucket capacity.
+	Burst int
+}
+
+type Config struct {
+	Roles map[string]Limits
+}
+
+var defaultLimits = map[string]Limits{
+	util.RoleViewer: {Daily: 20, RatePerMinute: 4, Burst: 3},
+	util.RoleAdmin:  {Daily: 0, RatePerMinute: 30, Burst: 10},
+}
+
+// LoadConfig reads QUOTA_<ROLE>_DAILY, QUOTA_<ROLE>_RATE_PER_MINUTE and
+// QUOTA_<ROLE>_BURST for every known role.
+func LoadConfig() Config {
+	cfg := Config{Roles: make(map[string]Limits, len(defaultLimits))}
+	for role, limits := range defaultLimits {
+		prefix := "QUOTA_" + strings.ToUpper(role) + "_"
+		if n, err := strconv.Atoi(util.GetEnv(prefix+"DAILY", "")); err == nil && n >= 0 {
+			limits.Daily = n
+		}
+		if f, err := strconv.ParseFloat(util.GetEnv(prefix+"RATE_PER_MINUTE", ""), 64); err == nil && f >= 0 {
+			limits.RatePerMinute = f
+		}
+		if n, err := strconv.Atoi(util.GetEnv(prefix+"BURST", "")); err == nil && n > 0 {
+			limits.Burst = n
+		}
+		cfg.Roles[role] = limits
+	}
+	return cfg
+}
+
+// For returns the limits of role, falling back to the default role.
+func (c Config) For(role string) Limits {
+	if limits, ok := c.Roles[role]; ok {
+		return limits
+	}
+	return c.Roles[util.DefaultRole]
+}
+
+// Status is the quota of a user for the current day.
+type Status struct {
+	Role          string    `json:"role"`
+	Unlimited     bool      `json:"unlimited"`
+	Limit         int       `json:"daily_limit"`
+	Used          int       `json:"used"`
+	Remaining     int       `json:"remaining"`
+	ResetAt       time.Time `json:"reset_at"`
+	RatePerMinute float64   `json:"rate_per_minute"`
+	Burst         int       `json:"burst"`
+}
+
+// LimitError is returned when a generation is refused. It matches
+// ErrRateLimited or ErrQuotaExceeded via errors.Is.
+type LimitError struct {
+	Err        error
+	RetryAfter time.Duration
+	Status     *Status
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v (retry in %s)", e.Err, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LimitError) Unwrap() error { return e.Err }
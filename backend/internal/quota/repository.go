@@ -0,0 +1,78 @@
+package quota
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type QuotaRepository struct {
+	db *sql.DB
+}
+
+func NewQuotaRepository(db *sql.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+func (r *QuotaRepository) Role(ctx context.Context, userID uuid.UUID) (string, error) {
+	var role string
+	err := r.db.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1`, userID).Scan(&role)
+	if err != nil {
+		return "", fmt.Errorf("get user role: %w", err)
+	}
+	return role, nil
+}
+
+// Consume spends n generations of the given day. It returns the updated
+// count and false, without spending anything, when that would go over limit.
+// A limit of 0 is unlimited.
+func (r *QuotaRepository) Consume(ctx context.Context, userID uuid.UUID, day time.Time, limit, n int) (int, bool, error) {
+	query := `
+		INSERT INTO generation_usage (user_id, day, count)
+		SELECT $1, $2, $4::int
+		WHERE $3::int = 0 OR $4::int <= $3::int
+		ON CONFLICT (user_id, day) DO UPDATE
+		SET count = generation_usage.count + EXCLUDED.count, updated_at = NOW()
+		WHERE $3::int = 0 OR generation_usage.count + EXCLUDED.count <= $3::int
+		RETURNING count
+	`
+	var count int
+	err := r.db.QueryRowContext(ctx, query, userID, day, limit, n).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		used, err := r.Usage(ctx, userID, day)
+		return used, false, err
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("consume generation quota: %w", err)
+	}
+	return count, true, nil
+}
+
+// Refund gives back n generations, e.g. when the provider failed.
+func (r *QuotaRepository) Refund(ctx context.Context, userID uuid.UUID, day time.Time, n int) error {
+	query := `
+		UPDATE generation_usage
+		SET count = GREATEST(count - $3::int, 0), updated_at = NOW()
+		WHERE user_id = $1 AND day = $2
+	`
+	if _, err := r.db.ExecContext(ctx, query, userID, day, n); err != nil {
+		return fmt.Errorf("refund generation quota: %w", err)
+	}
+	return nil
+}
+
+func (r *QuotaRepository) Usage(ctx context.Context, userID uuid.UUID, day time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT count FROM generation_usage WHERE user_id = $1 AND day = $2`, userID, day).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get generation usage: %w", err)
+	}
+	return count, nil
+}
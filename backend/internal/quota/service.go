@@ -0,0 +1,95 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type QuotaService struct {
+	repo    *QuotaRepository
+	limiter *Limiter
+	cfg     Config
+	now     func() time.Time
+}
+
+func NewQuotaService(repo *QuotaRepository, cfg Config) *QuotaService {
+	return &QuotaService{repo: repo, limiter: NewLimiter(), cfg: cfg, now: time.Now}
+}
+
+// Consume spends n generations for the user. It returns a *LimitError when
+// the user is going too fast or doesn't have n generations left today. The
+// token bucket counts requests, not generations.
+func (s *QuotaService) Consume(ctx context.Context, userID uuid.UUID, n int) (*Status, error) {
+	role, err := s.repo.Role(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	limits := s.cfg.For(role)
+	status := s.newStatus(role, limits)
+
+	if ok, wait := s.limiter.Take(userID, limits); !ok {
+		used, err := s.repo.Usage(ctx, userID, s.today())
+		if err != nil {
+			return nil, err
+		}
+		status.setUsed(used)
+		return status, &LimitError{Err: ErrRateLimited, RetryAfter: wait, Status: status}
+	}
+
+	used, ok, err := s.repo.Consume(ctx, userID, s.today(), limits.Daily, n)
+	if err != nil {
+		s.limiter.Return(userID, limits)
+		return nil, err
+	}
+	status.setUsed(used)
+	if !ok {
+		s.limiter.Return(userID, limits)
+		return status, &LimitError{Err: ErrQuotaExceeded, RetryAfter: status.ResetAt.Sub(s.now()), Status: status}
+	}
+
+	return status, nil
+}
+
+// Refund gives back n generations that didn't reach the provider.
+func (s *QuotaService) Refund(ctx context.Context, userID uuid.UUID, n int) error {
+	return s.repo.Refund(ctx, userID, s.today(), n)
+}
+
+func (s *QuotaService) Status(ctx context.Context, userID uuid.UUID) (*Status, error) {
+	role, err := s.repo.Role(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.repo.Usage(ctx, userID, s.today())
+	if err != nil {
+		return nil, err
+	}
+	status := s.newStatus(role, s.cfg.For(role))
+	status.setUsed(used)
+	return status, nil
+}
+
+func (s *QuotaService) today() time.Time {
+	now := s.now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (s *QuotaService) newStatus(role string, limits Limits) *Status {
+	return &Status{
+		Role:          role,
+		Unlimited:     limits.Daily == 0,
+		Limit:         limits.Daily,
+		ResetAt:       s.today().AddDate(0, 0, 1),
+		RatePerMinute: limits.RatePerMinute,
+		Burst:         burst(limits),
+	}
+}
+
+func (st *Status) setUsed(used int) {
+	st.Used = used
+	if !st.Unlimited {
+		st.Remaining = max(st.Limit-used, 0)
+	}
+}
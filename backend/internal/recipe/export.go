@@ -0,0 +1,641 @@
+package recipe
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/storage"
+	"github.com/google/uuid"
+)
+
+// ExportFormat is a file format recipes can be downloaded in.
+type ExportFormat string
+
+const (
+	ExportMarkdown ExportFormat = "markdown"
+	ExportJSONLD   ExportFormat = "jsonld"
+	ExportHTML     ExportFormat = "html"
+	ExportPDF      ExportFormat = "pdf"
+	// ExportZip bundles every other format and the pictures of a library.
+	ExportZip ExportFormat = "zip"
+)
+
+// maxExportRecipes bounds a library export.
+const maxExportRecipes = 2000
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+var exportFiles = map[ExportFormat]struct{ contentType, extension string }{
+	ExportMarkdown: {"text/markdown; charset=utf-8", ".md"},
+	ExportJSONLD:   {"application/ld+json", ".jsonld"},
+	ExportHTML:     {"text/html; charset=utf-8", ".html"},
+	ExportPDF:      {"application/pdf", ".pdf"},
+	ExportZip:      {"application/zip", ".zip"},
+}
+
+// Export is a file ready to download.
+type Export struct {
+	Filename    string
+	ContentType string
+	// Write renders the file. Archives are streamed, so an error may come
+	// after part of the file was written.
+	Write func(w io.Writer) error
+}
+
+func exportBytes(name string, format ExportFormat, data []byte) *Export {
+	return &Export{
+		Filename:    name + exportFiles[format].extension,
+		ContentType: exportFiles[format].contentType,
+		Write: func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		},
+	}
+}
+
+// ParseExportFormat reads the format parameter of an export. It defaults to
+// Markdown for a recipe and to a zip archive for a library, the only
+// format that is library-only.
+func ParseExportFormat(s string, library bool) (ExportFormat, error) {
+	switch f := ExportFormat(s); f {
+	case "":
+		if library {
+			return ExportZip, nil
+		}
+		return ExportMarkdown, nil
+	case ExportMarkdown, ExportJSONLD, ExportHTML, ExportPDF:
+		return f, nil
+	case ExportZip:
+		if library {
+			return f, nil
+		}
+	}
+	if library {
+		return "", fmt.Errorf("%w: format must be zip, markdown, jsonld, html or pdf", ErrUnsupportedFormat)
+	}
+	return "", fmt.Errorf("%w: format must be markdown, jsonld, html or pdf", ErrUnsupportedFormat)
+}
+
+// ExportRecipe renders one of the user's recipes in format.
+func (s *RecipeService) ExportRecipe(ctx context.Context, id uuid.UUID, userID uuid.UUID, format ExportFormat) (*Export, error) {
+	if format == ExportZip {
+		return nil, fmt.Errorf("%w: a single recipe can't be exported as zip", ErrUnsupportedFormat)
+	}
+	recipe, err := s.GetRecipe(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch format {
+	case ExportMarkdown:
+		data = exportMarkdown(recipe)
+	case ExportJSONLD:
+		data, err = json.MarshalIndent(toSchema(recipe), "", "  ")
+	case ExportHTML:
+		data, err = renderHTML(recipe.Title, []card{newCard(recipe, s.exportImage(ctx, recipe))})
+	case ExportPDF:
+		data = renderPDF(recipe.Title, []card{newCard(recipe, s.exportImage(ctx, recipe))})
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("export recipe: %w", err)
+	}
+	return exportBytes(exportName(recipe), format, data), nil
+}
+
+// ExportLibrary renders the user's recipes matching filter, in its order.
+// Every format but zip puts them all in one file; the zip archive holds a
+// folder per recipe with each format and its picture, next to the full
+// recipes as JSON.
+func (s *RecipeService) ExportLibrary(ctx context.Context, userID uuid.UUID, filter RecipeFilter, format ExportFormat) (*Export, error) {
+	recipes, err := s.AllRecipes(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	name := "fridgechef-recipes-" + time.Now().UTC().Format(time.DateOnly)
+
+	var data []byte
+	switch format {
+	case ExportZip:
+		return &Export{
+			Filename:    name + exportFiles[format].extension,
+			ContentType: exportFiles[format].contentType,
+			Write: func(w io.Writer) error {
+				return s.writeArchive(ctx, w, name, recipes)
+			},
+		}, nil
+	case ExportMarkdown:
+		parts := make([][]byte, len(recipes))
+		for i, recipe := range recipes {
+			parts[i] = []byte(strings.TrimSpace(recipeMarkdown(recipe)) + "\n")
+		}
+		data = bytes.Join(parts, []byte("\n---\n\n"))
+	case ExportJSONLD:
+		data, err = schemaLibrary(recipes)
+	case ExportHTML, ExportPDF:
+		// Pictures would make a single document of a library too large;
+		// the archive has them.
+		cards := make([]card, len(recipes))
+		for i, recipe := range recipes {
+			cards[i] = newCard(recipe, nil)
+		}
+		if format == ExportHTML {
+			data, err = renderHTML("FridgeChef", cards)
+		} else {
+			data = renderPDF("FridgeChef", cards)
+		}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("export library: %w", err)
+	}
+	return exportBytes(name, format, data), nil
+}
+
+// AllRecipes returns every recipe of the user matching filter, reading the
+// listing page by page. The filter's limit and cursor are ignored.
+func (s *RecipeService) AllRecipes(ctx context.Context, userID uuid.UUID, filter RecipeFilter) ([]*Recipe, error) {
+	filter.Limit = MaxPageSize
+	filter.after = nil
+
+	var recipes []*Recipe
+	for {
+		page, err := s.ListRecipes(ctx, userID, filter)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, page.Recipes...)
+		if page.NextCursor == "" {
+			return recipes, nil
+		}
+		if len(recipes) >= maxExportRecipes {
+			return nil, fmt.Errorf("%w: more than %d recipes match; narrow the export down with filters", ErrInvalidFilter, maxExportRecipes)
+		}
+		if filter.after, err = decodeCursor(page.NextCursor); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// writeArchive streams a zip archive of recipes into a folder called root.
+func (s *RecipeService) writeArchive(ctx context.Context, w io.Writer, root string, recipes []*Recipe) error {
+	zw := zip.NewWriter(w)
+	add := func(name string, modified time.Time, compress bool, data []byte) error {
+		header := &zip.FileHeader{Name: path.Join(root, name), Modified: modified, Method: zip.Store}
+		if compress {
+			header.Method = zip.Deflate
+		}
+		f, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+
+	now := time.Now()
+	full, err := json.MarshalIndent(recipes, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add("recipes.json", now, true, full); err != nil {
+		return err
+	}
+	ld, err := schemaLibrary(recipes)
+	if err != nil {
+		return err
+	}
+	if err := add("recipes.jsonld", now, true, ld); err != nil {
+		return err
+	}
+
+	for _, recipe := range recipes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		dir := exportName(recipe)
+		image := s.exportImage(ctx, recipe)
+		c := newCard(recipe, image)
+
+		ld, err := json.MarshalIndent(toSchema(recipe), "", "  ")
+		if err != nil {
+			return err
+		}
+		page, err := renderHTML(recipe.Title, []card{c})
+		if err != nil {
+			return err
+		}
+		doc := renderPDF(recipe.Title, []card{c})
+		files := []archiveFile{
+			{"recipe.md", true, exportMarkdown(recipe)},
+			{"recipe.jsonld", true, ld},
+			{"recipe.html", true, page},
+			// PDFs and pictures are compressed already.
+			{"recipe.pdf", false, doc},
+		}
+		if image != nil {
+			files = append(files, archiveFile{"image" + imageExtensions[image.ContentType], false, image.Data})
+		}
+		for _, f := range files {
+			if err := add(path.Join(dir, f.name), recipe.UpdatedAt, f.compress, f.data); err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+type archiveFile struct {
+	name     string
+	compress bool
+	data     []byte
+}
+
+// exportImage loads the picture of a recipe for an export, which goes on
+// without it when it can't be read.
+func (s *RecipeService) exportImage(ctx context.Context, recipe *Recipe) *storage.Object {
+	if recipe.ImageKey == "" {
+		return nil
+	}
+	obj, _, err := s.loadImage(ctx, recipe.ImageKey)
+	if err != nil {
+		log.Printf("export image of recipe %s: %v", recipe.ID, err)
+		return nil
+	}
+	if obj.ContentType == "" {
+		obj.ContentType = http.DetectContentType(obj.Data)
+	}
+	return obj
+}
+
+// exportName is the file name of an exported recipe: its title as a slug
+// and the start of its ID, which keeps the names in an archive apart.
+func exportName(recipe *Recipe) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(recipe.Title) {
+		if r >= 0xC0 && r <= 0xFF {
+			r = rune(latinBase[r-0xC0])
+		}
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+		if sb.Len() >= 60 {
+			break
+		}
+	}
+	if sb.Len() == 0 {
+		sb.WriteString("recipe")
+	}
+	return sb.String() + "-" + recipe.ID.String()[:8]
+}
+
+// recipeMarkdown is the markdown of a recipe, which starts with its title.
+func recipeMarkdown(recipe *Recipe) string {
+	content := strings.TrimSpace(recipe.ContentMarkdown)
+	if content == "" && recipe.IsStructured() {
+		return RenderMarkdown(recipe.Title, recipe.CaloriesEstimate, recipe.Details, recipe.Language)
+	}
+	if !strings.HasPrefix(content, "# ") {
+		content = "# " + recipe.Title + "\n\n" + content
+	}
+	return content + "\n"
+}
+
+// exportMarkdown is the markdown of a recipe behind YAML front matter with
+// the fields the markdown doesn't carry.
+func exportMarkdown(recipe *Recipe) []byte {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "title: %s\n", strconv.Quote(recipe.Title))
+	if recipe.Servings > 0 {
+		fmt.Fprintf(&sb, "servings: %d\n", recipe.Servings)
+	}
+	if recipe.PrepTimeMinutes > 0 {
+		fmt.Fprintf(&sb, "prep_time_minutes: %d\n", recipe.PrepTimeMinutes)
+	}
+	if recipe.CookTimeMinutes > 0 {
+		fmt.Fprintf(&sb, "cook_time_minutes: %d\n", recipe.CookTimeMinutes)
+	}
+	if recipe.CaloriesEstimate > 0 {
+		fmt.Fprintf(&sb, "calories: %d\n", recipe.CaloriesEstimate)
+	}
+	if recipe.Cuisine != "" {
+		fmt.Fprintf(&sb, "cuisine: %s\n", strconv.Quote(recipe.Cuisine))
+	}
+	if len(recipe.Tags) > 0 {
+		tags := make([]string, len(recipe.Tags))
+		for i, tag := range recipe.Tags {
+			tags[i] = strconv.Quote(tag)
+		}
+		fmt.Fprintf(&sb, "tags: [%s]\n", strings.Join(tags, ", "))
+	}
+	if recipe.Language != "" {
+		fmt.Fprintf(&sb, "language: %s\n", recipe.Language)
+	}
+	if recipe.SourceURL != "" {
+		fmt.Fprintf(&sb, "source: %s\n", strconv.Quote(recipe.SourceURL))
+	}
+	fmt.Fprintf(&sb, "created: %s\n", recipe.CreatedAt.UTC().Format(time.RFC3339))
+	sb.WriteString("---\n\n")
+	sb.WriteString(recipeMarkdown(recipe))
+	return []byte(sb.String())
+}
+
+// exportDetails returns the ingredients and steps of a recipe, read from
+// its markdown when it has no structured ones.
+func exportDetails(recipe *Recipe) Details {
+	d := recipe.Details
+	if d.IsStructured() {
+		return d
+	}
+	parsed := ParseMarkdown(recipe.ContentMarkdown)
+	d.Ingredients, d.Steps = parsed.Ingredients, parsed.Steps
+	if len(d.Ingredients) == 0 {
+		var names []string
+		if json.Unmarshal(recipe.IngredientsUsed, &names) == nil {
+			for _, name := range names {
+				d.Ingredients = append(d.Ingredients, Ingredient{Name: name})
+			}
+		}
+	}
+	return d
+}
+
+const schemaContext = "https://schema.org"
+
+// schemaRecipe is a schema.org Recipe, the format recipe sites embed and
+// the importer reads back.
+type schemaRecipe struct {
+	Context            string           `json:"@context,omitempty"`
+	Type               string           `json:"@type"`
+	Name               string           `json:"name"`
+	InLanguage         string           `json:"inLanguage,omitempty"`
+	DateCreated        string           `json:"dateCreated"`
+	DateModified       string           `json:"dateModified"`
+	RecipeYield        string           `json:"recipeYield,omitempty"`
+	PrepTime           string           `json:"prepTime,omitempty"`
+	CookTime           string           `json:"cookTime,omitempty"`
+	TotalTime          string           `json:"totalTime,omitempty"`
+	RecipeCuisine      string           `json:"recipeCuisine,omitempty"`
+	Keywords           string           `json:"keywords,omitempty"`
+	RecipeIngredient   []string         `json:"recipeIngredient"`
+	RecipeInstructions []schemaStep     `json:"recipeInstructions"`
+	Nutrition          *schemaNutrition `json:"nutrition,omitempty"`
+	IsBasedOn          string           `json:"isBasedOn,omitempty"`
+}
+
+type schemaStep struct {
+	Type string `json:"@type"`
+	Text string `json:"text"`
+}
+
+type schemaNutrition struct {
+	Type                string `json:"@type"`
+	ServingSize         string `json:"servingSize,omitempty"`
+	Calories            string `json:"calories,omitempty"`
+	ProteinContent      string `json:"proteinContent,omitempty"`
+	FatContent          string `json:"fatContent,omitempty"`
+	CarbohydrateContent string `json:"carbohydrateContent,omitempty"`
+	FiberContent        string `json:"fiberContent,omitempty"`
+	SodiumContent       string `json:"sodiumContent,omitempty"`
+}
+
+// schemaLibrary writes recipes as the @graph of one JSON-LD document.
+func schemaLibrary(recipes []*Recipe) ([]byte, error) {
+	graph := make([]schemaRecipe, len(recipes))
+	for i, recipe := range recipes {
+		graph[i] = toSchema(recipe)
+		graph[i].Context = ""
+	}
+	return json.MarshalIndent(struct {
+		Context string         `json:"@context"`
+		Graph   []schemaRecipe `json:"@graph"`
+	}{schemaContext, graph}, "", "  ")
+}
+
+func toSchema(recipe *Recipe) schemaRecipe {
+	d := exportDetails(recipe)
+	s := schemaRecipe{
+		Context:            schemaContext,
+		Type:               "Recipe",
+		Name:               recipe.Title,
+		InLanguage:         recipe.Language,
+		DateCreated:        recipe.CreatedAt.UTC().Format(time.RFC3339),
+		DateModified:       recipe.UpdatedAt.UTC().Format(time.RFC3339),
+		PrepTime:           isoDuration(d.PrepTimeMinutes),
+		CookTime:           isoDuration(d.CookTimeMinutes),
+		TotalTime:          isoDuration(d.PrepTimeMinutes + d.CookTimeMinutes),
+		RecipeCuisine:      d.Cuisine,
+		Keywords:           strings.Join(d.Tags, ", "),
+		RecipeIngredient:   []string{},
+		RecipeInstructions: []schemaStep{},
+		IsBasedOn:          recipe.SourceURL,
+	}
+	if d.Servings > 0 {
+		s.RecipeYield = strconv.Itoa(d.Servings)
+	}
+	for _, ing := range d.Ingredients {
+		s.RecipeIngredient = append(s.RecipeIngredient, ing.String())
+	}
+	for _, step := range d.Steps {
+		s.RecipeInstructions = append(s.RecipeInstructions, schemaStep{Type: "HowToStep", Text: step})
+	}
+
+	calories := recipe.CaloriesEstimate
+	if n := recipe.Nutrition; n != nil {
+		if calories == 0 {
+			calories = int(n.PerServing.Calories + 0.5)
+		}
+		s.Nutrition = &schemaNutrition{
+			Type:                "NutritionInformation",
+			ProteinContent:      grams(n.PerServing.Protein, "g"),
+			FatContent:          grams(n.PerServing.Fat, "g"),
+			CarbohydrateContent: grams(n.PerServing.Carbs, "g"),
+			FiberContent:        grams(n.PerServing.Fiber, "g"),
+			SodiumContent:       grams(n.PerServing.Sodium, "mg"),
+		}
+		if d.Servings > 0 {
+			s.Nutrition.ServingSize = "1 serving"
+		}
+	}
+	if calories > 0 {
+		if s.Nutrition == nil {
+			s.Nutrition = &schemaNutrition{Type: "NutritionInformation"}
+		}
+		s.Nutrition.Calories = fmt.Sprintf("%d calories", calories)
+	}
+	return s
+}
+
+// isoDuration writes minutes as an ISO 8601 duration such as PT1H30M.
+func isoDuration(minutes int) string {
+	switch {
+	case minutes <= 0:
+		return ""
+	case minutes%60 == 0:
+		return fmt.Sprintf("PT%dH", minutes/60)
+	case minutes > 60:
+		return fmt.Sprintf("PT%dH%dM", minutes/60, minutes%60)
+	default:
+		return fmt.Sprintf("PT%dM", minutes)
+	}
+}
+
+func grams(amount float64, unit string) string {
+	if amount <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(int(amount*10+0.5))/10, 'f', -1, 64) + " " + unit
+}
+
+// card is a recipe laid out for printing, shared by the HTML and PDF
+// exports.
+type card struct {
+	Title    string
+	Language string
+	Meta     []string
+	Image    *storage.Object
+	Sections []cardSection
+	Tags     []string
+	Source   string
+	// SourceLabel introduces Source.
+	SourceLabel string
+}
+
+type cardSection struct {
+	Heading  string
+	Numbered bool
+	Items    []string
+}
+
+type cardLabels struct {
+	Nutrition string
+	Protein   string
+	Fat       string
+	Carbs     string
+	Fiber     string
+	Sodium    string
+	Source    string
+}
+
+var printLabels = map[string]cardLabels{
+	"en": {"Nutrition per serving", "Protein", "Fat", "Carbohydrates", "Fiber", "Sodium", "Source"},
+	"pt": {"Informação nutricional por porção", "Proteínas", "Gorduras", "Carboidratos", "Fibras", "Sódio", "Fonte"},
+	"es": {"Información nutricional por porción", "Proteínas", "Grasas", "Carbohidratos", "Fibra", "Sodio", "Fuente"},
+	"fr": {"Valeurs nutritionnelles par portion", "Protéines", "Lipides", "Glucides", "Fibres", "Sodium", "Source"},
+}
+
+func newCard(recipe *Recipe, image *storage.Object) card {
+	l := labelsFor(recipe.Language)
+	pl, ok := printLabels[locale.Resolve(recipe.Language).Base()]
+	if !ok {
+		pl = printLabels["en"]
+	}
+	d := exportDetails(recipe)
+
+	c := card{
+		Title:       recipe.Title,
+		Language:    locale.Resolve(recipe.Language).Base(),
+		Tags:        d.Tags,
+		Source:      recipe.SourceURL,
+		SourceLabel: pl.Source,
+	}
+	// Only the types images are stored as, which are safe to inline.
+	if image != nil && imageExtensions[image.ContentType] != "" {
+		c.Image = image
+	}
+	if d.Servings > 0 {
+		c.Meta = append(c.Meta, fmt.Sprintf("%s: %d", l.Servings, d.Servings))
+	}
+	if d.PrepTimeMinutes > 0 {
+		c.Meta = append(c.Meta, fmt.Sprintf("%s: %d min", l.Prep, d.PrepTimeMinutes))
+	}
+	if d.CookTimeMinutes > 0 {
+		c.Meta = append(c.Meta, fmt.Sprintf("%s: %d min", l.Cook, d.CookTimeMinutes))
+	}
+	if recipe.CaloriesEstimate > 0 {
+		c.Meta = append(c.Meta, fmt.Sprintf("%s: %d kcal", l.Calories, recipe.CaloriesEstimate))
+	}
+	if d.Cuisine != "" {
+		c.Meta = append(c.Meta, d.Cuisine)
+	}
+
+	if len(d.Ingredients) == 0 && len(d.Steps) == 0 {
+		// Nothing to structure: print the markdown as paragraphs.
+		section := cardSection{}
+		for _, line := range strings.Split(recipe.ContentMarkdown, "\n") {
+			if line = plainText(line); line != "" && line != recipe.Title {
+				section.Items = append(section.Items, line)
+			}
+		}
+		c.Sections = append(c.Sections, section)
+		return c
+	}
+
+	ingredients := cardSection{Heading: l.Ingredients}
+	for _, ing := range d.Ingredients {
+		ingredients.Items = append(ingredients.Items, plainText(ing.String()))
+	}
+	steps := cardSection{Heading: l.Instructions, Numbered: true}
+	for _, step := range d.Steps {
+		steps.Items = append(steps.Items, plainText(step))
+	}
+	c.Sections = append(c.Sections, ingredients, steps)
+
+	if n := recipe.Nutrition; n != nil && n.PerServing.Calories > 0 {
+		nutrition := cardSection{Heading: pl.Nutrition, Items: []string{
+			fmt.Sprintf("%s: %.0f kcal", l.Calories, n.PerServing.Calories),
+		}}
+		for _, v := range []struct {
+			label, unit string
+			amount      float64
+		}{
+			{pl.Protein, "g", n.PerServing.Protein},
+			{pl.Fat, "g", n.PerServing.Fat},
+			{pl.Carbs, "g", n.PerServing.Carbs},
+			{pl.Fiber, "g", n.PerServing.Fiber},
+			{pl.Sodium, "mg", n.PerServing.Sodium},
+		} {
+			if amount := grams(v.amount, v.unit); amount != "" {
+				nutrition.Items = append(nutrition.Items, v.label+": "+amount)
+			}
+		}
+		c.Sections = append(c.Sections, nutrition)
+	}
+	return c
+}
+
+// plainText strips the markdown markers from a line of a recipe.
+func plainText(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimLeft(line, "#")
+	if m := bulletLine.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+		line = m[1]
+	}
+	line = strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+	return strings.TrimSpace(line)
+}
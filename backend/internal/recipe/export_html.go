@@ -0,0 +1,89 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html/template"
+)
+
+// printTemplate renders recipe cards as a self-contained page: styles are
+// inline and pictures are data URIs, so it prints the same saved to disk.
+var printTemplate = template.Must(template.New("print").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+  @page { size: A4; margin: 18mm; }
+  body { font: 11pt/1.5 Helvetica, Arial, sans-serif; color: #222; margin: 0; }
+  .card { max-width: 720px; margin: 24px auto; padding: 0 16px; }
+  .card + .card { break-before: page; }
+  h1 { font-size: 22pt; margin: 0 0 4px; }
+  h2 { font-size: 13pt; margin: 18px 0 6px; border-bottom: 1px solid #ddd; padding-bottom: 2px; }
+  .meta, .tags, .source { color: #666; font-size: 10pt; margin: 4px 0; }
+  .meta span + span::before { content: " · "; }
+  .tags span { margin-right: 8px; }
+  .source a { color: inherit; word-break: break-all; }
+  img { display: block; max-width: 100%; max-height: 300px; margin: 12px 0; border-radius: 6px; }
+  ul, ol { padding-left: 22px; margin: 0; }
+  li { margin: 3px 0; break-inside: avoid; }
+  p.text { margin: 6px 0; }
+  @media print { .card { margin: 0 auto; padding: 0; } }
+</style>
+</head>
+<body>
+{{- range .Cards}}
+<article class="card" lang="{{.Language}}">
+  <h1>{{.Title}}</h1>
+  {{- if .Meta}}
+  <p class="meta">{{range .Meta}}<span>{{.}}</span>{{end}}</p>
+  {{- end}}
+  {{- with .ImageURI}}
+  <img src="{{.}}" alt="">
+  {{- end}}
+  {{- range .Sections}}
+  <section>
+    {{- if .Heading}}
+    <h2>{{.Heading}}</h2>
+    {{- end}}
+    {{- if .Numbered}}
+    <ol>{{range .Items}}<li>{{.}}</li>{{end}}</ol>
+    {{- else if .Heading}}
+    <ul>{{range .Items}}<li>{{.}}</li>{{end}}</ul>
+    {{- else}}
+    {{- range .Items}}
+    <p class="text">{{.}}</p>
+    {{- end}}
+    {{- end}}
+  </section>
+  {{- end}}
+  {{- if .Tags}}
+  <p class="tags">{{range .Tags}}<span>#{{.}}</span>{{end}}</p>
+  {{- end}}
+  {{- if .Source}}
+  <p class="source">{{.SourceLabel}}: <a href="{{.Source}}">{{.Source}}</a></p>
+  {{- end}}
+</article>
+{{- end}}
+</body>
+</html>
+`))
+
+// ImageURI embeds the card's picture as a data URI.
+func (c card) ImageURI() template.URL {
+	if c.Image == nil {
+		return ""
+	}
+	return template.URL("data:" + c.Image.ContentType + ";base64," + base64.StdEncoding.EncodeToString(c.Image.Data))
+}
+
+// renderHTML renders cards as a printable page.
+func renderHTML(title string, cards []card) ([]byte, error) {
+	var buf bytes.Buffer
+	err := printTemplate.Execute(&buf, struct {
+		Title string
+		Cards []card
+	}{title, cards})
+	return buf.Bytes(), err
+}
@@ -0,0 +1,304 @@
+package recipe
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/storage"
+	"github.com/google/uuid"
+)
+
+func exportFixture() *Recipe {
+	d := Details{
+		Servings:        4,
+		PrepTimeMinutes: 15,
+		CookTimeMinutes: 75,
+		Cuisine:         "French",
+		Tags:            []string{"soup", "vegetarian"},
+		Ingredients: []Ingredient{
+			{Name: "onions", Quantity: 1.0 / 3, Unit: "kg", Note: "sliced"},
+			{Name: "Gruyère", Quantity: 100, Unit: "g"},
+			{Name: "salt"},
+		},
+		Steps: []string{"Caramelize the onions (slowly).", "Add stock and simmer.", "Top with bread and cheese, then broil."},
+	}
+	return &Recipe{
+		ID:               uuid.MustParse("3f2b6a1c-8d4e-4f0a-9b7c-1e2d3c4b5a69"),
+		Title:            "Crème de l'oignon",
+		ContentMarkdown:  RenderMarkdown("Crème de l'oignon", 420, d, "fr"),
+		CaloriesEstimate: 420,
+		Language:         "fr",
+		SourceURL:        "https://example.com/soupe",
+		CreatedAt:        time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:        time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC),
+		Details:          d,
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		library bool
+		want    ExportFormat
+	}{
+		{"", false, ExportMarkdown},
+		{"", true, ExportZip},
+		{"pdf", false, ExportPDF},
+		{"zip", true, ExportZip},
+		{"jsonld", true, ExportJSONLD},
+	}
+	for _, tt := range tests {
+		got, err := ParseExportFormat(tt.in, tt.library)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseExportFormat(%q, %v) = %q, %v; want %q", tt.in, tt.library, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"zip", "docx", "PDF"} {
+		if _, err := ParseExportFormat(in, false); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("ParseExportFormat(%q, false) error = %v, want ErrUnsupportedFormat", in, err)
+		}
+	}
+}
+
+func TestExportName(t *testing.T) {
+	r := exportFixture()
+	if got := exportName(r); got != "creme-de-l-oignon-3f2b6a1c" {
+		t.Errorf("exportName = %q", got)
+	}
+	r.Title = "🍲"
+	if got := exportName(r); got != "recipe-3f2b6a1c" {
+		t.Errorf("exportName of an emoji title = %q", got)
+	}
+	if len(latinBase) != 0x100-0xC0 {
+		t.Errorf("latinBase has %d characters, want one per Latin-1 letter", len(latinBase))
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	got := string(exportMarkdown(exportFixture()))
+	for _, want := range []string{
+		"---\ntitle: \"Crème de l'oignon\"\n",
+		"servings: 4\n",
+		"tags: [\"soup\", \"vegetarian\"]\n",
+		"source: \"https://example.com/soupe\"\n",
+		"---\n\n# Crème de l'oignon\n",
+		"- 1/3 kg onions (sliced)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestToSchema(t *testing.T) {
+	data, err := json.Marshal(toSchema(exportFixture()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var node map[string]any
+	if err := json.Unmarshal(data, &node); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"@context":      "https://schema.org",
+		"@type":         "Recipe",
+		"name":          "Crème de l'oignon",
+		"recipeYield":   "4",
+		"prepTime":      "PT15M",
+		"cookTime":      "PT1H15M",
+		"totalTime":     "PT1H30M",
+		"keywords":      "soup, vegetarian",
+		"inLanguage":    "fr",
+		"isBasedOn":     "https://example.com/soupe",
+		"recipeCuisine": "French",
+	}
+	for key, value := range want {
+		if node[key] != value {
+			t.Errorf("%s = %v, want %v", key, node[key], value)
+		}
+	}
+	if got := node["recipeIngredient"].([]any); len(got) != 3 || got[0] != "1/3 kg onions (sliced)" {
+		t.Errorf("recipeIngredient = %v", got)
+	}
+	steps := node["recipeInstructions"].([]any)
+	if len(steps) != 3 || steps[0].(map[string]any)["@type"] != "HowToStep" {
+		t.Errorf("recipeInstructions = %v", steps)
+	}
+	if got := node["nutrition"].(map[string]any)["calories"]; got != "420 calories" {
+		t.Errorf("nutrition.calories = %v", got)
+	}
+
+	// A recipe with only markdown still exports its lines.
+	legacy := &Recipe{
+		Title:           "Toast",
+		ContentMarkdown: "# Toast\n\n## Ingredients\n- 2 slices bread\n\n## Instructions\n1. Toast the bread.\n",
+	}
+	s := toSchema(legacy)
+	if len(s.RecipeIngredient) != 1 || len(s.RecipeInstructions) != 1 || s.RecipeInstructions[0].Text != "Toast the bread." {
+		t.Errorf("legacy recipe = %+v", s)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	r := exportFixture()
+	r.Title = "<script>alert(1)</script>"
+	png := placeholderPNG(t)
+	page, err := renderHTML(r.Title, []card{newCard(r, &storage.Object{Data: png, ContentType: "image/png"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(page)
+	if strings.Contains(got, "<script>") {
+		t.Error("title is not escaped")
+	}
+	for _, want := range []string{`<article class="card" lang="fr">`, "<h2>Ingrédients</h2>", "<li>Add stock and simmer.</li>", `src="data:image/png;base64,`, "Source: <a"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+
+	// Only the types images are stored as are inlined.
+	svg := newCard(r, &storage.Object{Data: []byte("<svg/>"), ContentType: "image/svg+xml"})
+	if svg.Image != nil {
+		t.Error("svg picture kept")
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	r := exportFixture()
+	long := exportFixture()
+	for i := range 80 {
+		long.Steps = append(long.Steps, "Stir the pot and taste the soup, step "+strconv.Itoa(i)+".")
+	}
+	doc := renderPDF("Soups", []card{
+		newCard(r, &storage.Object{Data: placeholderPNG(t), ContentType: "image/png"}),
+		newCard(long, nil),
+	})
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d doesn't point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj\n"; !bytes.HasPrefix(doc[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, doc[offset:offset+10])
+		}
+	}
+
+	pages := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(doc)
+	if n, _ := strconv.Atoi(string(pages[1])); n < 3 {
+		t.Errorf("/Count = %d, want the long recipe to run over a page", n)
+	}
+	if !bytes.Contains(doc, []byte("/Subtype /Image /Width 800 /Height 600")) {
+		t.Error("picture not embedded")
+	}
+
+	var text strings.Builder
+	streams := regexp.MustCompile(`(?s)/Filter /FlateDecode /Length (\d+) >>\nstream\n`)
+	for _, loc := range streams.FindAllSubmatchIndex(doc, -1) {
+		n, _ := strconv.Atoi(string(doc[loc[2]:loc[3]]))
+		zr, err := zlib.NewReader(bytes.NewReader(doc[loc[1] : loc[1]+n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(zr)
+		text.Write(content)
+	}
+	for _, want := range []string{
+		"(Cr\xe8me de l'oignon) Tj",
+		"(Caramelize the onions \\(slowly\\).) Tj",
+		"(1/3 kg onions \\(sliced\\)) Tj",
+		"(\x95) Tj",
+		"(1 / 3) Tj",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("page content lacks %q", want)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("Simmer the onions gently until golden https://example.com/a/very/long/address/that/cannot/break", pdfRegular, 11, 150)
+	if len(lines) < 3 {
+		t.Fatalf("lines = %q", lines)
+	}
+	for _, line := range lines {
+		if w := textWidth(line, pdfRegular, 11); w > 150 {
+			t.Errorf("%q is %.1f points wide", line, w)
+		}
+	}
+	if got := pdfEncode("½ cup “milk” ⅓ 🍲 日"); got != "\xbd cup \x93milk\x94 1/3  ?" {
+		t.Errorf("pdfEncode = %q", got)
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	legacy := &Recipe{
+		ID:              uuid.MustParse("9a8b7c6d-0000-4000-8000-000000000001"),
+		Title:           "Toast",
+		ContentMarkdown: "# Toast\n\nToast the bread.",
+	}
+	var buf bytes.Buffer
+	s := &RecipeService{}
+	if err := s.writeArchive(t.Context(), &buf, "backup", []*Recipe{exportFixture(), legacy}); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	for _, want := range []string{
+		"backup/recipes.json",
+		"backup/recipes.jsonld",
+		"backup/creme-de-l-oignon-3f2b6a1c/recipe.md",
+		"backup/creme-de-l-oignon-3f2b6a1c/recipe.pdf",
+		"backup/toast-9a8b7c6d/recipe.html",
+		"backup/toast-9a8b7c6d/recipe.jsonld",
+	} {
+		if !slices.Contains(names, want) {
+			t.Errorf("archive lacks %s; has %v", want, names)
+		}
+	}
+
+	f, err := zr.Open("backup/recipes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recipes []Recipe
+	if err := json.NewDecoder(f).Decode(&recipes); err != nil || len(recipes) != 2 || recipes[0].SourceURL == "" {
+		t.Errorf("recipes.json = %+v, %v", recipes, err)
+	}
+}
+
+func placeholderPNG(t *testing.T) []byte {
+	t.Helper()
+	data, err := renderPlaceholder("Onion soup", []string{"onion", "cheese"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
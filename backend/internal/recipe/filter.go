@@ -0,0 +1,248 @@
+package recipe
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+	// maxSearchLength bounds the search query of a listing.
+	maxSearchLength = 200
+)
+
+var (
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Sort is the order of a recipe listing.
+type Sort string
+
+const (
+	SortCreatedAt  Sort = "created_at"
+	SortTitle      Sort = "title"
+	SortCalories   Sort = "calories"
+	SortLastCooked Sort = "last_cooked"
+	// SortRelevance ranks by how well recipes match the search query.
+	SortRelevance Sort = "relevance"
+)
+
+// sortDescending is the direction of each sort when none is asked for.
+var sortDescending = map[Sort]bool{
+	SortCreatedAt:  true,
+	SortTitle:      false,
+	SortCalories:   false,
+	SortLastCooked: true,
+	SortRelevance:  true,
+}
+
+type RecipeFilter struct {
+	// Query is a web-style search, with quoted phrases and -exclusions,
+	// matched against titles, ingredients and content.
+	Query       string
+	MinCalories int
+	MaxCalories int
+	// Ingredients must all be used by a recipe, or any one of them when
+	// AnyIngredient is set. Names match as substrings, so "chicken" finds
+	// "Chicken Breast".
+	Ingredients        []string
+	AnyIngredient      bool
+	ExcludeIngredients []string
+	// Tags must all be on a recipe.
+	Tags []string
+	// Favorite keeps only favorites; Collection only recipes in it.
+	Favorite   bool
+	Collection uuid.UUID
+	// CreatedFrom and CreatedTo bound the creation time; CreatedTo is
+	// exclusive. Zero leaves a side open.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Sort        Sort
+	Descending  bool
+	Limit       int
+	// after is where the previous page ended.
+	after *cursor
+}
+
+// RecipePage is one page of a listing. NextCursor fetches the next one and
+// is empty on the last page.
+type RecipePage struct {
+	Recipes    []*Recipe `json:"recipes"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// cursor is the sort key and ID of the last recipe of a page. The key is
+// kept as Postgres prints it, so it compares exactly when sent back.
+type cursor struct {
+	Sort       Sort      `json:"s"`
+	Descending bool      `json:"d"`
+	Key        string    `json:"k"`
+	ID         uuid.UUID `json:"id"`
+}
+
+func (c cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// ParseRecipeFilter reads a listing's query string: q, sort, order, limit,
+// cursor, min_calories, max_calories, ingredient (repeatable or comma
+// separated), ingredient_match (all or any), exclude_ingredient, tag,
+// favorite, collection, created_from and created_to (dates, both
+// inclusive, or RFC 3339 times).
+func ParseRecipeFilter(query url.Values) (RecipeFilter, error) {
+	filter := RecipeFilter{
+		Query:              strings.TrimSpace(query.Get("q")),
+		Ingredients:        listParam(query, "ingredient"),
+		ExcludeIngredients: listParam(query, "exclude_ingredient"),
+		Tags:               listParam(query, "tag"),
+		Limit:              DefaultPageSize,
+	}
+	if utf8.RuneCountInString(filter.Query) > maxSearchLength {
+		return filter, fmt.Errorf("%w: q must be at most %d characters", ErrInvalidFilter, maxSearchLength)
+	}
+
+	var err error
+	if filter.MinCalories, err = intParam(query, "min_calories", 0, 1<<31-1); err != nil {
+		return filter, err
+	}
+	if filter.MaxCalories, err = intParam(query, "max_calories", 0, 1<<31-1); err != nil {
+		return filter, err
+	}
+	if filter.MaxCalories > 0 && filter.MinCalories > filter.MaxCalories {
+		return filter, fmt.Errorf("%w: min_calories is above max_calories", ErrInvalidFilter)
+	}
+	if query.Has("limit") {
+		if filter.Limit, err = intParam(query, "limit", 1, MaxPageSize); err != nil {
+			return filter, err
+		}
+	}
+
+	if v := query.Get("favorite"); v != "" {
+		if filter.Favorite, err = strconv.ParseBool(v); err != nil {
+			return filter, fmt.Errorf("%w: favorite must be true or false", ErrInvalidFilter)
+		}
+	}
+	if v := query.Get("collection"); v != "" {
+		if filter.Collection, err = uuid.Parse(v); err != nil {
+			return filter, fmt.Errorf("%w: collection must be a collection ID", ErrInvalidFilter)
+		}
+	}
+
+	switch match := query.Get("ingredient_match"); match {
+	case "", "all":
+	case "any":
+		filter.AnyIngredient = true
+	default:
+		return filter, fmt.Errorf("%w: ingredient_match must be all or any", ErrInvalidFilter)
+	}
+
+	if filter.CreatedFrom, err = timeParam(query, "created_from", false); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = timeParam(query, "created_to", true); err != nil {
+		return filter, err
+	}
+
+	filter.Sort = Sort(query.Get("sort"))
+	if filter.Sort == "" {
+		filter.Sort = SortCreatedAt
+		if filter.Query != "" {
+			filter.Sort = SortRelevance
+		}
+	}
+	descending, ok := sortDescending[filter.Sort]
+	if !ok {
+		return filter, fmt.Errorf("%w: sort must be created_at, title, calories, last_cooked or relevance", ErrInvalidFilter)
+	}
+	if filter.Sort == SortRelevance && filter.Query == "" {
+		return filter, fmt.Errorf("%w: sorting by relevance needs a search query", ErrInvalidFilter)
+	}
+	switch order := query.Get("order"); order {
+	case "":
+		filter.Descending = descending
+	case "asc", "desc":
+		filter.Descending = order == "desc"
+	default:
+		return filter, fmt.Errorf("%w: order must be asc or desc", ErrInvalidFilter)
+	}
+
+	if c := query.Get("cursor"); c != "" {
+		if filter.after, err = decodeCursor(c); err != nil {
+			return filter, err
+		}
+		// A cursor only points into the order it was made for.
+		if filter.after.Sort != filter.Sort || filter.after.Descending != filter.Descending {
+			return filter, fmt.Errorf("%w: it belongs to a different sort order", ErrInvalidCursor)
+		}
+	}
+	return filter, nil
+}
+
+// listParam collects a parameter given several times or comma separated.
+func listParam(query url.Values, name string) []string {
+	var values []string
+	for _, v := range query[name] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+func intParam(query url.Values, name string, lo, hi int) (int, error) {
+	v := query.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%w: %s must be a whole number between %d and %d", ErrInvalidFilter, name, lo, hi)
+	}
+	return n, nil
+}
+
+// timeParam reads a date or an RFC 3339 time. A date given as an upper
+// bound includes the whole day.
+func timeParam(query url.Values, name string, upper bool) (time.Time, error) {
+	v := query.Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		if upper {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be a date (YYYY-MM-DD) or an RFC 3339 time", ErrInvalidFilter, name)
+	}
+	return t, nil
+}
@@ -0,0 +1,71 @@
+package recipe
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseRecipeFilter(t *testing.T) {
+	query, _ := url.ParseQuery("q=chicken&ingredient=rice,beans&ingredient=garlic&ingredient_match=any" +
+		"&exclude_ingredient=peanut&tag=quick&min_calories=200&max_calories=600&created_from=2025-11-01&created_to=2025-11-30&limit=5")
+	filter, err := ParseRecipeFilter(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Sort != SortRelevance || !filter.Descending {
+		t.Errorf("sort = %s desc=%v; want relevance desc for a search", filter.Sort, filter.Descending)
+	}
+	if !slices.Equal(filter.Ingredients, []string{"rice", "beans", "garlic"}) || !filter.AnyIngredient {
+		t.Errorf("ingredients = %v any=%v", filter.Ingredients, filter.AnyIngredient)
+	}
+	if !filter.CreatedTo.Equal(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("created_to = %v; want the end of Nov 30", filter.CreatedTo)
+	}
+	if filter.Limit != 5 || filter.MinCalories != 200 || filter.MaxCalories != 600 {
+		t.Errorf("limit/calories = %d %d %d", filter.Limit, filter.MinCalories, filter.MaxCalories)
+	}
+
+	id := uuid.New()
+	filter, err = ParseRecipeFilter(url.Values{"favorite": {"true"}, "collection": {id.String()}, "sort": {"last_cooked"}})
+	if err != nil || !filter.Favorite || filter.Collection != id || !filter.Descending {
+		t.Errorf("favorite/collection = %+v, %v", filter, err)
+	}
+
+	filter, err = ParseRecipeFilter(url.Values{})
+	if err != nil || filter.Sort != SortCreatedAt || !filter.Descending || filter.Limit != DefaultPageSize {
+		t.Errorf("defaults = %+v, %v", filter, err)
+	}
+
+	for _, raw := range []string{
+		"sort=relevance", "sort=rating", "order=up", "limit=0", "limit=101",
+		"min_calories=500&max_calories=100", "ingredient_match=some", "created_from=yesterday",
+		"favorite=maybe", "collection=weeknight",
+	} {
+		query, _ := url.ParseQuery(raw)
+		if _, err := ParseRecipeFilter(query); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: error = %v; want ErrInvalidFilter", raw, err)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	c := cursor{Sort: SortTitle, Key: "pancakes", ID: uuid.New()}
+	query := url.Values{"sort": {"title"}, "cursor": {c.encode()}}
+	filter, err := ParseRecipeFilter(query)
+	if err != nil || filter.after == nil || *filter.after != c {
+		t.Fatalf("cursor = %+v, %v; want %+v", filter.after, err, c)
+	}
+
+	query.Set("order", "desc")
+	if _, err := ParseRecipeFilter(query); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor for another order: error = %v; want ErrInvalidCursor", err)
+	}
+	if _, err := ParseRecipeFilter(url.Values{"cursor": {"not a cursor"}}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("garbage cursor: error = %v; want ErrInvalidCursor", err)
+	}
+}
@@ -1,16 +1,34 @@
 package recipe
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/storage"
 	"github.com/Igorlimaponce/fridgeChef/backend/middleware"
 	"github.com/Igorlimaponce/fridgeChef/backend/util"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
+// imageWriteTimeout bounds an image generation request; it outlasts the
+// image client timeout so provider errors still reach the client.
+const imageWriteTimeout = 3 * time.Minute
+
+// exportWriteTimeout bounds a library export, which renders every recipe
+// while the archive is sent.
+const exportWriteTimeout = 5 * time.Minute
+
 type RecipeHandler struct {
 	service *RecipeService
 }
@@ -34,6 +52,14 @@ func (h *RecipeHandler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
 
 	recipe, err := h.service.CreateRecipe(r.Context(), userID, req)
 	if err != nil {
+		if errors.Is(err, ErrDegradedRecipe) {
+			util.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		if errors.Is(err, ErrRecipeNotFound) || errors.Is(err, ErrInvalidRecipe) {
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -48,22 +74,128 @@ func (h *RecipeHandler) ListRecipes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var filter RecipeFilter
-	if val := r.URL.Query().Get("max_calories"); val != "" {
-		var maxCal int
-		if _, err := fmt.Sscanf(val, "%d", &maxCal); err == nil {
-			filter.MaxCalories = maxCal
-		}
+	filter, err := ParseRecipeFilter(r.URL.Query())
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	filter.Ingredient = r.URL.Query().Get("ingredient")
 
-	recipes, err := h.service.ListRecipes(r.Context(), userID, filter)
+	page, err := h.service.ListRecipes(r.Context(), userID, filter)
 	if err != nil {
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	util.WriteJSON(w, http.StatusOK, recipes)
+	util.WriteJSON(w, http.StatusOK, page)
+}
+
+// GetRecipe returns one of the user's recipes, scaled when ?servings= is
+// given.
+func (h *RecipeHandler) GetRecipe(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid recipe ID")
+		return
+	}
+
+	var recipe *Recipe
+	if val := r.URL.Query().Get("servings"); val != "" {
+		servings, err := strconv.Atoi(val)
+		if err != nil || servings < 1 || servings > MaxServings {
+			util.WriteError(w, http.StatusBadRequest, fmt.Sprintf("servings must be a whole number between 1 and %d", MaxServings))
+			return
+		}
+		recipe, err = h.service.GetScaledRecipe(r.Context(), id, userID, servings)
+	} else {
+		recipe, err = h.service.GetRecipe(r.Context(), id, userID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRecipeNotFound):
+			util.WriteError(w, http.StatusNotFound, "recipe not found")
+		case errors.Is(err, ErrNotScalable):
+			util.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	// A scaled recipe isn't the stored version, so it can't be used as the
+	// base of an edit.
+	if recipe.ScaledFrom == 0 {
+		etag := recipe.ETag()
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "private, no-cache")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	util.WriteJSON(w, http.StatusOK, recipe)
+}
+
+// ReplaceRecipe handles PUT, replacing every editable field of a recipe.
+func (h *RecipeHandler) ReplaceRecipe(w http.ResponseWriter, r *http.Request) {
+	h.updateRecipe(w, r, true)
+}
+
+// PatchRecipe handles PATCH, changing only the fields in the body.
+func (h *RecipeHandler) PatchRecipe(w http.ResponseWriter, r *http.Request) {
+	h.updateRecipe(w, r, false)
+}
+
+func (h *RecipeHandler) updateRecipe(w http.ResponseWriter, r *http.Request, replace bool) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid recipe ID")
+		return
+	}
+
+	// Edits must name the version they were made on, so two clients can't
+	// silently overwrite each other; "*" opts out explicitly.
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" {
+		util.WriteError(w, http.StatusPreconditionRequired, "If-Match header is required")
+		return
+	}
+
+	var req UpdateRecipeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	recipe, err := h.service.UpdateRecipe(r.Context(), id, userID, req, replace, ifMatch)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRecipeNotFound):
+			util.WriteError(w, http.StatusNotFound, "recipe not found")
+		case errors.Is(err, ErrVersionConflict):
+			util.WriteError(w, http.StatusPreconditionFailed, err.Error())
+		case errors.Is(err, ErrInvalidRecipe):
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("ETag", recipe.ETag())
+	util.WriteJSON(w, http.StatusOK, recipe)
 }
 
 func (h *RecipeHandler) DeleteRecipe(w http.ResponseWriter, r *http.Request) {
@@ -111,6 +243,33 @@ func (h *RecipeHandler) ToggleShare(w http.ResponseWriter, r *http.Request) {
 	util.WriteJSON(w, http.StatusOK, recipe)
 }
 
+// Revisions returns the revision chain of a recipe, oldest first.
+func (h *RecipeHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid recipe ID")
+		return
+	}
+
+	recipes, err := h.service.Revisions(r.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, ErrRecipeNotFound) {
+			util.WriteError(w, http.StatusNotFound, "recipe not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, recipes)
+}
+
 func (h *RecipeHandler) GetPublicRecipe(w http.ResponseWriter, r *http.Request) {
 	token := chi.URLParam(r, "token")
 	if token == "" {
@@ -130,3 +289,304 @@ func (h *RecipeHandler) GetPublicRecipe(w http.ResponseWriter, r *http.Request)
 
 	util.WriteJSON(w, http.StatusOK, recipe)
 }
+
+// GenerateImage draws a new picture for a recipe. Image providers are slow,
+// so the request gets a longer write deadline than the server default.
+func (h *RecipeHandler) GenerateImage(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid recipe ID")
+		return
+	}
+
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(imageWriteTimeout))
+
+	recipe, err := h.service.GenerateImage(r.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, ErrRecipeNotFound) {
+			util.WriteError(w, http.StatusNotFound, "recipe not found")
+			return
+		}
+		status, msg := imageErrorStatus(err)
+		if retryAfter := ia.RetryAfter(err); retryAfter > 0 && (status == http.StatusServiceUnavailable || status == http.StatusTooManyRequests) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+		util.WriteError(w, status, msg)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, recipe)
+}
+
+// GetImage serves the picture of one of the user's recipes.
+func (h *RecipeHandler) GetImage(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid recipe ID")
+		return
+	}
+
+	obj, version, err := h.service.Image(r.Context(), id, userID)
+	writeImage(w, r, obj, version, err, "private, max-age=86400")
+}
+
+// GetPublicImage serves the picture of a shared recipe.
+func (h *RecipeHandler) GetPublicImage(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		util.WriteError(w, http.StatusBadRequest, "token required")
+		return
+	}
+
+	obj, version, err := h.service.PublicImage(r.Context(), token)
+	writeImage(w, r, obj, version, err, "public, max-age=86400")
+}
+
+// writeImage answers with obj, or with 304 when the client already has this
+// version. Image keys change with their content, so versions never go stale.
+func writeImage(w http.ResponseWriter, r *http.Request, obj *storage.Object, version string, err error, cacheControl string) {
+	if err != nil {
+		if errors.Is(err, ErrRecipeNotFound) || errors.Is(err, ErrImageNotFound) {
+			util.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	etag := `"` + version + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	contentType := obj.ContentType
+	if contentType == "" {
+		contentType = http.DetectContentType(obj.Data)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Length", strconv.Itoa(len(obj.Data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(obj.Data)
+}
+
+func imageErrorStatus(err error) (int, string) {
+	var apiErr *ia.APIError
+	switch {
+	case errors.Is(err, ia.ErrCircuitOpen):
+		return http.StatusServiceUnavailable, "image generation is temporarily unavailable"
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests:
+		return http.StatusTooManyRequests, "image provider is busy, please try again shortly"
+	case errors.As(err, &apiErr):
+		return http.StatusBadGateway, "failed to generate image"
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "image generation timed out"
+	default:
+		return http.StatusInternalServerError, "failed to generate image"
+	}
+}
+
+// ListTags returns the user's tags with how many recipes carry each.
+func (h *RecipeHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tags, err := h.service.ListTags(r.Context(), userID)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, tags)
+}
+
+func (h *RecipeHandler) AddTag(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Tag string `json:"tag"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	h.organize(w, r, func(ctx context.Context, id, userID uuid.UUID) (*Recipe, error) {
+		return h.service.AddTag(ctx, id, userID, req.Tag)
+	})
+}
+
+func (h *RecipeHandler) RemoveTag(w http.ResponseWriter, r *http.Request) {
+	h.organize(w, r, func(ctx context.Context, id, userID uuid.UUID) (*Recipe, error) {
+		return h.service.RemoveTag(ctx, id, userID, chi.URLParam(r, "tag"))
+	})
+}
+
+func (h *RecipeHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	h.organize(w, r, func(ctx context.Context, id, userID uuid.UUID) (*Recipe, error) {
+		return h.service.SetFavorite(ctx, id, userID, true)
+	})
+}
+
+func (h *RecipeHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	h.organize(w, r, func(ctx context.Context, id, userID uuid.UUID) (*Recipe, error) {
+		return h.service.SetFavorite(ctx, id, userID, false)
+	})
+}
+
+// MarkCooked records that a recipe was cooked, now or at the optional
+// cooked_at of the body.
+func (h *RecipeHandler) MarkCooked(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CookedAt time.Time `json:"cooked_at"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			util.WriteError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	h.organize(w, r, func(ctx context.Context, id, userID uuid.UUID) (*Recipe, error) {
+		return h.service.MarkCooked(ctx, id, userID, req.CookedAt)
+	})
+}
+
+func (h *RecipeHandler) ClearCooked(w http.ResponseWriter, r *http.Request) {
+	h.organize(w, r, func(ctx context.Context, id, userID uuid.UUID) (*Recipe, error) {
+		return h.service.ClearCooked(ctx, id, userID)
+	})
+}
+
+// organize runs a change to one of the user's recipes named by the id URL
+// parameter and writes the changed recipe.
+func (h *RecipeHandler) organize(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id, userID uuid.UUID) (*Recipe, error)) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid recipe ID")
+		return
+	}
+
+	recipe, err := change(r.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRecipeNotFound):
+			util.WriteError(w, http.StatusNotFound, "recipe not found")
+		case errors.Is(err, ErrInvalidRecipe):
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("ETag", recipe.ETag())
+	util.WriteJSON(w, http.StatusOK, recipe)
+}
+
+// ExportRecipe downloads one of the user's recipes in ?format=markdown,
+// jsonld, html or pdf.
+func (h *RecipeHandler) ExportRecipe(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid recipe ID")
+		return
+	}
+
+	format, err := ParseExportFormat(r.URL.Query().Get("format"), false)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	export, err := h.service.ExportRecipe(r.Context(), id, userID, format)
+	if err != nil {
+		if errors.Is(err, ErrRecipeNotFound) {
+			util.WriteError(w, http.StatusNotFound, "recipe not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeExport(w, export)
+}
+
+// ExportLibrary downloads the user's recipes that match the listing
+// filters, as a zip archive unless ?format= asks for a single file.
+func (h *RecipeHandler) ExportLibrary(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromCtx(r.Context())
+	if userID == uuid.Nil {
+		util.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	filter, err := ParseRecipeFilter(r.URL.Query())
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	format, err := ParseExportFormat(r.URL.Query().Get("format"), true)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+
+	export, err := h.service.ExportLibrary(r.Context(), userID, filter, format)
+	if err != nil {
+		if errors.Is(err, ErrInvalidFilter) {
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeExport(w, export)
+}
+
+// writeExport sends an export as a download. HTML opens in the browser to
+// be printed, under a policy that keeps it from running anything.
+func writeExport(w http.ResponseWriter, export *Export) {
+	disposition := "attachment"
+	if strings.HasPrefix(export.ContentType, "text/html") {
+		disposition = "inline"
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src data:; style-src 'unsafe-inline'; sandbox")
+	}
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": export.Filename}))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if err := export.Write(w); err != nil {
+		log.Printf("write export %s: %v", export.Filename, err)
+	}
+}
@@ -0,0 +1,159 @@
+package recipe
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quantity"
+)
+
+type markdownLabels struct {
+	Ingredients  string
+	Instructions string
+	Servings     string
+	Prep         string
+	Cook         string
+	Calories     string
+}
+
+var labels = map[string]markdownLabels{
+	"en": {"Ingredients", "Instructions", "Servings", "Prep", "Cook", "Calories"},
+	"pt": {"Ingredientes", "Modo de preparo", "Porções", "Preparo", "Cozimento", "Calorias"},
+	"es": {"Ingredientes", "Preparación", "Porciones", "Preparación", "Cocción", "Calorías"},
+	"fr": {"Ingrédients", "Préparation", "Portions", "Préparation", "Cuisson", "Calories"},
+}
+
+func labelsFor(language string) markdownLabels {
+	if l, ok := labels[locale.Resolve(language).Base()]; ok {
+		return l
+	}
+	return labels["en"]
+}
+
+// RenderMarkdown renders a structured recipe as markdown.
+func RenderMarkdown(title string, calories int, d Details, language string) string {
+	l := labelsFor(language)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+
+	var meta []string
+	if d.Servings > 0 {
+		meta = append(meta, fmt.Sprintf("**%s:** %d", l.Servings, d.Servings))
+	}
+	if d.PrepTimeMinutes > 0 {
+		meta = append(meta, fmt.Sprintf("**%s:** %d min", l.Prep, d.PrepTimeMinutes))
+	}
+	if d.CookTimeMinutes > 0 {
+		meta = append(meta, fmt.Sprintf("**%s:** %d min", l.Cook, d.CookTimeMinutes))
+	}
+	if calories > 0 {
+		meta = append(meta, fmt.Sprintf("**%s:** %d kcal", l.Calories, calories))
+	}
+	if len(meta) > 0 {
+		sb.WriteString(strings.Join(meta, " · "))
+		sb.WriteString("\n\n")
+	}
+
+	fmt.Fprintf(&sb, "## %s\n", l.Ingredients)
+	for _, ing := range d.Ingredients {
+		fmt.Fprintf(&sb, "- %s\n", ing.String())
+	}
+
+	fmt.Fprintf(&sb, "\n## %s\n", l.Instructions)
+	for i, step := range d.Steps {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, step)
+	}
+
+	return sb.String()
+}
+
+// String renders the ingredient as a shopping-list line, e.g. "200 g chicken (diced)".
+func (i Ingredient) String() string {
+	var parts []string
+	if i.Quantity > 0 {
+		parts = append(parts, quantity.FormatAmount(i.Quantity))
+	}
+	if i.Unit != "" {
+		parts = append(parts, i.Unit)
+	}
+	parts = append(parts, i.Name)
+
+	line := strings.Join(parts, " ")
+	if i.Note != "" {
+		line += " (" + i.Note + ")"
+	}
+	return line
+}
+
+var (
+	ingredientHeading  = regexp.MustCompile(`(?i)^#{2,3}\s*(ingredients|ingredientes|ingrédients)(?:[\s:]|$)`)
+	instructionHeading = regexp.MustCompile(`(?i)^#{2,3}\s*(instructions|directions|method|steps|modo de preparo|preparo|instruções|preparación|elaboración|instrucciones|pasos|préparation|étapes)(?:[\s:]|$)`)
+	anyHeading         = regexp.MustCompile(`^#{1,6}\s`)
+	bulletLine         = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(.+)$`)
+	noteSuffix         = regexp.MustCompile(`^(.*?)\s*\(([^)]*)\)$`)
+)
+
+// ParseMarkdown recovers structured details from a markdown recipe with
+// "Ingredients" and "Instructions" sections. Lines it can't interpret are
+// kept as ingredient names or steps verbatim.
+func ParseMarkdown(markdown string) Details {
+	var d Details
+	section := ""
+
+	for _, raw := range strings.Split(markdown, "\n") {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "":
+			continue
+		case ingredientHeading.MatchString(line):
+			section = "ingredients"
+			continue
+		case instructionHeading.MatchString(line):
+			section = "steps"
+			continue
+		case anyHeading.MatchString(line):
+			section = ""
+			continue
+		}
+
+		m := bulletLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		switch section {
+		case "ingredients":
+			d.Ingredients = append(d.Ingredients, ParseIngredientLine(m[1]))
+		case "steps":
+			d.Steps = append(d.Steps, m[1])
+		}
+	}
+
+	return d
+}
+
+// ParseIngredientLine splits a free-form ingredient line such as
+// "1 1/2 cups flour (sifted)" into its parts.
+func ParseIngredientLine(line string) Ingredient {
+	line = strings.TrimSpace(line)
+	var ing Ingredient
+
+	if m := noteSuffix.FindStringSubmatch(line); m != nil {
+		line, ing.Note = m[1], strings.TrimSpace(m[2])
+	}
+
+	if amount, unit, rest, ok := quantity.Split(line); ok {
+		ing.Quantity, ing.Unit, line = amount, unit, rest
+	}
+
+	name := strings.TrimSpace(line)
+	if ing.Quantity > 0 {
+		for _, prefix := range []string{"of ", "de "} {
+			name = strings.TrimPrefix(name, prefix)
+		}
+	}
+	ing.Name = strings.TrimSpace(name)
+	return ing
+}
@@ -0,0 +1,48 @@
+package recipe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIngredientLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Ingredient
+	}{
+		{"200g chicken breast", Ingredient{Name: "chicken breast", Quantity: 200, Unit: "g"}},
+		{"1 1/2 cups flour (sifted)", Ingredient{Name: "flour", Quantity: 1.5, Unit: "cups", Note: "sifted"}},
+		{"2 tomatoes", Ingredient{Name: "tomatoes", Quantity: 2}},
+		{"salt to taste", Ingredient{Name: "salt to taste"}},
+		{"0,5 kg de batata", Ingredient{Name: "batata", Quantity: 0.5, Unit: "kg"}},
+		{"2 colheres de sopa de azeite", Ingredient{Name: "azeite", Quantity: 2, Unit: "colheres de sopa"}},
+		{"a pinch of salt", Ingredient{Name: "salt", Quantity: 1, Unit: "pinch"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseIngredientLine(tt.line); got != tt.want {
+			t.Errorf("ParseIngredientLine(%q) = %+v; want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	d := Details{
+		Servings: 2,
+		Ingredients: []Ingredient{
+			{Name: "rice", Quantity: 200, Unit: "g"},
+			{Name: "salt", Note: "to taste"},
+		},
+		Steps: []string{"Rinse the rice.", "Cook for 15 minutes."},
+	}
+
+	md := RenderMarkdown("Plain Rice", 350, d, "en")
+	got := ParseMarkdown(md)
+
+	if !reflect.DeepEqual(got.Ingredients, d.Ingredients) {
+		t.Errorf("ingredients = %+v; want %+v", got.Ingredients, d.Ingredients)
+	}
+	if !reflect.DeepEqual(got.Steps, d.Steps) {
+		t.Errorf("steps = %+v; want %+v", got.Steps, d.Steps)
+	}
+}
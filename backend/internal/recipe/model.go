@@ -2,8 +2,10 @@ package recipe
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/nutrition"
 	"github.com/google/uuid"
 )
 
@@ -15,8 +17,95 @@ type Recipe struct {
 	ContentMarkdown  string          `json:"content_markdown"`
 	CaloriesEstimate int             `json:"calories_estimate"`
 	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
 	IsPublic         bool            `json:"is_public"`
 	ShareToken       *string         `json:"share_token,omitempty"`
+	Language         string          `json:"language,omitempty"`
+	// PromptVersion and Model record what generated the recipe; both are
+	// empty for recipes written by hand or saved before they were tracked.
+	PromptVersion string `json:"prompt_version,omitempty"`
+	Model         string `json:"model,omitempty"`
+	// RevisionOf is the saved recipe this one was refined from.
+	RevisionOf *uuid.UUID `json:"revision_of,omitempty"`
+	// SourceURL is the page an imported recipe was read from.
+	SourceURL string `json:"source_url,omitempty"`
+	// ImageKey locates the recipe picture in blob storage; ImageURL is where
+	// clients fetch it from and changes whenever the picture does.
+	ImageKey string `json:"-"`
+	ImageURL string `json:"image_url,omitempty"`
+	// Nutrition is computed from the ingredient lines, next to the model's
+	// CaloriesEstimate; nil when no line has a quantity.
+	Nutrition *nutrition.Facts `json:"nutrition,omitempty"`
+	// ScaledFrom is the stored servings of a recipe returned scaled to
+	// another count; zero when it is returned as saved.
+	ScaledFrom   int        `json:"scaled_from,omitempty"`
+	IsFavorite   bool       `json:"is_favorite"`
+	LastCookedAt *time.Time `json:"last_cooked_at,omitempty"`
+	// CollectionIDs are the user's collections the recipe belongs to.
+	CollectionIDs []uuid.UUID `json:"collection_ids"`
+	// Highlight is an excerpt of the content around the words that matched
+	// a search, each wrapped in <mark>; empty outside search results.
+	Highlight string `json:"highlight,omitempty"`
+	Details
+}
+
+// ETag is the entity tag of the stored version of the recipe. It quotes
+// UpdatedAt as it appears in the JSON, so clients can build an If-Match
+// header from a recipe they loaded in a list.
+func (r *Recipe) ETag() string {
+	return `"` + r.UpdatedAt.Format(time.RFC3339Nano) + `"`
+}
+
+// parseETag reads the version an ETag from Recipe.ETag stands for. Weak
+// tags, which proxies may produce, name the same version.
+func parseETag(etag string) (time.Time, bool) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	unquoted, ok := strings.CutPrefix(etag, `"`)
+	if !ok {
+		return time.Time{}, false
+	}
+	unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, unquoted)
+	return t, err == nil
+}
+
+// Details is the structured part of a recipe. Recipes saved before it
+// existed only have ContentMarkdown and leave every field empty.
+type Details struct {
+	Servings        int          `json:"servings,omitempty"`
+	PrepTimeMinutes int          `json:"prep_time_minutes,omitempty"`
+	CookTimeMinutes int          `json:"cook_time_minutes,omitempty"`
+	Cuisine         string       `json:"cuisine,omitempty"`
+	Tags            []string     `json:"tags,omitempty"`
+	Ingredients     []Ingredient `json:"ingredients,omitempty"`
+	Steps           []string     `json:"steps,omitempty"`
+}
+
+// Ingredient is a single ingredient line. A zero Quantity means the amount
+// is unspecified ("salt to taste").
+type Ingredient struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity,omitempty"`
+	Unit     string  `json:"unit,omitempty"`
+	Note     string  `json:"note,omitempty"`
+}
+
+// ComputeNutrition computes the nutrition per serving from the ingredient lines.
+func (d Details) ComputeNutrition() *nutrition.Facts {
+	lines := make([]nutrition.Line, 0, len(d.Ingredients))
+	for _, ing := range d.Ingredients {
+		lines = append(lines, nutrition.Line{Name: ing.Name, Quantity: ing.Quantity, Unit: ing.Unit})
+	}
+	return nutrition.Default().Calculate(lines, d.Servings)
+}
+
+// IsStructured reports whether the recipe carries ingredient lines and steps,
+// in which case its markdown is rendered from them.
+func (d Details) IsStructured() bool {
+	return len(d.Ingredients) > 0 && len(d.Steps) > 0
 }
 
 type CreateRecipeRequest struct {
@@ -24,9 +113,36 @@ type CreateRecipeRequest struct {
 	IngredientsUsed  []string `json:"ingredients_used"`
 	ContentMarkdown  string   `json:"content_markdown"`
 	CaloriesEstimate int      `json:"calories_estimate"`
+	Language         string   `json:"language"`
+	// ParseStatus is copied from the generated recipe; degraded generations
+	// are rejected.
+	ParseStatus   string     `json:"parse_status,omitempty"`
+	PromptVersion string     `json:"prompt_version,omitempty"`
+	Model         string     `json:"model,omitempty"`
+	RevisionOf    *uuid.UUID `json:"revision_of,omitempty"`
+	SourceURL     string     `json:"source_url,omitempty"`
+	Details
+}
+
+// UpdateRecipeRequest edits a saved recipe. In a PATCH, nil fields are left
+// as they are; a PUT replaces every field, clearing the ones it leaves out.
+// Setting ContentMarkdown without ingredients or steps re-reads them from
+// the markdown.
+type UpdateRecipeRequest struct {
+	Title            *string       `json:"title"`
+	ContentMarkdown  *string       `json:"content_markdown"`
+	CaloriesEstimate *int          `json:"calories_estimate"`
+	Servings         *int          `json:"servings"`
+	PrepTimeMinutes  *int          `json:"prep_time_minutes"`
+	CookTimeMinutes  *int          `json:"cook_time_minutes"`
+	Cuisine          *string       `json:"cuisine"`
+	Tags             *[]string     `json:"tags"`
+	Ingredients      *[]Ingredient `json:"ingredients"`
+	Steps            *[]string     `json:"steps"`
 }
 
-type RecipeFilter struct {
-	MaxCalories int
-	Ingredient  string
+// TagCount is a tag and how many of the user's recipes carry it.
+type TagCount struct {
+	Tag     string `json:"tag"`
+	Recipes int    `json:"recipes"`
 }
@@ -0,0 +1,372 @@
+package recipe
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/storage"
+)
+
+// Page geometry in points; pages are A4.
+const (
+	pdfPageWidth    = 595.28
+	pdfPageHeight   = 841.89
+	pdfMargin       = 56
+	pdfContentWidth = pdfPageWidth - 2*pdfMargin
+	// pdfMaxImageHeight bounds the picture at the top of a recipe.
+	pdfMaxImageHeight = 240
+	// pdfMaxImagePixels bounds the longer side of an embedded picture;
+	// larger ones are downsampled, as print needs no more.
+	pdfMaxImagePixels = 800
+)
+
+type pdfFont int
+
+const (
+	pdfRegular pdfFont = iota
+	pdfBold
+)
+
+// helveticaWidths are the advance widths of the printable ASCII characters
+// in Helvetica and Helvetica-Bold, in thousandths of the font size, from
+// the Adobe font metrics.
+var helveticaWidths = [2][95]int{
+	{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// latinBase is the unaccented letter of each Latin-1 character from U+00C0
+// to U+00FF, close enough in width to measure it by.
+const latinBase = "AAAAAAACEEEEIIIIDNOOOOOxOUUUUYPsaaaaaaaceeeeiiiidnooooo/ouuuuypy"
+
+// winAnsi maps the characters WinAnsiEncoding places in 0x80-0x9F, where
+// it departs from Latin-1.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// pdfReplacer spells out characters WinAnsi lacks but recipes use.
+var pdfReplacer = strings.NewReplacer(
+	"⅓", "1/3", "⅔", "2/3", "⅕", "1/5", "⅛", "1/8", "⅜", "3/8", "⅝", "5/8", "⅞", "7/8",
+	"−", "-", "⁄", "/", "\u2009", " ", "\u202f", " ", "\t", " ", "\r", "", "\n", " ",
+)
+
+var pdfEscaper = strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+
+// pdfEncode converts text to WinAnsi. Other letters become "?" and other
+// symbols, such as emoji, are dropped.
+func pdfEncode(s string) string {
+	s = pdfReplacer.Replace(s)
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch b, ok := winAnsi[r]; {
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		case ok:
+			out = append(out, b)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			out = append(out, '?')
+		}
+	}
+	return string(out)
+}
+
+func charWidth(b byte, font pdfFont) int {
+	switch {
+	case b >= 0x20 && b < 0x7F:
+		return helveticaWidths[font][b-0x20]
+	case b >= 0xC0:
+		return helveticaWidths[font][latinBase[b-0xC0]-0x20]
+	default:
+		return 556
+	}
+}
+
+// textWidth measures WinAnsi text in points.
+func textWidth(s string, font pdfFont, size float64) float64 {
+	var w int
+	for i := 0; i < len(s); i++ {
+		w += charWidth(s[i], font)
+	}
+	return float64(w) * size / 1000
+}
+
+// wrapText breaks WinAnsi text into lines no wider than width, splitting
+// words that don't fit on a line of their own.
+func wrapText(s string, font pdfFont, size, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for textWidth(word, font, size) > width {
+			n := 1
+			for n < len(word) && textWidth(word[:n+1], font, size) <= width {
+				n++
+			}
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+		switch {
+		case word == "":
+		case line == "":
+			line = word
+		case textWidth(line+" "+word, font, size) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+type pdfImage struct {
+	width, height int
+	data          []byte
+}
+
+// pdfDoc lays out text in the standard Helvetica fonts, which every PDF
+// reader has, so documents need no embedded fonts. Text is written in
+// WinAnsi, which covers the languages recipes are written in.
+type pdfDoc struct {
+	title  string
+	pages  []*bytes.Buffer
+	images []pdfImage
+	page   *bytes.Buffer
+	// y is the top of the next line on the page.
+	y float64
+}
+
+func (p *pdfDoc) newPage() {
+	p.page = new(bytes.Buffer)
+	p.pages = append(p.pages, p.page)
+	p.y = pdfPageHeight - pdfMargin
+}
+
+// ensure starts a new page unless height fits above the bottom margin.
+func (p *pdfDoc) ensure(height float64) {
+	if p.page == nil || p.y-height < pdfMargin {
+		p.newPage()
+	}
+}
+
+func (p *pdfDoc) space(height float64) {
+	p.y -= height
+}
+
+func (p *pdfDoc) text(x, y float64, font pdfFont, size float64, gray bool, s string) {
+	if gray {
+		p.page.WriteString("0.4 g\n")
+	}
+	fmt.Fprintf(p.page, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font+1, pdfNum(size), pdfNum(x), pdfNum(y), pdfEscaper.Replace(s))
+	if gray {
+		p.page.WriteString("0 g\n")
+	}
+}
+
+// paragraph writes s wrapped to the content width after indent, with
+// marker, such as a bullet, in the indent of the first line.
+func (p *pdfDoc) paragraph(s string, font pdfFont, size float64, gray bool, marker string, indent float64) {
+	leading := size * 1.35
+	for i, line := range wrapText(pdfEncode(s), font, size, pdfContentWidth-indent) {
+		p.ensure(leading)
+		baseline := p.y - size
+		if i == 0 && marker != "" {
+			p.text(pdfMargin, baseline, font, size, gray, pdfEncode(marker))
+		}
+		p.text(pdfMargin+indent, baseline, font, size, gray, line)
+		p.y -= leading
+	}
+}
+
+// heading writes a section heading, underlined, on the page of the line
+// that follows it.
+func (p *pdfDoc) heading(s string) {
+	p.ensure(13*1.35 + 11*1.35 + 4)
+	p.paragraph(s, pdfBold, 13, false, "", 0)
+	fmt.Fprintf(p.page, "0.8 G 0.5 w %s %s m %s %s l S 0 G\n",
+		pdfNum(pdfMargin), pdfNum(p.y), pdfNum(pdfMargin+pdfContentWidth), pdfNum(p.y))
+	p.space(4)
+}
+
+// image draws a picture across the content width. Pictures that can't be
+// decoded, such as WebP ones, are left out.
+func (p *pdfDoc) image(obj *storage.Object) {
+	img, _, err := image.Decode(bytes.NewReader(obj.Data))
+	if err != nil {
+		return
+	}
+	b := img.Bounds()
+	step := max(1, (max(b.Dx(), b.Dy())+pdfMaxImagePixels-1)/pdfMaxImagePixels)
+	w, h := b.Dx()/step, b.Dy()/step
+	if w == 0 || h == 0 {
+		return
+	}
+	rgb := make([]byte, 0, w*h*3)
+	for y := range h {
+		for x := range w {
+			// Colors are premultiplied; adding the missing alpha lays
+			// transparent pixels over white paper.
+			r, g, bl, a := img.At(b.Min.X+x*step, b.Min.Y+y*step).RGBA()
+			rgb = append(rgb, byte((r+0xffff-a)>>8), byte((g+0xffff-a)>>8), byte((bl+0xffff-a)>>8))
+		}
+	}
+	p.images = append(p.images, pdfImage{width: w, height: h, data: deflate(rgb)})
+
+	scale := min(pdfContentWidth/float64(w), pdfMaxImageHeight/float64(h))
+	dw, dh := float64(w)*scale, float64(h)*scale
+	p.ensure(dh)
+	p.y -= dh
+	fmt.Fprintf(p.page, "q %s 0 0 %s %s %s cm /Im%d Do Q\n", pdfNum(dw), pdfNum(dh), pdfNum(pdfMargin), pdfNum(p.y), len(p.images))
+}
+
+// bytes writes out the document with page numbers in the footers.
+func (p *pdfDoc) bytes() []byte {
+	if len(p.pages) == 0 {
+		p.newPage()
+	}
+	for i, page := range p.pages {
+		p.page = page
+		footer := pdfEncode(fmt.Sprintf("%d / %d", i+1, len(p.pages)))
+		p.text((pdfPageWidth-textWidth(footer, pdfRegular, 9))/2, pdfMargin/2, pdfRegular, 9, true, footer)
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+	begin := func() {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+	}
+	object := func(body string) {
+		begin()
+		buf.WriteString(body)
+		buf.WriteString("\nendobj\n")
+	}
+	stream := func(dict string, data []byte) {
+		begin()
+		fmt.Fprintf(&buf, "<< %s /Length %d >>\nstream\n", dict, len(data))
+		buf.Write(data)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+
+	// Objects 1 to 5 are fixed; the images follow, then each page and
+	// its content.
+	firstImage := 6
+	firstPage := firstImage + len(p.images)
+	var kids, xobjects strings.Builder
+	for i := range p.pages {
+		fmt.Fprintf(&kids, " %d 0 R", firstPage+2*i)
+	}
+	for i := range p.images {
+		fmt.Fprintf(&xobjects, " /Im%d %d 0 R", i+1, firstImage+i)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s ] /Count %d >>", kids.String(), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (FridgeChef) >>", pdfEscaper.Replace(pdfEncode(p.title))))
+	for _, img := range p.images {
+		stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode",
+			img.width, img.height), img.data)
+	}
+	resources := fmt.Sprintf("<< /Font << /F1 3 0 R /F2 4 0 R >> /XObject <<%s >> >>", xobjects.String())
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			pdfNum(pdfPageWidth), pdfNum(pdfPageHeight), resources, firstPage+2*i+1))
+		stream("/Filter /FlateDecode", deflate(page.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// renderPDF lays out cards as a PDF, each starting on a new page.
+func renderPDF(title string, cards []card) []byte {
+	p := &pdfDoc{title: title}
+	for _, c := range cards {
+		p.newPage()
+		p.paragraph(c.Title, pdfBold, 20, false, "", 0)
+		if len(c.Meta) > 0 {
+			p.space(2)
+			p.paragraph(strings.Join(c.Meta, "  ·  "), pdfRegular, 10, true, "", 0)
+		}
+		if c.Image != nil {
+			p.space(8)
+			p.image(c.Image)
+		}
+		for _, section := range c.Sections {
+			p.space(10)
+			if section.Heading != "" {
+				p.heading(section.Heading)
+			}
+			for i, item := range section.Items {
+				switch {
+				case section.Numbered:
+					p.paragraph(item, pdfRegular, 11, false, strconv.Itoa(i+1)+".", 18)
+				case section.Heading != "":
+					p.paragraph(item, pdfRegular, 11, false, "•", 14)
+				default:
+					p.paragraph(item, pdfRegular, 11, false, "", 0)
+					p.space(3)
+				}
+			}
+		}
+		if len(c.Tags) > 0 {
+			p.space(10)
+			p.paragraph("#"+strings.Join(c.Tags, "  #"), pdfRegular, 10, true, "", 0)
+		}
+		if c.Source != "" {
+			p.space(4)
+			p.paragraph(c.SourceLabel+": "+c.Source, pdfRegular, 9, true, "", 0)
+		}
+	}
+	return p.bytes()
+}
+
+func pdfNum(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,138 @@
+package recipe
+
+import (
+	"bytes"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strings"
+)
+
+const (
+	placeholderWidth  = 800
+	placeholderHeight = 600
+	// maxPlaceholderItems caps the ingredients drawn on the plate.
+	maxPlaceholderItems = 10
+)
+
+// ingredientColors gives common ingredients a recognizable color; others
+// get one derived from their name.
+var ingredientColors = []struct {
+	word  string
+	color color.RGBA
+}{
+	{"tomato", color.RGBA{214, 48, 49, 255}},
+	{"pepper", color.RGBA{225, 82, 61, 255}},
+	{"carrot", color.RGBA{240, 130, 40, 255}},
+	{"pumpkin", color.RGBA{235, 140, 50, 255}},
+	{"salmon", color.RGBA{250, 128, 114, 255}},
+	{"egg", color.RGBA{250, 210, 80, 255}},
+	{"cheese", color.RGBA{247, 200, 90, 255}},
+	{"corn", color.RGBA{245, 205, 70, 255}},
+	{"rice", color.RGBA{245, 240, 225, 255}},
+	{"pasta", color.RGBA{238, 214, 160, 255}},
+	{"potato", color.RGBA{222, 190, 130, 255}},
+	{"chicken", color.RGBA{214, 170, 110, 255}},
+	{"beef", color.RGBA{130, 70, 50, 255}},
+	{"mushroom", color.RGBA{160, 125, 95, 255}},
+	{"spinach", color.RGBA{60, 130, 60, 255}},
+	{"broccoli", color.RGBA{70, 140, 60, 255}},
+	{"basil", color.RGBA{80, 150, 70, 255}},
+	{"avocado", color.RGBA{140, 180, 80, 255}},
+	{"bean", color.RGBA{120, 60, 50, 255}},
+	{"onion", color.RGBA{200, 160, 190, 255}},
+}
+
+// renderPlaceholder draws the offline stand-in for a recipe picture: a plate
+// on a cloth colored by the title, with a dab of color per ingredient. The
+// same recipe always gets the same image.
+func renderPlaceholder(title string, ingredients []string) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, placeholderWidth, placeholderHeight))
+
+	seed := hashString(strings.ToLower(title))
+	cloth := hsvColor(float64(seed%360), 0.25, 0.92)
+	stripe := hsvColor(float64(seed%360), 0.35, 0.85)
+	draw.Draw(img, img.Bounds(), &image.Uniform{cloth}, image.Point{}, draw.Src)
+	for x := int(seed % 60); x < placeholderWidth; x += 120 {
+		draw.Draw(img, image.Rect(x, 0, x+50, placeholderHeight), &image.Uniform{stripe}, image.Point{}, draw.Src)
+	}
+
+	cx, cy := placeholderWidth/2, placeholderHeight/2
+	fillCircle(img, cx+10, cy+14, 240, color.RGBA{0, 0, 0, 40})
+	fillCircle(img, cx, cy, 240, color.RGBA{228, 228, 228, 255})
+	fillCircle(img, cx, cy, 200, color.RGBA{252, 252, 250, 255})
+
+	if len(ingredients) > maxPlaceholderItems {
+		ingredients = ingredients[:maxPlaceholderItems]
+	}
+	for i, name := range ingredients {
+		h := hashString(strings.ToLower(strings.TrimSpace(name)))
+		angle := 2*math.Pi*float64(i)/float64(len(ingredients)) + float64(seed%100)/100
+		distance := 40 + float64(h%90)
+		if len(ingredients) == 1 {
+			distance = 0
+		}
+		radius := 35 + int(h>>8%25)
+		x := cx + int(distance*math.Cos(angle))
+		y := cy + int(distance*math.Sin(angle))
+		fillCircle(img, x, y, radius, ingredientColor(name, h))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func ingredientColor(name string, h uint32) color.RGBA {
+	lower := strings.ToLower(name)
+	for _, c := range ingredientColors {
+		if strings.Contains(lower, c.word) {
+			return c.color
+		}
+	}
+	return hsvColor(float64(h%360), 0.55, 0.8)
+}
+
+// fillCircle blends c over the disc of radius r centered at (cx, cy).
+func fillCircle(img *image.RGBA, cx, cy, r int, c color.RGBA) {
+	src := &image.Uniform{c}
+	for y := max(cy-r, 0); y <= min(cy+r, placeholderHeight-1); y++ {
+		dy := y - cy
+		half := int(math.Sqrt(float64(r*r - dy*dy)))
+		line := image.Rect(cx-half, y, cx+half+1, y+1)
+		draw.Draw(img, line, src, image.Point{}, draw.Over)
+	}
+}
+
+func hsvColor(h, s, v float64) color.RGBA {
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.RGBA{uint8((r + m) * 255), uint8((g + m) * 255), uint8((b + m) * 255), 255}
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
@@ -0,0 +1,29 @@
+package recipe
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestRenderPlaceholder(t *testing.T) {
+	a, err := renderPlaceholder("Tomato Soup", []string{"tomato", "onion", "basil"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := renderPlaceholder("Tomato Soup", []string{"tomato", "onion", "basil"})
+	if !bytes.Equal(a, b) {
+		t.Error("same recipe rendered two different images")
+	}
+	if c, _ := renderPlaceholder("Mushroom Risotto", []string{"rice", "mushroom"}); bytes.Equal(a, c) {
+		t.Error("different recipes rendered the same image")
+	}
+
+	img, err := png.Decode(bytes.NewReader(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != placeholderWidth || size.Y != placeholderHeight {
+		t.Errorf("size = %v", size)
+	}
+}
@@ -3,7 +3,10 @@ package recipe
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -16,52 +19,254 @@ func NewRecipeRepository(db *sql.DB) *RecipeRepository {
 	return &RecipeRepository{db: db}
 }
 
+const recipeColumns = `id, user_id, title, ingredients_used, content_markdown, calories_estimate, created_at, is_public, share_token,
+		language, COALESCE(servings, 0), COALESCE(prep_time_minutes, 0), COALESCE(cook_time_minutes, 0), COALESCE(cuisine, ''),
+		COALESCE(prompt_version, ''), COALESCE(model, ''), revision_of, COALESCE(image_key, ''), nutrition, updated_at,
+		is_favorite, last_cooked_at, COALESCE(source_url, '')`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRecipe scans the recipeColumns of a row into a recipe and any further
+// columns into extra.
+func scanRecipe(row rowScanner, extra ...any) (*Recipe, error) {
+	var recipe Recipe
+	var nutritionJSON []byte
+	dest := []any{
+		&recipe.ID,
+		&recipe.UserID,
+		&recipe.Title,
+		&recipe.IngredientsUsed,
+		&recipe.ContentMarkdown,
+		&recipe.CaloriesEstimate,
+		&recipe.CreatedAt,
+		&recipe.IsPublic,
+		&recipe.ShareToken,
+		&recipe.Language,
+		&recipe.Servings,
+		&recipe.PrepTimeMinutes,
+		&recipe.CookTimeMinutes,
+		&recipe.Cuisine,
+		&recipe.PromptVersion,
+		&recipe.Model,
+		&recipe.RevisionOf,
+		&recipe.ImageKey,
+		&nutritionJSON,
+		&recipe.UpdatedAt,
+		&recipe.IsFavorite,
+		&recipe.LastCookedAt,
+		&recipe.SourceURL,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if nutritionJSON != nil {
+		if err := json.Unmarshal(nutritionJSON, &recipe.Nutrition); err != nil {
+			return nil, fmt.Errorf("decode nutrition: %w", err)
+		}
+	}
+	return &recipe, nil
+}
+
 func (r *RecipeRepository) CreateRecipe(ctx context.Context, recipe *Recipe) (*Recipe, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var nutritionJSON []byte
+	if recipe.Nutrition != nil {
+		if nutritionJSON, err = json.Marshal(recipe.Nutrition); err != nil {
+			return nil, fmt.Errorf("encode nutrition: %w", err)
+		}
+	}
+
 	query := `
-		INSERT INTO recipes (user_id, title, ingredients_used, content_markdown, calories_estimate)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at
+		INSERT INTO recipes (user_id, title, ingredients_used, content_markdown, calories_estimate,
+			language, servings, prep_time_minutes, cook_time_minutes, cuisine, prompt_version, model, revision_of, nutrition, source_url)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), NULLIF($8, 0), NULLIF($9, 0), NULLIF($10, ''), NULLIF($11, ''), NULLIF($12, ''), $13, $14, NULLIF($15, ''))
+		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		recipe.UserID,
 		recipe.Title,
 		recipe.IngredientsUsed,
 		recipe.ContentMarkdown,
 		recipe.CaloriesEstimate,
-	).Scan(&recipe.ID, &recipe.CreatedAt)
+		recipe.Language,
+		recipe.Servings,
+		recipe.PrepTimeMinutes,
+		recipe.CookTimeMinutes,
+		recipe.Cuisine,
+		recipe.PromptVersion,
+		recipe.Model,
+		recipe.RevisionOf,
+		nutritionJSON,
+		recipe.SourceURL,
+	).Scan(&recipe.ID, &recipe.CreatedAt, &recipe.UpdatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("create recipe: %w", err)
 	}
 
+	if err := insertDetails(ctx, tx, recipe.ID, recipe.Details); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit recipe: %w", err)
+	}
+
 	return recipe, nil
 }
 
-func (r *RecipeRepository) ListRecipes(ctx context.Context, userID uuid.UUID, filter RecipeFilter) ([]*Recipe, error) {
-	query := `
-		SELECT id, user_id, title, ingredients_used, content_markdown, calories_estimate, created_at, is_public, share_token
-		FROM recipes
-		WHERE user_id = $1
-	`
-	args := []interface{}{userID}
-	argCount := 1
+func insertDetails(ctx context.Context, tx *sql.Tx, recipeID uuid.UUID, d Details) error {
+	for i, ing := range d.Ingredients {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO recipe_ingredients (recipe_id, position, name, quantity, unit, note)
+			VALUES ($1, $2, $3, NULLIF($4::numeric, 0), $5, $6)
+		`, recipeID, i, ing.Name, ing.Quantity, ing.Unit, ing.Note)
+		if err != nil {
+			return fmt.Errorf("insert recipe ingredient: %w", err)
+		}
+	}
+
+	for i, step := range d.Steps {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO recipe_steps (recipe_id, position, instruction)
+			VALUES ($1, $2, $3)
+		`, recipeID, i, step)
+		if err != nil {
+			return fmt.Errorf("insert recipe step: %w", err)
+		}
+	}
+
+	for _, tag := range d.Tags {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO recipe_tags (recipe_id, tag)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, recipeID, tag)
+		if err != nil {
+			return fmt.Errorf("insert recipe tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// searchHeadlineOptions configures the excerpt returned with search results.
+const searchHeadlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20, FragmentDelimiter=" … "`
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 
+// sortKeys are the expression each sort orders by and its SQL type, which
+// a cursor's key is cast back to.
+var sortKeys = map[Sort][2]string{
+	SortCreatedAt: {"created_at", "timestamptz"},
+	SortTitle:     {"lower(title)", "text"},
+	SortCalories:  {"calories_estimate", "int"},
+	// Recipes never cooked sort as if cooked at the dawn of time.
+	SortLastCooked: {"COALESCE(last_cooked_at, '-infinity')", "timestamptz"},
+	// Relevance is filled in by ListRecipes, since it depends on the query.
+	SortRelevance: {"", "float8"},
+}
+
+// ListRecipes returns a page of the user's recipes in the filter's order,
+// resuming after its cursor. A search query matches full text over title,
+// ingredients and content, stemmed for each recipe's language, plus
+// trigram similarity so misspellings still match titles and ingredients.
+func (r *RecipeRepository) ListRecipes(ctx context.Context, userID uuid.UUID, filter RecipeFilter) (*RecipePage, error) {
+	args := []any{userID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	key, keyType := sortKeys[filter.Sort][0], sortKeys[filter.Sort][1]
+	columns, from := recipeColumns, "recipes"
+	where := []string{"user_id = $1"}
+
+	if filter.Query != "" {
+		q := arg(filter.Query)
+		columns += `, ts_headline(recipe_search_config(language), content_markdown, query, '` + searchHeadlineOptions + `')`
+		from += `, websearch_to_tsquery(recipe_search_config(language), ` + q + `) AS query`
+		where = append(where, `(search_vector @@ query OR lower(`+q+`) <% lower(title) OR lower(`+q+`) <% lower(ingredients_used::text))`)
+		if filter.Sort == SortRelevance {
+			key = `(ts_rank_cd(search_vector, query) + word_similarity(lower(` + q + `), lower(title)))::float8`
+		}
+	}
+	columns += `, (` + key + `)::text`
+
+	if filter.MinCalories > 0 {
+		where = append(where, "calories_estimate >= "+arg(filter.MinCalories))
+	}
 	if filter.MaxCalories > 0 {
-		argCount++
-		query += fmt.Sprintf(" AND calories_estimate <= $%d", argCount)
-		args = append(args, filter.MaxCalories)
+		where = append(where, "calories_estimate <= "+arg(filter.MaxCalories))
 	}
 
-	if filter.Ingredient != "" {
-		argCount++
-		// Assuming ingredients_used is JSONB. We construct a JSON array string for containment check.
-		query += fmt.Sprintf(" AND ingredients_used @> $%d::jsonb", argCount)
-		jsonArg := fmt.Sprintf(`["%s"]`, filter.Ingredient)
-		args = append(args, jsonArg)
+	if len(filter.Ingredients) > 0 {
+		var uses []string
+		for _, ing := range filter.Ingredients {
+			uses = append(uses, usesIngredient(arg(likeEscaper.Replace(ing))))
+		}
+		joiner := " AND "
+		if filter.AnyIngredient {
+			joiner = " OR "
+		}
+		where = append(where, "("+strings.Join(uses, joiner)+")")
+	}
+	for _, ing := range filter.ExcludeIngredients {
+		where = append(where, "NOT "+usesIngredient(arg(likeEscaper.Replace(ing))))
 	}
 
-	query += " ORDER BY created_at DESC"
+	if len(filter.Tags) > 0 {
+		tags := make([]string, len(filter.Tags))
+		for i, tag := range filter.Tags {
+			tags[i] = strings.ToLower(tag)
+		}
+		where = append(where, `(
+			SELECT count(DISTINCT lower(tag)) FROM recipe_tags
+			WHERE recipe_id = recipes.id AND lower(tag) = ANY(`+arg(tags)+`::text[])
+		) = `+arg(len(uniqueStrings(tags))))
+	}
+
+	if filter.Favorite {
+		where = append(where, "is_favorite")
+	}
+	if filter.Collection != uuid.Nil {
+		where = append(where, `EXISTS (
+			SELECT 1 FROM collection_recipes cr
+			WHERE cr.recipe_id = recipes.id AND cr.collection_id = `+arg(filter.Collection)+`
+		)`)
+	}
+
+	if !filter.CreatedFrom.IsZero() {
+		where = append(where, "created_at >= "+arg(filter.CreatedFrom))
+	}
+	if !filter.CreatedTo.IsZero() {
+		where = append(where, "created_at < "+arg(filter.CreatedTo))
+	}
+
+	direction, after := "ASC", ">"
+	if filter.Descending {
+		direction, after = "DESC", "<"
+	}
+	if filter.after != nil {
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s::%s, %s)", key, after, arg(filter.after.Key), keyType, arg(filter.after.ID)))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	query := `SELECT ` + columns + ` FROM ` + from + ` WHERE ` + strings.Join(where, " AND ") +
+		fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", key, direction, direction, arg(limit+1))
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -69,108 +274,484 @@ func (r *RecipeRepository) ListRecipes(ctx context.Context, userID uuid.UUID, fi
 	}
 	defer rows.Close()
 
-	var recipes []*Recipe = []*Recipe{}
+	page := &RecipePage{Recipes: []*Recipe{}}
+	var keys []string
 	for rows.Next() {
-		var recipe Recipe
-		if err := rows.Scan(
-			&recipe.ID,
-			&recipe.UserID,
-			&recipe.Title,
-			&recipe.IngredientsUsed,
-			&recipe.ContentMarkdown,
-			&recipe.CaloriesEstimate,
-			&recipe.CreatedAt,
-			&recipe.IsPublic,
-			&recipe.ShareToken,
-		); err != nil {
+		var recipe *Recipe
+		var sortKey string
+		if filter.Query != "" {
+			var highlight string
+			recipe, err = scanRecipe(rows, &highlight, &sortKey)
+			if err == nil {
+				recipe.Highlight = highlight
+			}
+		} else {
+			recipe, err = scanRecipe(rows, &sortKey)
+		}
+		if err != nil {
 			return nil, fmt.Errorf("scan recipe: %w", err)
 		}
-		recipes = append(recipes, &recipe)
+		page.Recipes = append(page.Recipes, recipe)
+		keys = append(keys, sortKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list recipes: %w", err)
 	}
 
-	return recipes, nil
+	if len(page.Recipes) > limit {
+		page.Recipes = page.Recipes[:limit]
+		last := page.Recipes[limit-1]
+		page.NextCursor = cursor{Sort: filter.Sort, Descending: filter.Descending, Key: keys[limit-1], ID: last.ID}.encode()
+	}
+
+	if err := r.loadDetails(ctx, page.Recipes); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// usesIngredient is a condition matching recipes with an ingredient whose
+// name contains the escaped pattern in param.
+func usesIngredient(param string) string {
+	return `EXISTS (
+		SELECT 1 FROM jsonb_array_elements_text(ingredients_used) AS used
+		WHERE used ILIKE '%' || ` + param + ` || '%'
+	)`
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
 }
 
 func (r *RecipeRepository) UpdateRecipe(ctx context.Context, recipe *Recipe) error {
 	query := `
 		UPDATE recipes
-		SET is_public = $1, share_token = $2
+		SET is_public = $1, share_token = $2, updated_at = NOW()
 		WHERE id = $3 AND user_id = $4
+		RETURNING updated_at
 	`
-	_, err := r.db.ExecContext(ctx, query, recipe.IsPublic, recipe.ShareToken, recipe.ID, recipe.UserID)
+	err := r.db.QueryRowContext(ctx, query, recipe.IsPublic, recipe.ShareToken, recipe.ID, recipe.UserID).Scan(&recipe.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("update recipe: %w", err)
 	}
 	return nil
 }
 
+// EditRecipe stores the editable fields and details of a recipe. When
+// version is set the edit only applies if the recipe wasn't updated since;
+// otherwise ErrVersionConflict is returned.
+func (r *RecipeRepository) EditRecipe(ctx context.Context, recipe *Recipe, version *time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var nutritionJSON []byte
+	if recipe.Nutrition != nil {
+		if nutritionJSON, err = json.Marshal(recipe.Nutrition); err != nil {
+			return fmt.Errorf("encode nutrition: %w", err)
+		}
+	}
+
+	query := `
+		UPDATE recipes
+		SET title = $1, content_markdown = $2, calories_estimate = $3, servings = NULLIF($4, 0),
+			prep_time_minutes = NULLIF($5, 0), cook_time_minutes = NULLIF($6, 0), cuisine = NULLIF($7, ''),
+			nutrition = $8, ingredients_used = $9, updated_at = NOW()
+		WHERE id = $10 AND user_id = $11 AND ($12::timestamptz IS NULL OR updated_at = $12)
+		RETURNING updated_at
+	`
+	err = tx.QueryRowContext(ctx, query,
+		recipe.Title,
+		recipe.ContentMarkdown,
+		recipe.CaloriesEstimate,
+		recipe.Servings,
+		recipe.PrepTimeMinutes,
+		recipe.CookTimeMinutes,
+		recipe.Cuisine,
+		nutritionJSON,
+		recipe.IngredientsUsed,
+		recipe.ID,
+		recipe.UserID,
+		version,
+	).Scan(&recipe.UpdatedAt)
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM recipes WHERE id = $1 AND user_id = $2)`,
+			recipe.ID, recipe.UserID).Scan(&exists); err != nil {
+			return fmt.Errorf("edit recipe: %w", err)
+		}
+		if exists {
+			return ErrVersionConflict
+		}
+		return ErrRecipeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("edit recipe: %w", err)
+	}
+
+	for _, table := range []string{"recipe_ingredients", "recipe_steps", "recipe_tags"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE recipe_id = $1`, recipe.ID); err != nil {
+			return fmt.Errorf("clear %s: %w", table, err)
+		}
+	}
+	if err := insertDetails(ctx, tx, recipe.ID, recipe.Details); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit recipe: %w", err)
+	}
+	return nil
+}
+
+// GetRevisions returns the revision chain of a recipe, from the original to
+// the recipe itself.
+func (r *RecipeRepository) GetRevisions(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]*Recipe, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT recipes.*, 0 AS depth FROM recipes WHERE id = $1 AND user_id = $2
+			UNION ALL
+			SELECT parent.*, chain.depth + 1
+			FROM recipes parent
+			JOIN chain ON parent.id = chain.revision_of
+			WHERE parent.user_id = $2 AND chain.depth < $3
+		)
+		SELECT ` + recipeColumns + `
+		FROM chain
+		ORDER BY depth DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, id, userID, maxRevisionDepth)
+	if err != nil {
+		return nil, fmt.Errorf("get revisions: %w", err)
+	}
+	defer rows.Close()
+
+	recipes := []*Recipe{}
+	for rows.Next() {
+		recipe, err := scanRecipe(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan recipe: %w", err)
+		}
+		recipes = append(recipes, recipe)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get revisions: %w", err)
+	}
+
+	if err := r.loadDetails(ctx, recipes); err != nil {
+		return nil, err
+	}
+	return recipes, nil
+}
+
 func (r *RecipeRepository) GetRecipeByToken(ctx context.Context, token string) (*Recipe, error) {
 	query := `
-		SELECT id, user_id, title, ingredients_used, content_markdown, calories_estimate, created_at, is_public, share_token
+		SELECT ` + recipeColumns + `
 		FROM recipes
 		WHERE share_token = $1 AND is_public = true
 	`
-	var recipe Recipe
-	err := r.db.QueryRowContext(ctx, query, token).Scan(
-		&recipe.ID,
-		&recipe.UserID,
-		&recipe.Title,
-		&recipe.IngredientsUsed,
-		&recipe.ContentMarkdown,
-		&recipe.CaloriesEstimate,
-		&recipe.CreatedAt,
-		&recipe.IsPublic,
-		&recipe.ShareToken,
-	)
+	recipe, err := scanRecipe(r.db.QueryRowContext(ctx, query, token))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("get recipe by token: %w", err)
 	}
-	return &recipe, nil
+	if err := r.loadDetails(ctx, []*Recipe{recipe}); err != nil {
+		return nil, err
+	}
+	return recipe, nil
 }
 
 func (r *RecipeRepository) GetRecipeByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Recipe, error) {
 	query := `
-		SELECT id, user_id, title, ingredients_used, content_markdown, calories_estimate, created_at, is_public, share_token
+		SELECT ` + recipeColumns + `
 		FROM recipes
 		WHERE id = $1 AND user_id = $2
 	`
-	var recipe Recipe
-	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(
-		&recipe.ID,
-		&recipe.UserID,
-		&recipe.Title,
-		&recipe.IngredientsUsed,
-		&recipe.ContentMarkdown,
-		&recipe.CaloriesEstimate,
-		&recipe.CreatedAt,
-		&recipe.IsPublic,
-		&recipe.ShareToken,
-	)
+	recipe, err := scanRecipe(r.db.QueryRowContext(ctx, query, id, userID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("get recipe by id: %w", err)
 	}
-	return &recipe, nil
+	if err := r.loadDetails(ctx, []*Recipe{recipe}); err != nil {
+		return nil, err
+	}
+	return recipe, nil
+}
+
+// DeleteRecipe deletes a recipe and returns the key of its image, if any.
+func (r *RecipeRepository) DeleteRecipe(ctx context.Context, id uuid.UUID, userID uuid.UUID) (string, error) {
+	query := `DELETE FROM recipes WHERE id = $1 AND user_id = $2 RETURNING COALESCE(image_key, '')`
+	var imageKey string
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(&imageKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("recipe not found or unauthorized")
+		}
+		return "", fmt.Errorf("delete recipe: %w", err)
+	}
+	return imageKey, nil
+}
+
+// SetImage points a recipe at a new image and returns the key it replaced.
+func (r *RecipeRepository) SetImage(ctx context.Context, id uuid.UUID, userID uuid.UUID, key string) (string, error) {
+	query := `
+		UPDATE recipes r
+		SET image_key = $1, updated_at = NOW()
+		FROM recipes prev
+		WHERE r.id = prev.id AND r.id = $2 AND r.user_id = $3
+		RETURNING COALESCE(prev.image_key, '')
+	`
+	var previous string
+	err := r.db.QueryRowContext(ctx, query, key, id, userID).Scan(&previous)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrRecipeNotFound
+		}
+		return "", fmt.Errorf("set recipe image: %w", err)
+	}
+	return previous, nil
+}
+
+// SetFavorite marks or unmarks a recipe as a favorite.
+func (r *RecipeRepository) SetFavorite(ctx context.Context, id uuid.UUID, userID uuid.UUID, favorite bool) error {
+	return r.touch(ctx, "set favorite", `is_favorite = $3`, id, userID, favorite)
+}
+
+// SetLastCooked records when a recipe was last cooked; nil clears it.
+func (r *RecipeRepository) SetLastCooked(ctx context.Context, id uuid.UUID, userID uuid.UUID, at *time.Time) error {
+	return r.touch(ctx, "set last cooked", `last_cooked_at = $3`, id, userID, at)
 }
 
-func (r *RecipeRepository) DeleteRecipe(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	query := `DELETE FROM recipes WHERE id = $1 AND user_id = $2`
-	result, err := r.db.ExecContext(ctx, query, id, userID)
+// touch applies an assignment to one of the user's recipes and bumps its
+// updated_at, since every field is part of the recipe's ETag.
+func (r *RecipeRepository) touch(ctx context.Context, op, assignment string, id uuid.UUID, userID uuid.UUID, args ...any) error {
+	query := `UPDATE recipes SET ` + assignment + `, updated_at = NOW() WHERE id = $1 AND user_id = $2`
+	result, err := r.db.ExecContext(ctx, query, append([]any{id, userID}, args...)...)
 	if err != nil {
-		return fmt.Errorf("delete recipe: %w", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	} else if n == 0 {
+		return ErrRecipeNotFound
 	}
+	return nil
+}
 
-	rows, err := result.RowsAffected()
+// AddTag tags one of the user's recipes; tagging twice is a no-op.
+func (r *RecipeRepository) AddTag(ctx context.Context, id uuid.UUID, userID uuid.UUID, tag string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("rows affected: %w", err)
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchTx(ctx, tx, id, userID); err != nil {
+		return err
 	}
-	if rows == 0 {
-		return fmt.Errorf("recipe not found or unauthorized")
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO recipe_tags (recipe_id, tag)
+		SELECT $1, $2
+		WHERE NOT EXISTS (SELECT 1 FROM recipe_tags WHERE recipe_id = $1 AND lower(tag) = lower($2))
+	`, id, tag)
+	if err != nil {
+		return fmt.Errorf("add recipe tag: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RemoveTag untags one of the user's recipes.
+func (r *RecipeRepository) RemoveTag(ctx context.Context, id uuid.UUID, userID uuid.UUID, tag string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchTx(ctx, tx, id, userID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM recipe_tags WHERE recipe_id = $1 AND lower(tag) = lower($2)`, id, tag)
+	if err != nil {
+		return fmt.Errorf("remove recipe tag: %w", err)
+	}
+	return tx.Commit()
+}
+
+func touchTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, userID uuid.UUID) error {
+	result, err := tx.ExecContext(ctx, `UPDATE recipes SET updated_at = NOW() WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("touch recipe: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("touch recipe: %w", err)
+	} else if n == 0 {
+		return ErrRecipeNotFound
+	}
+	return nil
+}
+
+// ListTags returns the tags on the user's recipes, most used first.
+func (r *RecipeRepository) ListTags(ctx context.Context, userID uuid.UUID) ([]TagCount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT lower(t.tag), count(*)
+		FROM recipe_tags t
+		JOIN recipes r ON r.id = t.recipe_id
+		WHERE r.user_id = $1
+		GROUP BY lower(t.tag)
+		ORDER BY count(*) DESC, lower(t.tag)
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []TagCount{}
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Recipes); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags = append(tags, tc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// loadDetails fills the structured ingredients, steps and tags of recipes and
+// re-renders the markdown of the ones that are structured.
+func (r *RecipeRepository) loadDetails(ctx context.Context, recipes []*Recipe) error {
+	if len(recipes) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*Recipe, len(recipes))
+	ids := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		byID[recipe.ID] = recipe
+		ids = append(ids, recipe.ID.String())
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT recipe_id, name, COALESCE(quantity, 0), COALESCE(unit, ''), COALESCE(note, '')
+		FROM recipe_ingredients
+		WHERE recipe_id = ANY($1::uuid[])
+		ORDER BY recipe_id, position
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("load recipe ingredients: %w", err)
+	}
+	for rows.Next() {
+		var recipeID uuid.UUID
+		var ing Ingredient
+		if err := rows.Scan(&recipeID, &ing.Name, &ing.Quantity, &ing.Unit, &ing.Note); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan recipe ingredient: %w", err)
+		}
+		byID[recipeID].Ingredients = append(byID[recipeID].Ingredients, ing)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load recipe ingredients: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT recipe_id, instruction
+		FROM recipe_steps
+		WHERE recipe_id = ANY($1::uuid[])
+		ORDER BY recipe_id, position
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("load recipe steps: %w", err)
+	}
+	for rows.Next() {
+		var recipeID uuid.UUID
+		var step string
+		if err := rows.Scan(&recipeID, &step); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan recipe step: %w", err)
+		}
+		byID[recipeID].Steps = append(byID[recipeID].Steps, step)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load recipe steps: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT recipe_id, tag
+		FROM recipe_tags
+		WHERE recipe_id = ANY($1::uuid[])
+		ORDER BY recipe_id, tag
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("load recipe tags: %w", err)
+	}
+	for rows.Next() {
+		var recipeID uuid.UUID
+		var tag string
+		if err := rows.Scan(&recipeID, &tag); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan recipe tag: %w", err)
+		}
+		byID[recipeID].Tags = append(byID[recipeID].Tags, tag)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load recipe tags: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT cr.recipe_id, cr.collection_id
+		FROM collection_recipes cr
+		JOIN collections c ON c.id = cr.collection_id
+		WHERE cr.recipe_id = ANY($1::uuid[])
+		ORDER BY cr.recipe_id, lower(c.name)
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("load recipe collections: %w", err)
+	}
+	for rows.Next() {
+		var recipeID, collectionID uuid.UUID
+		if err := rows.Scan(&recipeID, &collectionID); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan recipe collection: %w", err)
+		}
+		byID[recipeID].CollectionIDs = append(byID[recipeID].CollectionIDs, collectionID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load recipe collections: %w", err)
+	}
+
+	for _, recipe := range recipes {
+		if recipe.CollectionIDs == nil {
+			recipe.CollectionIDs = []uuid.UUID{}
+		}
+		if recipe.IsStructured() {
+			recipe.ContentMarkdown = RenderMarkdown(recipe.Title, recipe.CaloriesEstimate, recipe.Details, recipe.Language)
+		}
+		// Recipes saved before nutrition was computed get it on the fly.
+		if recipe.Nutrition == nil {
+			recipe.Nutrition = recipe.ComputeNutrition()
+		}
 	}
 
 	return nil
@@ -0,0 +1,130 @@
+package recipe
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// newTestDB starts Postgres with the migrations applied. The test is
+// skipped where Docker isn't available.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("needs Postgres")
+	}
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	container, err := postgres.Run(ctx,
+		"postgres:latest",
+		postgres.WithDatabase("recipes"),
+		postgres.WithUsername("user"),
+		postgres.WithPassword("password"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(30*time.Second)),
+	)
+	if err != nil {
+		t.Fatalf("could not start postgres container: %v", err)
+	}
+	t.Cleanup(func() { container.Terminate(context.Background()) })
+
+	dsn, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// Apply the Up half of each goose migration, in order.
+	files, err := filepath.Glob("../database/migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		if _, err := db.ExecContext(ctx, up); err != nil {
+			t.Fatalf("migrate %s: %v", filepath.Base(file), err)
+		}
+	}
+	return db
+}
+
+func TestEditRecipeIngredientsUsed(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	var userID uuid.UUID
+	if err := db.QueryRowContext(ctx,
+		`INSERT INTO users (username, email, password_hash) VALUES ('cook', 'cook@example.com', 'x') RETURNING id`,
+	).Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewRecipeService(NewRecipeRepository(db), nil, nil)
+	created, err := s.CreateRecipe(ctx, userID, CreateRecipeRequest{
+		Title:    "Stir fry",
+		Language: "en",
+		Details: Details{
+			Ingredients: []Ingredient{{Name: "chicken", Quantity: 200, Unit: "g"}},
+			Steps:       []string{"Fry everything."},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ingredients := []Ingredient{{Name: "tofu", Quantity: 200, Unit: "g"}}
+	edited, err := s.UpdateRecipe(ctx, created.ID, userID, UpdateRecipeRequest{Ingredients: &ingredients}, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(edited.IngredientsUsed) != `["tofu"]` {
+		t.Errorf("ingredients_used = %s; want [\"tofu\"]", edited.IngredientsUsed)
+	}
+
+	tests := []struct {
+		name   string
+		filter RecipeFilter
+		want   int
+	}{
+		{"filter by new ingredient", RecipeFilter{Ingredients: []string{"tofu"}}, 1},
+		{"filter by old ingredient", RecipeFilter{Ingredients: []string{"chicken"}}, 0},
+		{"exclude new ingredient", RecipeFilter{ExcludeIngredients: []string{"tofu"}}, 0},
+		{"search new ingredient", RecipeFilter{Query: "tofu", Sort: SortRelevance, Descending: true}, 1},
+		{"search old ingredient", RecipeFilter{Query: "chicken", Sort: SortRelevance, Descending: true}, 0},
+	}
+	for _, tt := range tests {
+		filter := tt.filter
+		filter.Limit = DefaultPageSize
+		if filter.Sort == "" {
+			filter.Sort, filter.Descending = SortCreatedAt, true
+		}
+		page, err := s.ListRecipes(ctx, userID, filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(page.Recipes) != tt.want {
+			t.Errorf("%s: got %d recipes; want %d", tt.name, len(page.Recipes), tt.want)
+		}
+	}
+}
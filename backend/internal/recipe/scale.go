@@ -0,0 +1,66 @@
+package recipe
+
+import (
+	"errors"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quantity"
+)
+
+// MaxServings bounds the servings a recipe can be scaled to.
+const MaxServings = 100
+
+// ErrNotScalable is returned when scaling a recipe that doesn't record how
+// many servings it makes or has no ingredient lines to scale.
+var ErrNotScalable = errors.New("recipe has no servings or ingredients to scale from")
+
+// Scale returns a copy of r adjusted to make servings. Ingredient amounts
+// are rounded and moved to a better unit where one reads more naturally,
+// and the markdown and nutrition are recomputed from them.
+func Scale(r *Recipe, servings int) (*Recipe, error) {
+	if r.Servings <= 0 || len(r.Ingredients) == 0 {
+		return nil, ErrNotScalable
+	}
+	if servings == r.Servings {
+		return r, nil
+	}
+
+	factor := float64(servings) / float64(r.Servings)
+	language := locale.Resolve(r.Language).Base()
+
+	scaled := *r
+	scaled.Ingredients = make([]Ingredient, len(r.Ingredients))
+	for i, ing := range r.Ingredients {
+		scaled.Ingredients[i] = scaleIngredient(ing, factor, language)
+	}
+	scaled.Servings = servings
+	scaled.ScaledFrom = r.Servings
+	scaled.Nutrition = scaled.ComputeNutrition()
+	if scaled.IsStructured() {
+		scaled.ContentMarkdown = RenderMarkdown(scaled.Title, scaled.CaloriesEstimate, scaled.Details, scaled.Language)
+	}
+	return &scaled, nil
+}
+
+func scaleIngredient(ing Ingredient, factor float64, language string) Ingredient {
+	if ing.Quantity <= 0 {
+		return ing
+	}
+	u, ok := quantity.LookupUnit(ing.Unit)
+	if !ok {
+		// A unit we don't know, like "handful", is scaled in place.
+		ing.Quantity = quantity.Round(ing.Quantity*factor, ing.Unit)
+		return ing
+	}
+	if u.Dimension == quantity.Count {
+		// Counts keep their wording ("2 large eggs"); only mass and
+		// volume move between units.
+		ing.Quantity = quantity.Round(ing.Quantity*factor, u.Symbol)
+		return ing
+	}
+
+	q := quantity.Simplify(quantity.Quantity{Amount: ing.Quantity * factor, Unit: u.Symbol})
+	ing.Quantity = q.Amount
+	ing.Unit = quantity.Spell(q.Unit, language, q.Amount)
+	return ing
+}
@@ -0,0 +1,65 @@
+package recipe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScale(t *testing.T) {
+	r := &Recipe{
+		Title:    "Pancakes",
+		Language: "en",
+		Details: Details{
+			Servings: 4,
+			Ingredients: []Ingredient{
+				{Name: "flour", Quantity: 2, Unit: "cups"},
+				{Name: "butter", Quantity: 8, Unit: "tbsp"},
+				{Name: "eggs", Quantity: 2, Unit: "large"},
+				{Name: "salt"},
+			},
+			Steps: []string{"Mix.", "Cook."},
+		},
+	}
+
+	scaled, err := Scale(r, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Ingredient{
+		{Name: "flour", Quantity: 3, Unit: "cups"},
+		{Name: "butter", Quantity: 0.75, Unit: "cup"},
+		{Name: "eggs", Quantity: 3, Unit: "large"},
+		{Name: "salt"},
+	}
+	for i, ing := range scaled.Ingredients {
+		if ing != want[i] {
+			t.Errorf("ingredient %d = %+v; want %+v", i, ing, want[i])
+		}
+	}
+	if scaled.Servings != 6 || scaled.ScaledFrom != 4 {
+		t.Errorf("servings = %d from %d; want 6 from 4", scaled.Servings, scaled.ScaledFrom)
+	}
+	if !strings.Contains(scaled.ContentMarkdown, "3/4 cup butter") {
+		t.Errorf("markdown not re-rendered:\n%s", scaled.ContentMarkdown)
+	}
+	if r.Ingredients[0].Quantity != 2 {
+		t.Error("Scale modified the stored recipe")
+	}
+	if scaled.Nutrition == nil || scaled.Nutrition.Servings != 6 {
+		t.Errorf("nutrition = %+v; want it per 6 servings", scaled.Nutrition)
+	}
+
+	pt := &Recipe{Language: "pt-BR", Details: Details{Servings: 2, Ingredients: []Ingredient{{Name: "azeite", Quantity: 2, Unit: "colheres de chá"}}}}
+	scaled, err = Scale(pt, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scaled.Ingredients[0]; got.Quantity != 1 || got.Unit != "colher de sopa" {
+		t.Errorf("pt ingredient = %+v; want 1 colher de sopa", got)
+	}
+
+	if _, err := Scale(&Recipe{ContentMarkdown: "legacy"}, 2); !errors.Is(err, ErrNotScalable) {
+		t.Errorf("Scale without servings error = %v; want ErrNotScalable", err)
+	}
+}
@@ -3,44 +3,382 @@ package recipe
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/locale"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quota"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/storage"
 	"github.com/google/uuid"
 )
 
+// ErrDegradedRecipe is returned when saving a generation whose output could
+// not be parsed into a recipe.
+var ErrDegradedRecipe = errors.New("recipe could not be parsed and cannot be saved; please regenerate it")
+
+var ErrRecipeNotFound = errors.New("recipe not found")
+
+var ErrImageNotFound = errors.New("recipe has no image")
+
+// ErrVersionConflict is returned when an edit was based on a version of the
+// recipe that has since changed.
+var ErrVersionConflict = errors.New("recipe was changed since it was loaded; reload it and try again")
+
+var ErrInvalidRecipe = errors.New("invalid recipe")
+
+// maxTitleLength is the size of the title column.
+const maxTitleLength = 255
+
+// maxTagLength bounds a tag; the column allows more, but tags are labels.
+const maxTagLength = 50
+
+// maxRevisionDepth bounds how far back a revision chain is followed.
+const maxRevisionDepth = 100
+
+// imageExtensions maps the content types image providers return to the
+// extension of the stored file.
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+}
+
 type RecipeService struct {
-	repo *RecipeRepository
+	repo  *RecipeRepository
+	store storage.Store
+	// images draws recipe pictures; when nil a placeholder is rendered
+	// instead.
+	images ia.ImageGenerator
 }
 
-func NewRecipeService(repo *RecipeRepository) *RecipeService {
-	return &RecipeService{repo: repo}
+func NewRecipeService(repo *RecipeRepository, store storage.Store, images ia.ImageGenerator) *RecipeService {
+	return &RecipeService{repo: repo, store: store, images: images}
 }
 
 func (s *RecipeService) CreateRecipe(ctx context.Context, userID uuid.UUID, req CreateRecipeRequest) (*Recipe, error) {
-	ingredientsJSON, err := json.Marshal(req.IngredientsUsed)
+	if req.ParseStatus == "degraded" {
+		return nil, ErrDegradedRecipe
+	}
+	if req.SourceURL != "" {
+		// Clients link to it, so only web pages are accepted.
+		if u, err := url.Parse(req.SourceURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: source_url must be an http or https URL", ErrInvalidRecipe)
+		}
+	}
+	if req.RevisionOf != nil {
+		parent, err := s.repo.GetRecipeByID(ctx, *req.RevisionOf, userID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, fmt.Errorf("revision_of: %w", ErrRecipeNotFound)
+		}
+	}
+
+	ingredientsUsed := req.IngredientsUsed
+	if len(ingredientsUsed) == 0 {
+		for _, ing := range req.Ingredients {
+			ingredientsUsed = append(ingredientsUsed, ing.Name)
+		}
+	}
+
+	ingredientsJSON, err := json.Marshal(ingredientsUsed)
 	if err != nil {
 		return nil, err
 	}
 
+	language := locale.Resolve(req.Language).Tag
+
+	content := req.ContentMarkdown
+	if req.IsStructured() {
+		content = RenderMarkdown(req.Title, req.CaloriesEstimate, req.Details, language)
+	}
+
 	recipe := &Recipe{
 		UserID:           userID,
 		Title:            req.Title,
 		IngredientsUsed:  ingredientsJSON,
-		ContentMarkdown:  req.ContentMarkdown,
+		ContentMarkdown:  content,
 		CaloriesEstimate: req.CaloriesEstimate,
+		Language:         language,
+		PromptVersion:    req.PromptVersion,
+		Model:            req.Model,
+		RevisionOf:       req.RevisionOf,
+		SourceURL:        req.SourceURL,
+		Nutrition:        req.ComputeNutrition(),
+		Details:          req.Details,
 	}
 
 	return s.repo.CreateRecipe(ctx, recipe)
 }
 
-func (s *RecipeService) ListRecipes(ctx context.Context, userID uuid.UUID, filter RecipeFilter) ([]*Recipe, error) {
-	return s.repo.ListRecipes(ctx, userID, filter)
+// GetRecipe returns one of the user's recipes, or ErrRecipeNotFound.
+func (s *RecipeService) GetRecipe(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Recipe, error) {
+	recipe, err := s.repo.GetRecipeByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if recipe == nil {
+		return nil, ErrRecipeNotFound
+	}
+	setImageURL(recipe)
+	return recipe, nil
+}
+
+// GetScaledRecipe returns one of the user's recipes scaled to servings; see
+// Scale.
+func (s *RecipeService) GetScaledRecipe(ctx context.Context, id uuid.UUID, userID uuid.UUID, servings int) (*Recipe, error) {
+	recipe, err := s.GetRecipe(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return Scale(recipe, servings)
+}
+
+// UpdateRecipe edits one of the user's recipes; replace tells a PUT from a
+// PATCH. ifMatch is the If-Match header: unless it is empty or "*", the
+// edit only applies to the version it names.
+func (s *RecipeService) UpdateRecipe(ctx context.Context, id uuid.UUID, userID uuid.UUID, req UpdateRecipeRequest, replace bool, ifMatch string) (*Recipe, error) {
+	var version *time.Time
+	if ifMatch != "" && ifMatch != "*" {
+		v, ok := parseETag(ifMatch)
+		if !ok {
+			return nil, ErrVersionConflict
+		}
+		version = &v
+	}
+
+	recipe, err := s.GetRecipe(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if version != nil && !recipe.UpdatedAt.Equal(*version) {
+		return nil, ErrVersionConflict
+	}
+
+	if err := applyUpdate(recipe, req, replace); err != nil {
+		return nil, err
+	}
+	if err := s.repo.EditRecipe(ctx, recipe, version); err != nil {
+		return nil, err
+	}
+	setImageURL(recipe)
+	return recipe, nil
+}
+
+func applyUpdate(recipe *Recipe, req UpdateRecipeRequest, replace bool) error {
+	if replace {
+		if req.Title == nil || (req.ContentMarkdown == nil && (req.Ingredients == nil || req.Steps == nil)) {
+			return fmt.Errorf("%w: title and either content_markdown or ingredients and steps are required", ErrInvalidRecipe)
+		}
+		recipe.CaloriesEstimate = 0
+		recipe.Details = Details{}
+	}
+
+	if req.Title != nil {
+		recipe.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.CaloriesEstimate != nil {
+		recipe.CaloriesEstimate = *req.CaloriesEstimate
+	}
+	if req.Servings != nil {
+		recipe.Servings = *req.Servings
+	}
+	if req.PrepTimeMinutes != nil {
+		recipe.PrepTimeMinutes = *req.PrepTimeMinutes
+	}
+	if req.CookTimeMinutes != nil {
+		recipe.CookTimeMinutes = *req.CookTimeMinutes
+	}
+	if req.Cuisine != nil {
+		recipe.Cuisine = strings.TrimSpace(*req.Cuisine)
+	}
+	if req.Tags != nil {
+		recipe.Tags = nil
+		for _, raw := range *req.Tags {
+			tag, err := NormalizeTag(raw)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(recipe.Tags, tag) {
+				recipe.Tags = append(recipe.Tags, tag)
+			}
+		}
+	}
+	if req.Ingredients != nil {
+		recipe.Ingredients = *req.Ingredients
+	}
+	if req.Steps != nil {
+		recipe.Steps = *req.Steps
+	}
+	if req.ContentMarkdown != nil {
+		recipe.ContentMarkdown = *req.ContentMarkdown
+		// Structured recipes are shown from their details, so edited
+		// markdown has to become the details or the edit is lost.
+		if req.Ingredients == nil && req.Steps == nil {
+			parsed := ParseMarkdown(recipe.ContentMarkdown)
+			recipe.Ingredients, recipe.Steps = nil, nil
+			if parsed.IsStructured() {
+				recipe.Ingredients, recipe.Steps = parsed.Ingredients, parsed.Steps
+			}
+		}
+	}
+
+	switch {
+	case recipe.Title == "":
+		return fmt.Errorf("%w: title is required", ErrInvalidRecipe)
+	case len([]rune(recipe.Title)) > maxTitleLength:
+		return fmt.Errorf("%w: title is longer than %d characters", ErrInvalidRecipe, maxTitleLength)
+	case recipe.CaloriesEstimate < 0 || recipe.PrepTimeMinutes < 0 || recipe.CookTimeMinutes < 0:
+		return fmt.Errorf("%w: calories and times cannot be negative", ErrInvalidRecipe)
+	case recipe.Servings < 0 || recipe.Servings > MaxServings:
+		return fmt.Errorf("%w: servings must be between 1 and %d", ErrInvalidRecipe, MaxServings)
+	}
+	for _, ing := range recipe.Ingredients {
+		if strings.TrimSpace(ing.Name) == "" || ing.Quantity < 0 {
+			return fmt.Errorf("%w: ingredients need a name and cannot have a negative quantity", ErrInvalidRecipe)
+		}
+	}
+
+	if recipe.IsStructured() {
+		recipe.ContentMarkdown = RenderMarkdown(recipe.Title, recipe.CaloriesEstimate, recipe.Details, recipe.Language)
+	}
+	if strings.TrimSpace(recipe.ContentMarkdown) == "" {
+		return fmt.Errorf("%w: content_markdown is required", ErrInvalidRecipe)
+	}
+	// ingredients_used feeds search, the ingredient filter and
+	// substitutions, so it follows the ingredient lines. Markdown that
+	// yields none leaves it alone, as older recipes only have that list.
+	if req.Ingredients != nil || len(recipe.Ingredients) > 0 {
+		names := make([]string, 0, len(recipe.Ingredients))
+		for _, ing := range recipe.Ingredients {
+			names = append(names, ing.Name)
+		}
+		used, err := json.Marshal(names)
+		if err != nil {
+			return err
+		}
+		recipe.IngredientsUsed = used
+	}
+	recipe.Nutrition = recipe.ComputeNutrition()
+	return nil
+}
+
+// Revisions returns the chain of recipes id was refined from, oldest first
+// and ending with id itself.
+func (s *RecipeService) Revisions(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]*Recipe, error) {
+	recipes, err := s.repo.GetRevisions(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipes) == 0 {
+		return nil, ErrRecipeNotFound
+	}
+	for _, recipe := range recipes {
+		setImageURL(recipe)
+	}
+	return recipes, nil
+}
+
+func (s *RecipeService) ListRecipes(ctx context.Context, userID uuid.UUID, filter RecipeFilter) (*RecipePage, error) {
+	page, err := s.repo.ListRecipes(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, recipe := range page.Recipes {
+		setImageURL(recipe)
+	}
+	return page, nil
 }
 
 func (s *RecipeService) DeleteRecipe(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	return s.repo.DeleteRecipe(ctx, id, userID)
+	imageKey, err := s.repo.DeleteRecipe(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	s.removeImage(ctx, imageKey)
+	return nil
+}
+
+// NormalizeTag trims a tag, collapses its inner whitespace and lowercases
+// it, so "Week Night" and " week  night" are the same tag.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+	if tag == "" {
+		return "", fmt.Errorf("%w: tags cannot be empty", ErrInvalidRecipe)
+	}
+	if len([]rune(tag)) > maxTagLength {
+		return "", fmt.Errorf("%w: tags are at most %d characters", ErrInvalidRecipe, maxTagLength)
+	}
+	return tag, nil
+}
+
+// ListTags returns the tags on the user's recipes with how often each is used.
+func (s *RecipeService) ListTags(ctx context.Context, userID uuid.UUID) ([]TagCount, error) {
+	return s.repo.ListTags(ctx, userID)
+}
+
+func (s *RecipeService) AddTag(ctx context.Context, id uuid.UUID, userID uuid.UUID, tag string) (*Recipe, error) {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddTag(ctx, id, userID, tag); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(ctx, id, userID)
+}
+
+func (s *RecipeService) RemoveTag(ctx context.Context, id uuid.UUID, userID uuid.UUID, tag string) (*Recipe, error) {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RemoveTag(ctx, id, userID, tag); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(ctx, id, userID)
+}
+
+func (s *RecipeService) SetFavorite(ctx context.Context, id uuid.UUID, userID uuid.UUID, favorite bool) (*Recipe, error) {
+	if err := s.repo.SetFavorite(ctx, id, userID, favorite); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(ctx, id, userID)
+}
+
+// MarkCooked records that the recipe was cooked at the given time, or now
+// when it is zero. A time in the future is refused.
+func (s *RecipeService) MarkCooked(ctx context.Context, id uuid.UUID, userID uuid.UUID, at time.Time) (*Recipe, error) {
+	now := time.Now()
+	if at.IsZero() {
+		at = now
+	}
+	if at.After(now.Add(time.Minute)) {
+		return nil, fmt.Errorf("%w: cooked_at cannot be in the future", ErrInvalidRecipe)
+	}
+	if err := s.repo.SetLastCooked(ctx, id, userID, &at); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(ctx, id, userID)
+}
+
+// ClearCooked forgets when the recipe was last cooked.
+func (s *RecipeService) ClearCooked(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Recipe, error) {
+	if err := s.repo.SetLastCooked(ctx, id, userID, nil); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(ctx, id, userID)
 }
 
 func (s *RecipeService) ToggleShare(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Recipe, error) {
@@ -67,9 +405,150 @@ func (s *RecipeService) ToggleShare(ctx context.Context, id uuid.UUID, userID uu
 	if err := s.repo.UpdateRecipe(ctx, recipe); err != nil {
 		return nil, err
 	}
+	setImageURL(recipe)
 	return recipe, nil
 }
 
 func (s *RecipeService) GetPublicRecipe(ctx context.Context, token string) (*Recipe, error) {
-	return s.repo.GetRecipeByToken(ctx, token)
+	recipe, err := s.repo.GetRecipeByToken(ctx, token)
+	if err != nil || recipe == nil {
+		return recipe, err
+	}
+	if recipe.ImageKey != "" {
+		recipe.ImageURL = fmt.Sprintf("/api/v1/recipes/share/%s/image?v=%s", token, imageVersion(recipe.ImageKey))
+	}
+	return recipe, nil
+}
+
+// GenerateImage draws a picture of one of the user's recipes, stores it and
+// replaces the previous one. Without an image provider a placeholder is
+// rendered and the generation is refunded.
+func (s *RecipeService) GenerateImage(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Recipe, error) {
+	recipe, err := s.GetRecipe(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := s.drawImage(ctx, recipe)
+	if err != nil {
+		return nil, err
+	}
+	ext, ok := imageExtensions[img.ContentType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported image type %q", img.ContentType)
+	}
+	sum := sha256.Sum256(img.Data)
+	key := fmt.Sprintf("recipes/%s/%s%s", recipe.ID, hex.EncodeToString(sum[:8]), ext)
+
+	if err := s.store.Put(ctx, key, storage.Object{Data: img.Data, ContentType: img.ContentType}); err != nil {
+		return nil, fmt.Errorf("store image: %w", err)
+	}
+	previous, err := s.repo.SetImage(ctx, recipe.ID, userID, key)
+	if err != nil {
+		s.removeImage(ctx, key)
+		return nil, err
+	}
+	if previous != key {
+		s.removeImage(ctx, previous)
+	}
+
+	recipe.ImageKey = key
+	setImageURL(recipe)
+	return recipe, nil
+}
+
+func (s *RecipeService) drawImage(ctx context.Context, recipe *Recipe) (*ia.Image, error) {
+	names := ingredientNames(recipe)
+	if s.images == nil {
+		quota.Refund(ctx, 1)
+		data, err := renderPlaceholder(recipe.Title, names)
+		if err != nil {
+			return nil, fmt.Errorf("render placeholder: %w", err)
+		}
+		return &ia.Image{Data: data, ContentType: "image/png"}, nil
+	}
+
+	dish := recipe.Title
+	if recipe.Cuisine != "" {
+		dish += " (" + recipe.Cuisine + " cuisine)"
+	}
+	prompt := fmt.Sprintf("Overhead food photograph of %s, plated and ready to serve, made with %s. "+
+		"Natural light, shallow depth of field, no text, no people.", dish, strings.Join(names, ", "))
+	img, err := s.images.GenerateImage(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("generate image: %w", err)
+	}
+	return img, nil
+}
+
+// Image returns the picture of one of the user's recipes and its version.
+func (s *RecipeService) Image(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*storage.Object, string, error) {
+	recipe, err := s.repo.GetRecipeByID(ctx, id, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if recipe == nil {
+		return nil, "", ErrRecipeNotFound
+	}
+	return s.loadImage(ctx, recipe.ImageKey)
+}
+
+// PublicImage returns the picture of a shared recipe and its version.
+func (s *RecipeService) PublicImage(ctx context.Context, token string) (*storage.Object, string, error) {
+	recipe, err := s.repo.GetRecipeByToken(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+	if recipe == nil {
+		return nil, "", ErrRecipeNotFound
+	}
+	return s.loadImage(ctx, recipe.ImageKey)
+}
+
+func (s *RecipeService) loadImage(ctx context.Context, key string) (*storage.Object, string, error) {
+	if key == "" {
+		return nil, "", ErrImageNotFound
+	}
+	obj, err := s.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, "", ErrImageNotFound
+		}
+		return nil, "", fmt.Errorf("load image: %w", err)
+	}
+	return obj, imageVersion(key), nil
+}
+
+// removeImage deletes a stored image. Failures only leave an orphaned
+// object behind, so they are logged rather than returned.
+func (s *RecipeService) removeImage(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := s.store.Delete(ctx, key); err != nil {
+		log.Printf("delete image %s: %v", key, err)
+	}
+}
+
+// imageVersion is the content hash embedded in an image key.
+func imageVersion(key string) string {
+	base := path.Base(key)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+func setImageURL(recipe *Recipe) {
+	if recipe.ImageKey != "" {
+		recipe.ImageURL = fmt.Sprintf("/api/v1/recipes/%s/image?v=%s", recipe.ID, imageVersion(recipe.ImageKey))
+	}
+}
+
+func ingredientNames(recipe *Recipe) []string {
+	var names []string
+	for _, ing := range recipe.Ingredients {
+		names = append(names, ing.Name)
+	}
+	if len(names) == 0 {
+		json.Unmarshal(recipe.IngredientsUsed, &names)
+	}
+	return names
 }
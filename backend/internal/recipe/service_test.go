@@ -0,0 +1,113 @@
+package recipe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyUpdate(t *testing.T) {
+	stored := func() *Recipe {
+		return &Recipe{
+			Title:            "Omelette",
+			CaloriesEstimate: 300,
+			Language:         "en",
+			Details: Details{
+				Servings:    1,
+				Cuisine:     "French",
+				Ingredients: []Ingredient{{Name: "eggs", Quantity: 2}},
+				Steps:       []string{"Whisk.", "Cook."},
+			},
+		}
+	}
+	ptr := func(s string) *string { return &s }
+	tooMany := MaxServings + 1
+
+	r := stored()
+	if err := applyUpdate(r, UpdateRecipeRequest{Title: ptr("  Cheese omelette ")}, false); err != nil {
+		t.Fatal(err)
+	}
+	if r.Title != "Cheese omelette" || r.Cuisine != "French" || r.CaloriesEstimate != 300 {
+		t.Errorf("patch changed more than the title: %+v", r)
+	}
+	if !strings.HasPrefix(r.ContentMarkdown, "# Cheese omelette") {
+		t.Errorf("markdown not re-rendered:\n%s", r.ContentMarkdown)
+	}
+
+	r = stored()
+	content := "# Toast\n\n## Ingredients\n- 2 slices bread\n\n## Instructions\n1. Toast the bread.\n"
+	if err := applyUpdate(r, UpdateRecipeRequest{Title: ptr("Toast"), ContentMarkdown: &content}, true); err != nil {
+		t.Fatal(err)
+	}
+	if r.Cuisine != "" || r.Servings != 0 || len(r.Ingredients) != 1 || r.Ingredients[0].Unit != "slices" || len(r.Steps) != 1 {
+		t.Errorf("put did not replace the details: %+v", r.Details)
+	}
+	if string(r.IngredientsUsed) != `["bread"]` {
+		t.Errorf("ingredients_used = %s; want it rebuilt from the new lines", r.IngredientsUsed)
+	}
+
+	// Markdown without ingredient lines keeps the list older recipes have.
+	r = stored()
+	r.IngredientsUsed = []byte(`["eggs"]`)
+	notes := "Just whisk and cook."
+	if err := applyUpdate(r, UpdateRecipeRequest{ContentMarkdown: &notes}, false); err != nil {
+		t.Fatal(err)
+	}
+	if string(r.IngredientsUsed) != `["eggs"]` {
+		t.Errorf("ingredients_used = %s; want it kept", r.IngredientsUsed)
+	}
+
+	for name, req := range map[string]UpdateRecipeRequest{
+		"put without content": {Title: ptr("Toast")},
+		"empty title":         {Title: ptr(" ")},
+		"too many servings":   {Servings: &tooMany},
+	} {
+		replace := name == "put without content"
+		if err := applyUpdate(stored(), req, replace); !errors.Is(err, ErrInvalidRecipe) {
+			t.Errorf("%s: error = %v; want ErrInvalidRecipe", name, err)
+		}
+	}
+}
+
+func TestETagRoundTrip(t *testing.T) {
+	r := &Recipe{UpdatedAt: time.Date(2025, 12, 2, 10, 0, 0, 123456000, time.UTC)}
+	got, ok := parseETag(r.ETag())
+	if !ok || !got.Equal(r.UpdatedAt) {
+		t.Errorf("parseETag(%s) = %v, %v; want %v", r.ETag(), got, ok, r.UpdatedAt)
+	}
+	if got, ok := parseETag("W/" + r.ETag()); !ok || !got.Equal(r.UpdatedAt) {
+		t.Errorf("weak tag = %v, %v; want %v", got, ok, r.UpdatedAt)
+	}
+	for _, etag := range []string{"", "*", `W/"x"`, `"yesterday"`} {
+		if _, ok := parseETag(etag); ok {
+			t.Errorf("parseETag(%q) succeeded", etag)
+		}
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	for in, want := range map[string]string{
+		"Weeknight":       "weeknight",
+		"  Week \t Night": "week night",
+		"Ação":            "ação",
+	} {
+		if got, err := NormalizeTag(in); err != nil || got != want {
+			t.Errorf("NormalizeTag(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "   ", strings.Repeat("x", maxTagLength+1)} {
+		if _, err := NormalizeTag(in); !errors.Is(err, ErrInvalidRecipe) {
+			t.Errorf("NormalizeTag(%q) error = %v; want ErrInvalidRecipe", in, err)
+		}
+	}
+
+	r := &Recipe{Title: "Soup", ContentMarkdown: "# Soup"}
+	tags := []string{"Quick", " quick ", "Vegan"}
+	if err := applyUpdate(r, UpdateRecipeRequest{Tags: &tags}, false); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(r.Tags, ",") != "quick,vegan" {
+		t.Errorf("tags = %v; want [quick vegan]", r.Tags)
+	}
+}
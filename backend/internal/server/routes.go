@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/chef"
 	appMiddleware "github.com/Igorlimaponce/fridgeChef/backend/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -18,7 +19,8 @@ func (s *Server) RegisterRoutes() http.Handler {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"Content-Disposition", "ETag", "Retry-After", "X-Cache", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -36,18 +38,40 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 		r.Route("/chef", func(r chi.Router) {
 			r.Use(appMiddleware.JWTAuth)
-			r.Post("/generate", s.chefHandler.GenerateRecipe)
+			r.Get("/quota", s.quotaHandler.Get)
+			r.With(s.quotaHandler.Limit(chef.GenerationCost)).Post("/generate", s.chefHandler.GenerateRecipe)
+			r.With(s.quotaHandler.Limit(nil)).Post("/generate/stream", s.chefHandler.GenerateRecipeStream)
+			r.With(s.quotaHandler.Limit(nil)).Post("/refine", s.refineHandler.Refine)
+			r.Get("/sessions/{id}", s.refineHandler.GetSession)
+			r.With(s.quotaHandler.Limit(nil)).Post("/substitutions", s.substitutionHandler.Suggest)
 		})
 
 		r.Route("/recipes", func(r chi.Router) {
 			r.Use(appMiddleware.JWTAuth)
 			r.Post("/", s.recipeHandler.CreateRecipe)
 			r.Get("/", s.recipeHandler.ListRecipes)
+			r.Get("/tags", s.recipeHandler.ListTags)
+			r.Get("/export", s.recipeHandler.ExportLibrary)
+			r.With(s.quotaHandler.Limit(nil)).Post("/import", s.importHandler.Import)
+			r.Get("/{id}", s.recipeHandler.GetRecipe)
+			r.Put("/{id}", s.recipeHandler.ReplaceRecipe)
+			r.Patch("/{id}", s.recipeHandler.PatchRecipe)
 			r.Delete("/{id}", s.recipeHandler.DeleteRecipe)
 			r.Post("/{id}/share", s.recipeHandler.ToggleShare)
+			r.Get("/{id}/revisions", s.recipeHandler.Revisions)
+			r.Get("/{id}/export", s.recipeHandler.ExportRecipe)
+			r.With(s.quotaHandler.Limit(nil)).Post("/{id}/image", s.recipeHandler.GenerateImage)
+			r.Get("/{id}/image", s.recipeHandler.GetImage)
+			r.Post("/{id}/tags", s.recipeHandler.AddTag)
+			r.Delete("/{id}/tags/{tag}", s.recipeHandler.RemoveTag)
+			r.Put("/{id}/favorite", s.recipeHandler.AddFavorite)
+			r.Delete("/{id}/favorite", s.recipeHandler.RemoveFavorite)
+			r.Post("/{id}/cooked", s.recipeHandler.MarkCooked)
+			r.Delete("/{id}/cooked", s.recipeHandler.ClearCooked)
 		})
 
 		r.Get("/recipes/share/{token}", s.recipeHandler.GetPublicRecipe)
+		r.Get("/recipes/share/{token}/image", s.recipeHandler.GetPublicImage)
 
 		r.Route("/meal-plans", func(r chi.Router) {
 			r.Use(appMiddleware.JWTAuth)
@@ -56,12 +80,30 @@ func (s *Server) RegisterRoutes() http.Handler {
 			r.Delete("/{id}", s.mealPlanHandler.Delete)
 		})
 
+		r.Route("/collections", func(r chi.Router) {
+			r.Use(appMiddleware.JWTAuth)
+			r.Post("/", s.collectionHandler.Create)
+			r.Get("/", s.collectionHandler.List)
+			r.Get("/{id}", s.collectionHandler.Get)
+			r.Patch("/{id}", s.collectionHandler.Update)
+			r.Delete("/{id}", s.collectionHandler.Delete)
+			r.Put("/{id}/recipes/{recipeID}", s.collectionHandler.AddRecipe)
+			r.Delete("/{id}/recipes/{recipeID}", s.collectionHandler.RemoveRecipe)
+		})
+
 		r.Route("/pantry", func(r chi.Router) {
 			r.Use(appMiddleware.JWTAuth)
 			r.Post("/", s.pantryHandler.Create)
 			r.Get("/", s.pantryHandler.List)
 			r.Delete("/{id}", s.pantryHandler.Delete)
 		})
+
+		r.Get("/dietary-profile/options", s.dietaryHandler.Options)
+		r.Route("/dietary-profile", func(r chi.Router) {
+			r.Use(appMiddleware.JWTAuth)
+			r.Get("/", s.dietaryHandler.Get)
+			r.Put("/", s.dietaryHandler.Update)
+		})
 	})
 
 	return r
@@ -80,6 +122,15 @@ func (s *Server) HelloWorldHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
-	jsonResp, _ := json.Marshal(s.db.Health())
+	stats := s.db.Health()
+	stats["ai_provider"] = s.aiProvider
+	for k, v := range s.aiBreaker.Health() {
+		stats["ai_"+k] = v
+	}
+	for k, v := range s.imageBreaker.Health() {
+		stats["image_"+k] = v
+	}
+
+	jsonResp, _ := json.Marshal(stats)
 	_, _ = w.Write(jsonResp)
 }
@@ -1,18 +1,26 @@
 package server
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/Igorlimaponce/fridgeChef/backend/internal/chef"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/collection"
 	"github.com/Igorlimaponce/fridgeChef/backend/internal/database"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/dietary"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/external/ia"
 	"github.com/Igorlimaponce/fridgeChef/backend/internal/filter"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/importer"
 	"github.com/Igorlimaponce/fridgeChef/backend/internal/mealplan"
 	"github.com/Igorlimaponce/fridgeChef/backend/internal/pantry"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/quota"
 	"github.com/Igorlimaponce/fridgeChef/backend/internal/recipe"
+	"github.com/Igorlimaponce/fridgeChef/backend/internal/storage"
 	"github.com/Igorlimaponce/fridgeChef/backend/internal/user"
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -22,11 +30,23 @@ type Server struct {
 
 	db database.Service
 
-	userHandler     *user.UserHandler
-	chefHandler     *chef.ChefHandler
-	recipeHandler   *recipe.RecipeHandler
-	mealPlanHandler *mealplan.MealPlanHandler
-	pantryHandler   *pantry.PantryHandler
+	aiProvider string
+	// The text and image providers fail independently, so each has its own
+	// breaker: image outages must not stop recipe generation.
+	aiBreaker    *ia.CircuitBreaker
+	imageBreaker *ia.CircuitBreaker
+
+	userHandler         *user.UserHandler
+	chefHandler         *chef.ChefHandler
+	refineHandler       *chef.RefineHandler
+	substitutionHandler *chef.SubstitutionHandler
+	recipeHandler       *recipe.RecipeHandler
+	importHandler       *importer.ImportHandler
+	mealPlanHandler     *mealplan.MealPlanHandler
+	collectionHandler   *collection.CollectionHandler
+	pantryHandler       *pantry.PantryHandler
+	quotaHandler        *quota.QuotaHandler
+	dietaryHandler      *dietary.DietaryHandler
 }
 
 func NewServer() *http.Server {
@@ -47,28 +67,110 @@ func NewServer() *http.Server {
 	pantryService := pantry.NewPantryService(pantryRepo)
 	pantryHandler := pantry.NewPantryHandler(pantryService)
 
+	// Init Dietary
+	dietaryRepo := dietary.NewDietaryRepository(db.GetDB())
+	dietaryService := dietary.NewDietaryService(dietaryRepo)
+	dietaryHandler := dietary.NewDietaryHandler(dietaryService)
+
+	// Init Quota
+	quotaRepo := quota.NewQuotaRepository(db.GetDB())
+	quotaService := quota.NewQuotaService(quotaRepo, quota.LoadConfig())
+	quotaHandler := quota.NewQuotaHandler(quotaService)
+
 	// Init Chef
-	chefService := chef.NewChefService(pantryService)
+	aiConfig := ia.LoadConfig()
+	aiBreaker := ia.NewCircuitBreaker(aiConfig.Breaker)
+	aiProvider := ia.ProviderMock
+	generator, err := ia.New(aiConfig, aiBreaker)
+	if err != nil {
+		if !errors.Is(err, ia.ErrNotConfigured) {
+			log.Fatalf("ai provider: %v", err)
+		}
+		log.Printf("AI provider %q not configured, using mock recipes", aiConfig.Provider)
+	} else {
+		aiProvider = generator.Provider()
+		log.Printf("AI provider %s ready (model %s)", generator.Provider(), generator.Model())
+	}
+	cacheConfig := chef.LoadCacheConfig()
+	var generationCache chef.Cache
+	switch cacheConfig.Backend {
+	case chef.CachePostgres:
+		generationCache = chef.NewPostgresCache(db.GetDB())
+	case chef.CacheOff:
+	default:
+		generationCache = chef.NewMemoryCache(cacheConfig.Size)
+	}
+	promptConfig := chef.LoadPromptConfig()
+	prompts, err := chef.LoadPrompts(promptConfig)
+	if err != nil {
+		log.Fatalf("prompts: %v", err)
+	}
+	if promptConfig.Experiment != "" {
+		log.Printf("Serving prompt %s, %s to %d%% of users", promptConfig.Version, promptConfig.Experiment, promptConfig.ExperimentPercent)
+	}
+	chefService := chef.NewChefService(generator, pantryService, dietaryService, prompts, generationCache, cacheConfig.TTL)
 	chefHandler := chef.NewChefHandler(chefService)
 
 	// Init Recipe
+	blobs, err := storage.New(storage.LoadConfig())
+	if err != nil {
+		log.Fatalf("blob storage: %v", err)
+	}
+	imageBreaker := ia.NewCircuitBreaker(aiConfig.Breaker)
+	imageGenerator, err := ia.NewImageGenerator(aiConfig, imageBreaker)
+	if err != nil {
+		if !errors.Is(err, ia.ErrNotConfigured) {
+			log.Fatalf("image provider: %v", err)
+		}
+		log.Printf("Image provider %q not configured, using placeholder images", aiConfig.ImageProvider)
+	} else {
+		log.Printf("Image provider %s ready (model %s)", imageGenerator.Provider(), imageGenerator.Model())
+	}
 	recipeRepo := recipe.NewRecipeRepository(db.GetDB())
-	recipeService := recipe.NewRecipeService(recipeRepo)
+	recipeService := recipe.NewRecipeService(recipeRepo, blobs, imageGenerator)
 	recipeHandler := recipe.NewRecipeHandler(recipeService)
+	// Pages without recipe markup are read by the AI provider, if any.
+	importGenerator := generator
+	if os.Getenv("IMPORT_AI_FALLBACK") == "false" {
+		importGenerator = nil
+	}
+	importService := importer.NewImportService(importer.NewHTTPFetcher(), recipeService, importGenerator)
+	importHandler := importer.NewImportHandler(importService)
+
+	// Init Refinement and Substitutions
+	sessionRepo := chef.NewSessionRepository(db.GetDB())
+	refineService := chef.NewRefineService(chefService, sessionRepo, recipeService)
+	refineHandler := chef.NewRefineHandler(refineService)
+	substitutionService := chef.NewSubstitutionService(chefService, recipeService)
+	substitutionHandler := chef.NewSubstitutionHandler(substitutionService)
 
 	// Init MealPlan
 	mealPlanRepo := mealplan.NewMealPlanRepository(db.GetDB())
 	mealPlanService := mealplan.NewMealPlanService(mealPlanRepo)
 	mealPlanHandler := mealplan.NewMealPlanHandler(mealPlanService)
 
+	// Init Collections
+	collectionRepo := collection.NewCollectionRepository(db.GetDB())
+	collectionService := collection.NewCollectionService(collectionRepo)
+	collectionHandler := collection.NewCollectionHandler(collectionService)
+
 	NewServer := &Server{
-		port:            port,
-		db:              db,
-		userHandler:     userHandler,
-		chefHandler:     chefHandler,
-		recipeHandler:   recipeHandler,
-		mealPlanHandler: mealPlanHandler,
-		pantryHandler:   pantryHandler,
+		port:                port,
+		db:                  db,
+		aiProvider:          aiProvider,
+		aiBreaker:           aiBreaker,
+		imageBreaker:        imageBreaker,
+		userHandler:         userHandler,
+		chefHandler:         chefHandler,
+		refineHandler:       refineHandler,
+		substitutionHandler: substitutionHandler,
+		recipeHandler:       recipeHandler,
+		importHandler:       importHandler,
+		mealPlanHandler:     mealPlanHandler,
+		collectionHandler:   collectionHandler,
+		pantryHandler:       pantryHandler,
+		quotaHandler:        quotaHandler,
+		dietaryHandler:      dietaryHandler,
 	}
 
 	// Declare Server config
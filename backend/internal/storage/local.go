@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+)
+
+// LocalStore keeps objects as files under a directory. The media type is
+// derived from the key's extension.
+type LocalStore struct {
+	root *os.Root
+}
+
+// NewLocalStore creates dir if needed. All access goes through an os.Root,
+// so keys can't reach outside it.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create blob dir: %w", err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("open blob dir: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+func (s *LocalStore) Put(_ context.Context, key string, obj Object) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	if err := s.root.MkdirAll(path.Dir(key), 0o755); err != nil {
+		return fmt.Errorf("put %s: %w", key, err)
+	}
+	// Write to a temporary name first so readers never see a partial file.
+	tmp := key + ".tmp"
+	if err := s.root.WriteFile(tmp, obj.Data, 0o644); err != nil {
+		return fmt.Errorf("put %s: %w", key, err)
+	}
+	if err := s.root.Rename(tmp, key); err != nil {
+		return fmt.Errorf("put %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Get(_ context.Context, key string) (*Object, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	data, err := s.root.ReadFile(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &Object{Data: data, ContentType: contentType}, nil
+}
+
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	if err := s.root.Remove(key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Package storage keeps binary objects such as recipe images, on the local
+// filesystem or in an S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Igorlimaponce/fridgeChef/backend/util"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Object is a stored blob and its media type.
+type Object struct {
+	Data        []byte
+	ContentType string
+}
+
+// Store is implemented by every blob backend. Keys are slash-separated
+// relative paths such as "recipes/<id>/<hash>.png".
+type Store interface {
+	Put(ctx context.Context, key string, obj Object) error
+	// Get returns ErrNotFound when there is no object at key.
+	Get(ctx context.Context, key string) (*Object, error)
+	// Delete succeeds when there is no object at key.
+	Delete(ctx context.Context, key string) error
+}
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+type Config struct {
+	Backend string
+	// Dir is the root directory of the local backend.
+	Dir string
+	S3  S3Config
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle puts the bucket in the path instead of the host name, as
+	// MinIO and most self-hosted servers expect.
+	PathStyle bool
+}
+
+// LoadConfig reads BLOB_BACKEND, BLOB_DIR and the S3_* variables.
+func LoadConfig() Config {
+	cfg := Config{
+		Backend: strings.ToLower(util.GetEnv("BLOB_BACKEND", BackendLocal)),
+		Dir:     util.GetEnv("BLOB_DIR", "./data/blobs"),
+		S3: S3Config{
+			Endpoint:  strings.TrimRight(util.GetEnv("S3_ENDPOINT", ""), "/"),
+			Region:    util.GetEnv("S3_REGION", "us-east-1"),
+			Bucket:    util.GetEnv("S3_BUCKET", ""),
+			AccessKey: util.GetEnv("S3_ACCESS_KEY_ID", ""),
+			SecretKey: util.GetEnv("S3_SECRET_ACCESS_KEY", ""),
+		},
+	}
+	cfg.S3.PathStyle, _ = strconv.ParseBool(util.GetEnv("S3_PATH_STYLE", "false"))
+	if cfg.S3.Endpoint == "" {
+		cfg.S3.Endpoint = "https://s3." + cfg.S3.Region + ".amazonaws.com"
+	}
+	return cfg
+}
+
+// New opens the backend selected by cfg.Backend.
+func New(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case BackendLocal, "":
+		return NewLocalStore(cfg.Dir)
+	case BackendS3:
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown blob backend %q", cfg.Backend)
+	}
+}
+
+// validKey rejects keys that could escape the store's namespace.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid object key %q", key)
+		}
+	}
+	return nil
+}
@@ -1,20 +1,28 @@
 package util
 
+const (
+	RoleAdmin  = "admin"
+	RoleViewer = "viewer"
+
+	// DefaultRole is assigned to newly registered users.
+	DefaultRole = RoleViewer
+)
+
 func VerifyRole(userRole, requiredRole string) bool {
 	return userRole == requiredRole
 }
 
 func IsAdmin(userRole string) bool {
-	return VerifyRole(userRole, "admin")
+	return VerifyRole(userRole, RoleAdmin)
 }
 func IsViewer(userRole string) bool {
-	return VerifyRole(userRole, "viewer")
+	return VerifyRole(userRole, RoleViewer)
 }
 
 func ValidateRole(role string) bool {
 	validRoles := map[string]bool{
-		"admin":  true,
-		"viewer": true,
+		RoleAdmin:  true,
+		RoleViewer: true,
 	}
 
 	return validRoles[role]
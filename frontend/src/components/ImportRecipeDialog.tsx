@@ -0,0 +1,112 @@
+import { useState } from "react";
+import { Download, Loader2 } from "lucide-react";
+import { toast } from "sonner";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Textarea } from "@/components/ui/textarea";
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+  DialogTrigger,
+} from "@/components/ui/dialog";
+import { useImportRecipe } from "@/hooks/useQueries";
+import { useLanguage } from "@/contexts/LanguageContext";
+import { ImportRecipeRequest } from "@/types/api";
+
+// ImportRecipeDialog imports a recipe from a web page, or from page source
+// or JSON-LD pasted by the user for sites that cannot be fetched.
+export function ImportRecipeDialog() {
+  const [open, setOpen] = useState(false);
+  const [url, setUrl] = useState("");
+  const [source, setSource] = useState("");
+  const importMutation = useImportRecipe();
+  const { t, language } = useLanguage();
+
+  const handleImport = () => {
+    const request: ImportRecipeRequest = { language };
+    const pasted = source.trim();
+    if (url.trim()) {
+      request.url = url.trim();
+    } else if (pasted.startsWith("{") || pasted.startsWith("[")) {
+      try {
+        request.json_ld = JSON.parse(pasted);
+      } catch {
+        toast.error(t("importInvalidJson"));
+        return;
+      }
+    } else {
+      request.html = pasted;
+    }
+
+    importMutation.mutate(request, {
+      onSuccess: (result) => {
+        setOpen(false);
+        setUrl("");
+        setSource("");
+        toast.success(
+          t(result.source === "model" ? "importedWithAI" : "recipeImported").replace(
+            "{recipe}",
+            result.recipe.title
+          )
+        );
+      },
+    });
+  };
+
+  return (
+    <Dialog open={open} onOpenChange={setOpen}>
+      <DialogTrigger asChild>
+        <Button variant="outline">
+          <Download className="mr-2 h-4 w-4" /> {t("importRecipe")}
+        </Button>
+      </DialogTrigger>
+      <DialogContent className="max-w-2xl">
+        <DialogHeader>
+          <DialogTitle>{t("importRecipe")}</DialogTitle>
+          <DialogDescription>{t("importRecipeDesc")}</DialogDescription>
+        </DialogHeader>
+        <div className="grid gap-4 py-4">
+          <div className="grid gap-2">
+            <Label htmlFor="import-url">{t("recipeUrl")}</Label>
+            <Input
+              id="import-url"
+              type="url"
+              value={url}
+              onChange={(e) => setUrl(e.target.value)}
+              placeholder="https://"
+              disabled={!!source.trim()}
+            />
+          </div>
+          <div className="grid gap-2">
+            <Label htmlFor="import-source">{t("pastePageSource")}</Label>
+            <Textarea
+              id="import-source"
+              rows={8}
+              className="font-mono text-xs"
+              value={source}
+              onChange={(e) => setSource(e.target.value)}
+              disabled={!!url.trim()}
+            />
+          </div>
+        </div>
+        <DialogFooter>
+          <Button variant="outline" onClick={() => setOpen(false)}>
+            {t("cancel")}
+          </Button>
+          <Button
+            onClick={handleImport}
+            disabled={importMutation.isPending || (!url.trim() && !source.trim())}
+          >
+            {importMutation.isPending && <Loader2 className="mr-2 h-4 w-4 animate-spin" />}
+            {t("import")}
+          </Button>
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  );
+}
@@ -0,0 +1,45 @@
+import { Badge } from "@/components/ui/badge";
+import { NutritionFacts } from "@/types/api";
+import { useLanguage } from "@/contexts/LanguageContext";
+
+interface NutritionPanelProps {
+  nutrition: NutritionFacts;
+}
+
+export function NutritionPanel({ nutrition }: NutritionPanelProps) {
+  const { t } = useLanguage();
+  const amounts = nutrition.per_serving;
+
+  const rows = [
+    { label: t("calories"), value: `${amounts.calories}` },
+    { label: t("protein"), value: `${amounts.protein_g} g` },
+    { label: t("fat"), value: `${amounts.fat_g} g` },
+    { label: t("carbs"), value: `${amounts.carbs_g} g` },
+    { label: t("fiber"), value: `${amounts.fiber_g} g` },
+    { label: t("sodium"), value: `${amounts.sodium_mg} mg` },
+  ];
+
+  return (
+    <div className="rounded-lg border p-4">
+      <div className="mb-3 flex items-center justify-between">
+        <h3 className="font-semibold">{t("nutritionPerServing")}</h3>
+        {nutrition.confidence !== "high" && (
+          <Badge variant="outline">{t("nutritionEstimate")}</Badge>
+        )}
+      </div>
+      <div className="grid grid-cols-3 gap-3 text-sm sm:grid-cols-6">
+        {rows.map((row) => (
+          <div key={row.label}>
+            <div className="text-muted-foreground">{row.label}</div>
+            <div className="font-medium">{row.value}</div>
+          </div>
+        ))}
+      </div>
+      {nutrition.unmatched && nutrition.unmatched.length > 0 && (
+        <p className="mt-3 text-xs text-muted-foreground">
+          {t("nutritionUnmatched").replace("{items}", nutrition.unmatched.join(", "))}
+        </p>
+      )}
+    </div>
+  );
+}
@@ -1,5 +1,25 @@
-import { useState } from "react";
-import { Trash2, Flame, Calendar, Share2, Globe, Loader2, Maximize2 } from "lucide-react";
+import { useEffect, useState } from "react";
+import {
+  Trash2,
+  Flame,
+  Calendar,
+  Share2,
+  Globe,
+  Loader2,
+  Maximize2,
+  ImagePlus,
+  Minus,
+  Plus,
+  Users,
+  Pencil,
+  Star,
+  FolderPlus,
+  CookingPot,
+  X,
+  ExternalLink,
+  Download,
+  Printer,
+} from "lucide-react";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 import { Button } from "@/components/ui/button";
 import { Badge } from "@/components/ui/badge";
@@ -25,6 +45,16 @@ import {
 } from "@/components/ui/dialog";
 import { Label } from "@/components/ui/label";
 import { Input } from "@/components/ui/input";
+import { Textarea } from "@/components/ui/textarea";
+import {
+  DropdownMenu,
+  DropdownMenuCheckboxItem,
+  DropdownMenuContent,
+  DropdownMenuItem,
+  DropdownMenuLabel,
+  DropdownMenuSeparator,
+  DropdownMenuTrigger,
+} from "@/components/ui/dropdown-menu";
 import {
   Select,
   SelectContent,
@@ -32,11 +62,43 @@ import {
   SelectTrigger,
   SelectValue,
 } from "@/components/ui/select";
-import { Recipe } from "@/types/api";
+import { Recipe, UpdateRecipeRequest } from "@/types/api";
 import { toast } from "sonner";
-import { useToggleShareRecipe, useAddToMealPlan } from "@/hooks/useQueries";
+import {
+  useToggleShareRecipe,
+  useAddToMealPlan,
+  useGenerateRecipeImage,
+  useScaledRecipe,
+  useUpdateRecipe,
+  useSetFavorite,
+  useMarkCooked,
+  useRecipeTag,
+  useCollections,
+  useSetInCollection,
+  useExportRecipe,
+} from "@/hooks/useQueries";
+import { api } from "@/services/api";
+import { NutritionPanel } from "@/components/NutritionPanel";
 import { useLanguage } from "@/contexts/LanguageContext";
 
+// SearchHighlight renders a search excerpt. Only the <mark> tags the server
+// adds are markup; the rest is recipe text and is rendered as such.
+function SearchHighlight({ text }: { text: string }) {
+  return (
+    <p className="text-sm text-foreground/80 line-clamp-6">
+      {text.split(/<mark>|<\/mark>/).map((part, i) =>
+        i % 2 === 1 ? (
+          <mark key={i} className="rounded bg-primary/20 px-0.5 text-foreground">
+            {part}
+          </mark>
+        ) : (
+          part
+        )
+      )}
+    </p>
+  );
+}
+
 interface RecipeCardProps {
   recipe: Recipe;
   onDelete: (id: string) => void;
@@ -47,10 +109,56 @@ export function RecipeCard({ recipe, onDelete }: RecipeCardProps) {
   const [isFullViewOpen, setIsFullViewOpen] = useState(false);
   const [planDate, setPlanDate] = useState("");
   const [planType, setPlanType] = useState("dinner");
+  const [imageSrc, setImageSrc] = useState<string | null>(null);
+  const [servings, setServings] = useState(recipe.servings ?? 0);
+  const [isEditOpen, setIsEditOpen] = useState(false);
+  const [editTitle, setEditTitle] = useState(recipe.title);
+  const [editCalories, setEditCalories] = useState(String(recipe.calories_estimate));
+  const [editContent, setEditContent] = useState(recipe.content_markdown);
+  const [newTag, setNewTag] = useState("");
   const { t } = useLanguage();
 
   const toggleShareMutation = useToggleShareRecipe();
   const addToPlanMutation = useAddToMealPlan();
+  const generateImageMutation = useGenerateRecipeImage();
+  const updateMutation = useUpdateRecipe();
+  const favoriteMutation = useSetFavorite();
+  const cookedMutation = useMarkCooked();
+  const tagMutation = useRecipeTag();
+  const membershipMutation = useSetInCollection();
+  const exportMutation = useExportRecipe();
+  const { data: collections = [] } = useCollections();
+  const scaled = useScaledRecipe(
+    recipe.id,
+    servings,
+    isFullViewOpen && !!recipe.servings && servings !== recipe.servings
+  );
+  const shown = servings !== recipe.servings && scaled.data ? scaled.data : recipe;
+
+  useEffect(() => {
+    setServings(recipe.servings ?? 0);
+  }, [recipe.servings]);
+
+  useEffect(() => {
+    if (!recipe.image_url) {
+      setImageSrc(null);
+      return;
+    }
+    let objectUrl: string | null = null;
+    let cancelled = false;
+    api
+      .getRecipeImage(recipe.image_url)
+      .then((url) => {
+        objectUrl = url;
+        if (cancelled) URL.revokeObjectURL(url);
+        else setImageSrc(url);
+      })
+      .catch(() => setImageSrc(null));
+    return () => {
+      cancelled = true;
+      if (objectUrl) URL.revokeObjectURL(objectUrl);
+    };
+  }, [recipe.image_url]);
 
   const previewContent = recipe.content_markdown.slice(0, 200) + "...";
 
@@ -68,6 +176,43 @@ export function RecipeCard({ recipe, onDelete }: RecipeCardProps) {
     });
   };
 
+  const openEdit = () => {
+    setEditTitle(recipe.title);
+    setEditCalories(String(recipe.calories_estimate));
+    setEditContent(recipe.content_markdown);
+    setIsEditOpen(true);
+  };
+
+  const handleSaveEdit = () => {
+    const changes: UpdateRecipeRequest = {};
+    if (editTitle !== recipe.title) changes.title = editTitle;
+    if (Number(editCalories) !== recipe.calories_estimate)
+      changes.calories_estimate = Number(editCalories);
+    if (editContent !== recipe.content_markdown) changes.content_markdown = editContent;
+    if (Object.keys(changes).length === 0) {
+      setIsEditOpen(false);
+      return;
+    }
+    updateMutation.mutate(
+      { recipe, changes },
+      {
+        onSuccess: () => {
+          setIsEditOpen(false);
+          toast.success(t("recipeUpdated"));
+        },
+      }
+    );
+  };
+
+  const handleAddTag = () => {
+    const tag = newTag.trim();
+    if (!tag) return;
+    tagMutation.mutate(
+      { id: recipe.id, tag, add: true },
+      { onSuccess: () => setNewTag("") }
+    );
+  };
+
   const handleAddToPlan = () => {
     if (!planDate) {
       toast.error("Please select a date");
@@ -106,11 +251,69 @@ export function RecipeCard({ recipe, onDelete }: RecipeCardProps) {
 
   return (
     <>
-      <Card className="group transition-all hover:shadow-warm flex flex-col h-full">
+      <Card className="group transition-all hover:shadow-warm flex flex-col h-full overflow-hidden">
+        {imageSrc && (
+          <img
+            src={imageSrc}
+            alt={recipe.title}
+            className="h-40 w-full object-cover"
+          />
+        )}
         <CardHeader>
           <div className="flex items-start justify-between gap-4">
             <CardTitle className="text-xl line-clamp-2">{recipe.title}</CardTitle>
             <div className="flex gap-1 shrink-0">
+              <Button
+                variant="ghost"
+                size="icon"
+                className={recipe.is_favorite ? "" : "opacity-0 transition-opacity group-hover:opacity-100"}
+                title={recipe.is_favorite ? t("removeFavorite") : t("addFavorite")}
+                onClick={() => favoriteMutation.mutate({ id: recipe.id, favorite: !recipe.is_favorite })}
+                disabled={favoriteMutation.isPending}
+              >
+                <Star className={recipe.is_favorite ? "h-4 w-4 fill-yellow-400 text-yellow-500" : "h-4 w-4"} />
+              </Button>
+
+              <DropdownMenu>
+                <DropdownMenuTrigger asChild>
+                  <Button
+                    variant="ghost"
+                    size="icon"
+                    className="opacity-0 transition-opacity group-hover:opacity-100"
+                    title={t("collections")}
+                  >
+                    <FolderPlus className="h-4 w-4" />
+                  </Button>
+                </DropdownMenuTrigger>
+                <DropdownMenuContent align="end">
+                  <DropdownMenuLabel>{t("collections")}</DropdownMenuLabel>
+                  <DropdownMenuSeparator />
+                  {collections.length === 0 ? (
+                    <p className="px-2 py-1.5 text-sm text-muted-foreground">{t("noCollections")}</p>
+                  ) : (
+                    collections.map((collection) => {
+                      const member = (recipe.collection_ids ?? []).includes(collection.id);
+                      return (
+                        <DropdownMenuCheckboxItem
+                          key={collection.id}
+                          checked={member}
+                          onSelect={(e) => e.preventDefault()}
+                          onCheckedChange={(checked) =>
+                            membershipMutation.mutate({
+                              collectionId: collection.id,
+                              recipeId: recipe.id,
+                              member: checked,
+                            })
+                          }
+                        >
+                          {collection.name}
+                        </DropdownMenuCheckboxItem>
+                      );
+                    })
+                  )}
+                </DropdownMenuContent>
+              </DropdownMenu>
+
               <Dialog open={isPlanOpen} onOpenChange={setIsPlanOpen}>
                 <DialogTrigger asChild>
                   <Button
@@ -168,6 +371,79 @@ export function RecipeCard({ recipe, onDelete }: RecipeCardProps) {
                 </DialogContent>
               </Dialog>
 
+              <Dialog open={isEditOpen} onOpenChange={(open) => (open ? openEdit() : setIsEditOpen(false))}>
+                <DialogTrigger asChild>
+                  <Button
+                    variant="ghost"
+                    size="icon"
+                    className="opacity-0 transition-opacity group-hover:opacity-100"
+                    title={t("editRecipe")}
+                  >
+                    <Pencil className="h-4 w-4" />
+                  </Button>
+                </DialogTrigger>
+                <DialogContent className="max-w-2xl">
+                  <DialogHeader>
+                    <DialogTitle>{t("editRecipe")}</DialogTitle>
+                  </DialogHeader>
+                  <div className="grid gap-4 py-4">
+                    <div className="grid gap-2">
+                      <Label htmlFor={`title-${recipe.id}`}>{t("recipeTitle")}</Label>
+                      <Input
+                        id={`title-${recipe.id}`}
+                        value={editTitle}
+                        maxLength={255}
+                        onChange={(e) => setEditTitle(e.target.value)}
+                      />
+                    </div>
+                    <div className="grid gap-2">
+                      <Label htmlFor={`calories-${recipe.id}`}>{t("calories")}</Label>
+                      <Input
+                        id={`calories-${recipe.id}`}
+                        type="number"
+                        min={0}
+                        value={editCalories}
+                        onChange={(e) => setEditCalories(e.target.value)}
+                      />
+                    </div>
+                    <div className="grid gap-2">
+                      <Label htmlFor={`content-${recipe.id}`}>{t("recipeContent")}</Label>
+                      <Textarea
+                        id={`content-${recipe.id}`}
+                        rows={14}
+                        className="font-mono text-sm"
+                        value={editContent}
+                        onChange={(e) => setEditContent(e.target.value)}
+                      />
+                    </div>
+                  </div>
+                  <DialogFooter>
+                    <Button variant="outline" onClick={() => setIsEditOpen(false)}>
+                      {t("cancel")}
+                    </Button>
+                    <Button onClick={handleSaveEdit} disabled={updateMutation.isPending || !editTitle.trim()}>
+                      {updateMutation.isPending && <Loader2 className="mr-2 h-4 w-4 animate-spin" />}
+                      {t("saveRecipe")}
+                    </Button>
+                  </DialogFooter>
+                </DialogContent>
+              </Dialog>
+
+              <Button
+                variant="ghost"
+                size="icon"
+                onClick={() => generateImageMutation.mutate(recipe.id)}
+                className="opacity-0 transition-opacity group-hover:opacity-100"
+                title={t("generateImage")}
+                disabled={generateImageMutation.isPending}
+              >
+                {generateImageMutation.isPending ? (
+                  <Loader2 className="h-4 w-4 animate-spin" />
+                ) : (
+                  <ImagePlus className="h-4 w-4" />
+                )}
+              </Button>
+
               <Button
                 variant="ghost"
                 size="icon"
@@ -224,7 +500,22 @@ export function RecipeCard({ recipe, onDelete }: RecipeCardProps) {
               <Calendar className="h-4 w-4" />
               <span>{new Date(recipe.created_at).toLocaleDateString()}</span>
             </div>
+            {recipe.last_cooked_at && (
+              <div className="flex items-center gap-1" title={t("lastCooked")}>
+                <CookingPot className="h-4 w-4" />
+                <span>{new Date(recipe.last_cooked_at).toLocaleDateString()}</span>
+              </div>
+            )}
           </div>
+          {!!recipe.tags?.length && (
+            <div className="flex flex-wrap gap-1">
+              {recipe.tags.map((tag) => (
+                <Badge key={tag} variant="outline" className="text-xs">
+                  #{tag}
+                </Badge>
+              ))}
+            </div>
+          )}
         </CardHeader>
         <CardContent className="space-y-4 flex-1 flex flex-col">
           <div>
@@ -244,12 +535,16 @@ export function RecipeCard({ recipe, onDelete }: RecipeCardProps) {
           </div>
 
           <div className="prose prose-sm max-w-none flex-1">
-            <div
-              className="text-sm text-foreground/80 line-clamp-6"
-              dangerouslySetInnerHTML={{
-                __html: renderMarkdown(previewContent)
-              }}
-            />
+            {recipe.highlight ? (
+              <SearchHighlight text={recipe.highlight} />
+            ) : (
+              <div
+                className="text-sm text-foreground/80 line-clamp-6"
+                dangerouslySetInnerHTML={{
+                  __html: renderMarkdown(previewContent)
+                }}
+              />
+            )}
           </div>
 
           <Button
@@ -277,6 +572,17 @@ export function RecipeCard({ recipe, onDelete }: RecipeCardProps) {
                 <Calendar className="h-4 w-4" />
                 <span>{new Date(recipe.created_at).toLocaleDateString()}</span>
               </div>
+              {recipe.source_url && (
+                <a
+                  href={recipe.source_url}
+                  target="_blank"
+                  rel="noopener noreferrer nofollow"
+                  className="flex items-center gap-1 hover:underline"
+                >
+                  <ExternalLink className="h-4 w-4" />
+                  <span>{new URL(recipe.source_url).hostname}</span>
+                </a>
+              )}
             </div>
           </DialogHeader>
           
@@ -292,10 +598,115 @@ export function RecipeCard({ recipe, onDelete }: RecipeCardProps) {
               </div>
             </div>
 
+            <div className="flex flex-wrap items-center gap-3">
+              <Button
+                variant="outline"
+                size="sm"
+                onClick={() => cookedMutation.mutate(recipe.id)}
+                disabled={cookedMutation.isPending}
+              >
+                {cookedMutation.isPending ? (
+                  <Loader2 className="mr-2 h-4 w-4 animate-spin" />
+                ) : (
+                  <CookingPot className="mr-2 h-4 w-4" />
+                )}
+                {t("markCooked")}
+              </Button>
+              {recipe.last_cooked_at && (
+                <span className="text-sm text-muted-foreground">
+                  {t("lastCooked")}: {new Date(recipe.last_cooked_at).toLocaleString()}
+                </span>
+              )}
+              <DropdownMenu>
+                <DropdownMenuTrigger asChild>
+                  <Button variant="outline" size="sm" className="ml-auto" disabled={exportMutation.isPending}>
+                    {exportMutation.isPending ? (
+                      <Loader2 className="mr-2 h-4 w-4 animate-spin" />
+                    ) : (
+                      <Download className="mr-2 h-4 w-4" />
+                    )}
+                    {t("export")}
+                  </Button>
+                </DropdownMenuTrigger>
+                <DropdownMenuContent align="end">
+                  <DropdownMenuItem onSelect={() => exportMutation.mutate({ id: recipe.id, format: "html" })}>
+                    <Printer className="mr-2 h-4 w-4" />
+                    {t("print")}
+                  </DropdownMenuItem>
+                  <DropdownMenuItem onSelect={() => exportMutation.mutate({ id: recipe.id, format: "pdf" })}>
+                    PDF
+                  </DropdownMenuItem>
+                  <DropdownMenuItem onSelect={() => exportMutation.mutate({ id: recipe.id, format: "markdown" })}>
+                    Markdown
+                  </DropdownMenuItem>
+                  <DropdownMenuItem onSelect={() => exportMutation.mutate({ id: recipe.id, format: "jsonld" })}>
+                    {t("exportJsonLd")}
+                  </DropdownMenuItem>
+                </DropdownMenuContent>
+              </DropdownMenu>
+            </div>
+
+            <div>
+              <h3 className="mb-3 font-semibold">{t("tags")}</h3>
+              <div className="flex flex-wrap items-center gap-2">
+                {(recipe.tags ?? []).map((tag) => (
+                  <Badge key={tag} variant="outline" className="gap-1">
+                    #{tag}
+                    <button
+                      type="button"
+                      title={t("removeTag")}
+                      onClick={() => tagMutation.mutate({ id: recipe.id, tag, add: false })}
+                    >
+                      <X className="h-3 w-3" />
+                    </button>
+                  </Badge>
+                ))}
+                <Input
+                  value={newTag}
+                  onChange={(e) => setNewTag(e.target.value)}
+                  onKeyDown={(e) => e.key === "Enter" && handleAddTag()}
+                  placeholder={t("addTag")}
+                  maxLength={50}
+                  className="h-8 w-40"
+                />
+              </div>
+            </div>
+
+            {!!recipe.servings && (
+              <div className="flex items-center gap-2">
+                <Users className="h-4 w-4 text-muted-foreground" />
+                <span className="font-semibold">{t("servings")}</span>
+                <Button
+                  variant="outline"
+                  size="icon"
+                  className="h-7 w-7"
+                  title={t("fewerServings")}
+                  onClick={() => setServings((n) => Math.max(1, n - 1))}
+                  disabled={servings <= 1}
+                >
+                  <Minus className="h-3 w-3" />
+                </Button>
+                <span className="w-6 text-center">{servings}</span>
+                <Button
+                  variant="outline"
+                  size="icon"
+                  className="h-7 w-7"
+                  title={t("moreServings")}
+                  onClick={() => setServings((n) => Math.min(100, n + 1))}
+                  disabled={servings >= 100}
+                >
+                  <Plus className="h-3 w-3" />
+                </Button>
+                {scaled.isFetching && <Loader2 className="h-4 w-4 animate-spin" />}
+              </div>
+            )}
+
+            {shown.nutrition && <NutritionPanel nutrition={shown.nutrition} />}
+
             <div className="prose max-w-none dark:prose-invert">
               <div
                 dangerouslySetInnerHTML={{
-                  __html: renderMarkdown(recipe.content_markdown)
+                  __html: renderMarkdown(shown.content_markdown)
                 }}
               />
             </div>
@@ -7,6 +7,7 @@ import { toast } from "sonner";
 import { GenerateRecipeResponse } from "@/types/api";
 import { useSaveRecipe } from "@/hooks/useQueries";
 import { useLanguage } from "@/contexts/LanguageContext";
+import { NutritionPanel } from "@/components/NutritionPanel";
 
 interface RecipeDisplayProps {
   recipe: GenerateRecipeResponse;
@@ -25,6 +26,18 @@ export function RecipeDisplay({ recipe, ingredients, onSaved }: RecipeDisplayPro
         content_markdown: recipe.content,
         ingredients_used: ingredients,
         calories_estimate: recipe.calories,
+        parse_status: recipe.parse_status,
+        language: recipe.language,
+        prompt_version: recipe.prompt_version,
+        model: recipe.model,
+        revision_of: recipe.revision_of,
+        servings: recipe.servings,
+        prep_time_minutes: recipe.prep_time_minutes,
+        cook_time_minutes: recipe.cook_time_minutes,
+        cuisine: recipe.cuisine,
+        tags: recipe.tags,
+        ingredients: recipe.ingredients,
+        steps: recipe.steps,
       },
       {
         onSuccess: () => {
@@ -36,6 +49,7 @@ export function RecipeDisplay({ recipe, ingredients, onSaved }: RecipeDisplayPro
   };
 
   const isSaving = saveRecipeMutation.isPending;
+  const isDegraded = recipe.parse_status === "degraded";
 
   return (
     <Card className="shadow-card">
@@ -50,7 +64,7 @@ export function RecipeDisplay({ recipe, ingredients, onSaved }: RecipeDisplayPro
           </div>
           <Button
             onClick={handleSave}
-            disabled={isSaving}
+            disabled={isSaving || isDegraded}
             className="bg-accent shadow-warm"
           >
             {isSaving ? (
@@ -79,6 +93,8 @@ export function RecipeDisplay({ recipe, ingredients, onSaved }: RecipeDisplayPro
           </div>
         </div>
 
+        {recipe.nutrition && <NutritionPanel nutrition={recipe.nutrition} />}
+
         <div className="prose prose-sm max-w-none">
           <div
             className="space-y-4 text-foreground"
@@ -296,6 +296,10 @@ export const TRANSLATIONS = {
     generating: "AI Chef is cooking...",
     saveRecipe: "Save Recipe",
     saving: "Saving...",
+    editRecipe: "Edit recipe",
+    recipeTitle: "Title",
+    recipeContent: "Recipe",
+    recipeUpdated: "Recipe updated",
     viewFullRecipe: "View Full Recipe",
     delete: "Delete",
     cancel: "Cancel",
@@ -304,8 +308,59 @@ export const TRANSLATIONS = {
     addToMealPlan: "Add to Meal Plan",
     ingredients: "Ingredients",
     calories: "calories",
+    nutritionPerServing: "Nutrition per serving",
+    servings: "Servings",
+    fewerServings: "Fewer servings",
+    moreServings: "More servings",
+    nutritionEstimate: "Partial estimate",
+    nutritionUnmatched: "Not counted: {items}",
+    protein: "Protein",
+    fat: "Fat",
+    carbs: "Carbs",
+    fiber: "Fiber",
+    sodium: "Sodium",
     searchIngredients: "Search ingredients...",
     searchRecipes: "Search recipes...",
+    search: "Search",
+    excludeIngredient: "Without ingredient",
+    sortBy: "Sort by",
+    sortRelevance: "Best match",
+    sortNewest: "Newest",
+    sortOldest: "Oldest",
+    sortTitle: "Title (A-Z)",
+    sortCalories: "Fewest calories",
+    loadMore: "Load more",
+    sortLastCooked: "Recently cooked",
+    favorites: "Favorites",
+    addFavorite: "Add to favorites",
+    removeFavorite: "Remove from favorites",
+    collections: "Collections",
+    collection: "Collection",
+    allRecipes: "All recipes",
+    noCollections: "No collections yet",
+    newCollection: "New collection",
+    collectionName: "Collection name",
+    deleteCollection: "Delete collection",
+    tags: "Tags",
+    tag: "Tag",
+    allTags: "All tags",
+    addTag: "Add tag...",
+    removeTag: "Remove tag",
+    markCooked: "I cooked this",
+    lastCooked: "Last cooked",
+    importRecipe: "Import recipe",
+    importRecipeDesc: "Bring a recipe from another site. Paste its address, or its page source if the site can't be reached.",
+    recipeUrl: "Recipe URL",
+    pastePageSource: "Or paste the page HTML or JSON-LD",
+    import: "Import",
+    recipeImported: "Imported {recipe}",
+    importedWithAI: "Imported {recipe}; the AI read it from the page, so check the details",
+    importInvalidJson: "The pasted JSON-LD is not valid JSON",
+    export: "Export",
+    print: "Print",
+    exportJsonLd: "JSON-LD (schema.org)",
+    backUpLibrary: "Back up library (zip)",
+    exportShownRecipes: "Export shown recipes",
     notFound: "No ingredient found.",
     selectIngredient: "Select ingredient",
     pantryTitle: "My Pantry",
@@ -323,6 +378,7 @@ export const TRANSLATIONS = {
     removedFromPlan: "Removed from meal plan",
     shareSuccess: "Link copied to clipboard!",
     sharePrivate: "Recipe is now private",
+    generateImage: "Generate image",
     errorGeneric: "Something went wrong",
     login: "Login",
     register: "Register",
@@ -402,6 +458,10 @@ export const TRANSLATIONS = {
     generating: "Chef IA está cozinhando...",
     saveRecipe: "Salvar Receita",
     saving: "Salvando...",
+    editRecipe: "Editar receita",
+    recipeTitle: "Título",
+    recipeContent: "Receita",
+    recipeUpdated: "Receita atualizada",
     viewFullRecipe: "Ver Receita Completa",
     delete: "Excluir",
     cancel: "Cancelar",
@@ -410,8 +470,59 @@ export const TRANSLATIONS = {
     addToMealPlan: "Adicionar ao Planejamento",
     ingredients: "Ingredientes",
     calories: "calorias",
+    nutritionPerServing: "Nutrição por porção",
+    servings: "Porções",
+    fewerServings: "Menos porções",
+    moreServings: "Mais porções",
+    nutritionEstimate: "Estimativa parcial",
+    nutritionUnmatched: "Não contabilizados: {items}",
+    protein: "Proteína",
+    fat: "Gordura",
+    carbs: "Carboidratos",
+    fiber: "Fibra",
+    sodium: "Sódio",
     searchIngredients: "Buscar ingredientes...",
     searchRecipes: "Buscar receitas...",
+    search: "Buscar",
+    excludeIngredient: "Sem ingrediente",
+    sortBy: "Ordenar por",
+    sortRelevance: "Mais relevantes",
+    sortNewest: "Mais recentes",
+    sortOldest: "Mais antigas",
+    sortTitle: "Título (A-Z)",
+    sortCalories: "Menos calorias",
+    loadMore: "Carregar mais",
+    sortLastCooked: "Feitas recentemente",
+    favorites: "Favoritas",
+    addFavorite: "Adicionar aos favoritos",
+    removeFavorite: "Remover dos favoritos",
+    collections: "Coleções",
+    collection: "Coleção",
+    allRecipes: "Todas as receitas",
+    noCollections: "Nenhuma coleção ainda",
+    newCollection: "Nova coleção",
+    collectionName: "Nome da coleção",
+    deleteCollection: "Excluir coleção",
+    tags: "Tags",
+    tag: "Tag",
+    allTags: "Todas as tags",
+    addTag: "Adicionar tag...",
+    removeTag: "Remover tag",
+    markCooked: "Eu fiz esta receita",
+    lastCooked: "Feita pela última vez",
+    importRecipe: "Importar receita",
+    importRecipeDesc: "Traga uma receita de outro site. Cole o endereço, ou o código da página se o site não puder ser acessado.",
+    recipeUrl: "URL da receita",
+    pastePageSource: "Ou cole o HTML ou o JSON-LD da página",
+    import: "Importar",
+    recipeImported: "{recipe} importada",
+    importedWithAI: "{recipe} importada; a IA leu a página, então confira os detalhes",
+    importInvalidJson: "O JSON-LD colado não é um JSON válido",
+    export: "Exportar",
+    print: "Imprimir",
+    exportJsonLd: "JSON-LD (schema.org)",
+    backUpLibrary: "Fazer backup da biblioteca (zip)",
+    exportShownRecipes: "Exportar receitas exibidas",
     notFound: "Nenhum ingrediente encontrado.",
     selectIngredient: "Selecionar ingrediente",
     pantryTitle: "Minha Despensa",
@@ -429,6 +540,7 @@ export const TRANSLATIONS = {
     removedFromPlan: "Removido do planejamento",
     shareSuccess: "Link copiado para a área de transferência!",
     sharePrivate: "Receita agora é privada",
+    generateImage: "Gerar imagem",
     errorGeneric: "Algo deu errado",
     login: "Entrar",
     register: "Registrar",
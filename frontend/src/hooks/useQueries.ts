@@ -1,13 +1,101 @@
-import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
+import { useQuery, useInfiniteQuery, useMutation, useQueryClient } from "@tanstack/react-query";
 import { api } from "@/services/api";
-import { RecipeFilter, GenerateRecipeRequest } from "@/types/api";
+import {
+  RecipeFilter,
+  GenerateRecipeRequest,
+  Recipe,
+  UpdateRecipeRequest,
+  SaveCollectionRequest,
+  ImportRecipeRequest,
+  ExportFormat,
+  ExportFile,
+} from "@/types/api";
 import { toast } from "sonner";
 
 // Recipes
+// useRecipes pages through the user's recipes; fetchNextPage loads the
+// page after the last one.
 export function useRecipes(filter?: RecipeFilter) {
-  return useQuery({
+  return useInfiniteQuery({
     queryKey: ["recipes", filter],
-    queryFn: () => api.getRecipes(filter),
+    queryFn: ({ pageParam }) => api.getRecipes(filter, pageParam),
+    initialPageParam: undefined as string | undefined,
+    getNextPageParam: (lastPage) => lastPage.next_cursor,
+  });
+}
+
+// useScaledRecipe fetches a recipe scaled to servings; it stays idle until
+// servings differs from what the recipe was saved with.
+export function useScaledRecipe(id: string, servings: number, enabled: boolean) {
+  return useQuery({
+    queryKey: ["recipes", id, "servings", servings],
+    queryFn: () => api.getRecipe(id, servings),
+    enabled,
+    retry: false,
+  });
+}
+
+export function useUpdateRecipe() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: ({ recipe, changes }: { recipe: Recipe; changes: UpdateRecipeRequest }) =>
+      api.updateRecipe(recipe, changes),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["recipes"] });
+    },
+    onError: (error: Error) => {
+      // A conflict means another tab saved first; reload its version.
+      queryClient.invalidateQueries({ queryKey: ["recipes"] });
+      toast.error(error.message);
+    },
+  });
+}
+
+export function useImportRecipe() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: (data: ImportRecipeRequest) => api.importRecipe(data),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["recipes"] });
+    },
+    onError: (error: Error) => {
+      toast.error(error.message);
+    },
+  });
+}
+
+// saveExport hands a downloaded export to the browser. HTML opens in a new
+// tab to be printed, falling back to a download when pop-ups are blocked.
+function saveExport({ blob, filename }: ExportFile) {
+  const url = URL.createObjectURL(blob);
+  const opened = blob.type.startsWith("text/html") && window.open(url, "_blank");
+  if (!opened) {
+    const link = document.createElement("a");
+    link.href = url;
+    link.download = filename;
+    link.click();
+  }
+  setTimeout(() => URL.revokeObjectURL(url), 60_000);
+}
+
+export function useExportRecipe() {
+  return useMutation({
+    mutationFn: ({ id, format }: { id: string; format: ExportFormat }) => api.exportRecipe(id, format),
+    onSuccess: saveExport,
+    onError: (error: Error) => {
+      toast.error(error.message);
+    },
+  });
+}
+
+export function useExportLibrary() {
+  return useMutation({
+    mutationFn: ({ format, filter }: { format: ExportFormat; filter?: RecipeFilter }) =>
+      api.exportLibrary(format, filter),
+    onSuccess: saveExport,
+    onError: (error: Error) => {
+      toast.error(error.message);
+    },
   });
 }
 
@@ -39,6 +127,68 @@ export function useToggleShareRecipe() {
   });
 }
 
+// useRecipeTags lists the user's tags; it sits under "recipes" so any
+// recipe change refreshes the counts.
+export function useRecipeTags() {
+  return useQuery({
+    queryKey: ["recipes", "tags"],
+    queryFn: api.getRecipeTags,
+  });
+}
+
+export function useRecipeTag() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: ({ id, tag, add }: { id: string; tag: string; add: boolean }) =>
+      add ? api.addRecipeTag(id, tag) : api.removeRecipeTag(id, tag),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["recipes"] });
+    },
+    onError: (error: Error) => {
+      toast.error(error.message);
+    },
+  });
+}
+
+export function useSetFavorite() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: ({ id, favorite }: { id: string; favorite: boolean }) => api.setFavorite(id, favorite),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["recipes"] });
+    },
+    onError: (error: Error) => {
+      toast.error(error.message);
+    },
+  });
+}
+
+export function useMarkCooked() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: api.markCooked,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["recipes"] });
+    },
+    onError: (error: Error) => {
+      toast.error(error.message);
+    },
+  });
+}
+
+export function useGenerateRecipeImage() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: api.generateRecipeImage,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["recipes"] });
+    },
+    onError: (error: Error) => {
+      toast.error(error.message);
+    },
+  });
+}
+
 export function useGenerateRecipe() {
   return useMutation({
     mutationFn: api.generateRecipe,
@@ -88,8 +238,8 @@ export function useAddPantryItem() {
       queryClient.invalidateQueries({ queryKey: ["pantry"] });
       toast.success("Item added to pantry");
     },
-    onError: () => {
-      toast.error("Failed to add item");
+    onError: (error: Error) => {
+      toast.error(error.message || "Failed to add item");
     },
   });
 }
@@ -108,6 +258,57 @@ export function useDeletePantryItem() {
   });
 }
 
+// Collections
+export function useCollections() {
+  return useQuery({
+    queryKey: ["collections"],
+    queryFn: api.getCollections,
+  });
+}
+
+export function useCreateCollection() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: (data: SaveCollectionRequest) => api.createCollection(data),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["collections"] });
+    },
+    onError: (error: Error) => {
+      toast.error(error.message);
+    },
+  });
+}
+
+export function useDeleteCollection() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: api.deleteCollection,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["collections"] });
+      queryClient.invalidateQueries({ queryKey: ["recipes"] });
+    },
+    onError: () => {
+      toast.error("Failed to delete collection");
+    },
+  });
+}
+
+// useSetInCollection adds a recipe to a collection or takes it out.
+export function useSetInCollection() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: ({ collectionId, recipeId, member }: { collectionId: string; recipeId: string; member: boolean }) =>
+      api.setInCollection(collectionId, recipeId, member),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["collections"] });
+      queryClient.invalidateQueries({ queryKey: ["recipes"] });
+    },
+    onError: (error: Error) => {
+      toast.error(error.message);
+    },
+  });
+}
+
 // Meal Plan
 export function useMealPlan(startDate: string, endDate: string) {
   return useQuery({
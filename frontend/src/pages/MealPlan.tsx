@@ -28,7 +28,11 @@ export default function MealPlanPage() {
   const endStr = format(endDate, "yyyy-MM-dd");
 
   const { data: plans = [], isLoading } = useMealPlan(startStr, endStr);
-  const { data: recipes = [] } = useRecipes();
+  // The picker searches on the server so it isn't limited to the first page.
+  const { data: recipePages } = useRecipes(
+    searchTerm.trim() ? { q: searchTerm.trim() } : { sort: "title", order: "asc" }
+  );
+  const filteredRecipes = recipePages?.pages.flatMap((page) => page.recipes) ?? [];
   const deletePlanMutation = useDeleteMealPlan();
   const addToMealPlanMutation = useAddToMealPlan();
 
@@ -53,10 +57,6 @@ export default function MealPlanPage() {
     });
   };
 
-  const filteredRecipes = recipes.filter(r => 
-    r.title.toLowerCase().includes(searchTerm.toLowerCase())
-  );
-
   const weekDays = Array.from({ length: 7 }).map((_, i) => addDays(startDate, i));
   const mealTypes = ["breakfast", "lunch", "dinner", "snack"];
 
@@ -1,29 +1,115 @@
 import { useState } from "react";
 import { Layout } from "@/components/Layout";
 import { RecipeCard } from "@/components/RecipeCard";
-import { Loader2, ChefHat, Search } from "lucide-react";
+import { ImportRecipeDialog } from "@/components/ImportRecipeDialog";
+import { Loader2, ChefHat, Search, Star, Plus, Trash2, Download, Archive } from "lucide-react";
 import { Input } from "@/components/ui/input";
 import { Button } from "@/components/ui/button";
 import { Label } from "@/components/ui/label";
-import { useRecipes, useDeleteRecipe } from "@/hooks/useQueries";
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select";
+import {
+  DropdownMenu,
+  DropdownMenuContent,
+  DropdownMenuItem,
+  DropdownMenuLabel,
+  DropdownMenuSeparator,
+  DropdownMenuTrigger,
+} from "@/components/ui/dropdown-menu";
+import {
+  useRecipes,
+  useDeleteRecipe,
+  useRecipeTags,
+  useCollections,
+  useCreateCollection,
+  useDeleteCollection,
+  useExportLibrary,
+} from "@/hooks/useQueries";
 import { useLanguage } from "@/contexts/LanguageContext";
+import { ExportFormat, RecipeFilter } from "@/types/api";
+
+// SORTS maps the sort menu to listing parameters; "relevance" leaves the
+// order to the server, which ranks search results.
+const SORTS: Record<string, Pick<RecipeFilter, "sort" | "order">> = {
+  relevance: {},
+  newest: { sort: "created_at", order: "desc" },
+  oldest: { sort: "created_at", order: "asc" },
+  title: { sort: "title", order: "asc" },
+  calories: { sort: "calories", order: "asc" },
+  cooked: { sort: "last_cooked", order: "desc" },
+};
+
+// ALL is the select value for "no collection" or "no tag", which Radix
+// cannot represent with an empty string.
+const ALL = "all";
 
 export default function MyRecipes() {
+  const [search, setSearch] = useState("");
   const [filterIngredient, setFilterIngredient] = useState("");
+  const [filterExclude, setFilterExclude] = useState("");
   const [filterCalories, setFilterCalories] = useState("");
-  const [activeFilter, setActiveFilter] = useState<{
-    ingredient?: string;
-    max_calories?: number;
-  }>({});
+  const [sort, setSort] = useState("newest");
+  const [activeFilter, setActiveFilter] = useState<RecipeFilter>({});
+  // Favorites, collection and tag apply as soon as they are picked.
+  const [favorites, setFavorites] = useState(false);
+  const [collection, setCollection] = useState(ALL);
+  const [tag, setTag] = useState(ALL);
+  const [newCollection, setNewCollection] = useState("");
   const { t } = useLanguage();
 
-  const { data: recipes = [], isLoading } = useRecipes(activeFilter);
+  const listFilter: RecipeFilter = {
+    ...activeFilter,
+    favorite: favorites || undefined,
+    collection: collection === ALL ? undefined : collection,
+    tag: tag === ALL ? undefined : tag,
+  };
+  const { data, isLoading, hasNextPage, fetchNextPage, isFetchingNextPage } = useRecipes(listFilter);
+  const recipes = data?.pages.flatMap((page) => page.recipes) ?? [];
   const deleteRecipeMutation = useDeleteRecipe();
+  const { data: tags = [] } = useRecipeTags();
+  const { data: collections = [] } = useCollections();
+  const createCollectionMutation = useCreateCollection();
+  const deleteCollectionMutation = useDeleteCollection();
+  const exportMutation = useExportLibrary();
+
+  // The backup holds every recipe; the other formats export what the
+  // filters show.
+  const handleExport = (format: ExportFormat) => {
+    exportMutation.mutate({ format, filter: format === "zip" ? undefined : listFilter });
+  };
+
+  const handleCreateCollection = () => {
+    const name = newCollection.trim();
+    if (!name) return;
+    createCollectionMutation.mutate(
+      { name },
+      {
+        onSuccess: (created) => {
+          setNewCollection("");
+          setCollection(created.id);
+        },
+      }
+    );
+  };
+
+  const handleDeleteCollection = () => {
+    deleteCollectionMutation.mutate(collection, {
+      onSuccess: () => setCollection(ALL),
+    });
+  };
 
   const handleFilter = () => {
     setActiveFilter({
+      q: search.trim() || undefined,
       ingredient: filterIngredient || undefined,
+      exclude_ingredient: filterExclude || undefined,
       max_calories: filterCalories ? parseInt(filterCalories) : undefined,
+      ...SORTS[sort],
     });
   };
 
@@ -44,14 +130,55 @@ export default function MyRecipes() {
   return (
     <Layout>
       <div className="mx-auto max-w-7xl space-y-8">
-        <div className="space-y-2">
-          <h1 className="text-4xl font-bold">{t("mySavedRecipes")}</h1>
-          <p className="text-lg text-muted-foreground">
-            {t("mySavedRecipesSubtitle")}
-          </p>
+        <div className="flex flex-wrap items-end justify-between gap-4">
+          <div className="space-y-2">
+            <h1 className="text-4xl font-bold">{t("mySavedRecipes")}</h1>
+            <p className="text-lg text-muted-foreground">
+              {t("mySavedRecipesSubtitle")}
+            </p>
+          </div>
+          <div className="flex gap-2">
+            <DropdownMenu>
+              <DropdownMenuTrigger asChild>
+                <Button variant="outline" disabled={exportMutation.isPending}>
+                  {exportMutation.isPending ? (
+                    <Loader2 className="mr-2 h-4 w-4 animate-spin" />
+                  ) : (
+                    <Download className="mr-2 h-4 w-4" />
+                  )}
+                  {t("export")}
+                </Button>
+              </DropdownMenuTrigger>
+              <DropdownMenuContent align="end">
+                <DropdownMenuItem onSelect={() => handleExport("zip")}>
+                  <Archive className="mr-2 h-4 w-4" />
+                  {t("backUpLibrary")}
+                </DropdownMenuItem>
+                <DropdownMenuSeparator />
+                <DropdownMenuLabel>{t("exportShownRecipes")}</DropdownMenuLabel>
+                <DropdownMenuItem onSelect={() => handleExport("html")}>{t("print")}</DropdownMenuItem>
+                <DropdownMenuItem onSelect={() => handleExport("pdf")}>PDF</DropdownMenuItem>
+                <DropdownMenuItem onSelect={() => handleExport("markdown")}>Markdown</DropdownMenuItem>
+                <DropdownMenuItem onSelect={() => handleExport("jsonld")}>{t("exportJsonLd")}</DropdownMenuItem>
+              </DropdownMenuContent>
+            </DropdownMenu>
+            <ImportRecipeDialog />
+          </div>
         </div>
 
         <div className="flex flex-wrap gap-4 items-end bg-card p-4 rounded-lg border shadow-sm">
+          <div className="grid w-full max-w-sm items-center gap-1.5">
+            <Label htmlFor="search">{t("search")}</Label>
+            <Input
+              id="search"
+              type="search"
+              value={search}
+              onChange={(e) => setSearch(e.target.value)}
+              onKeyDown={(e) => e.key === "Enter" && handleFilter()}
+              placeholder={t("searchRecipes")}
+              maxLength={200}
+            />
+          </div>
           <div className="grid w-full max-w-sm items-center gap-1.5">
             <Label htmlFor="ingredient">{t("ingredient")}</Label>
             <Input
@@ -61,6 +188,15 @@ export default function MyRecipes() {
               placeholder="e.g. Chicken"
             />
           </div>
+          <div className="grid w-full max-w-sm items-center gap-1.5">
+            <Label htmlFor="exclude">{t("excludeIngredient")}</Label>
+            <Input
+              id="exclude"
+              value={filterExclude}
+              onChange={(e) => setFilterExclude(e.target.value)}
+              placeholder="e.g. Peanuts"
+            />
+          </div>
           <div className="grid w-full max-w-sm items-center gap-1.5">
             <Label htmlFor="calories">{t("maxCalories")}</Label>
             <Input
@@ -71,11 +207,105 @@ export default function MyRecipes() {
               placeholder="e.g. 500"
             />
           </div>
+          <div className="grid w-full max-w-[12rem] items-center gap-1.5">
+            <Label htmlFor="sort">{t("sortBy")}</Label>
+            <Select value={sort} onValueChange={setSort}>
+              <SelectTrigger id="sort">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="relevance">{t("sortRelevance")}</SelectItem>
+                <SelectItem value="newest">{t("sortNewest")}</SelectItem>
+                <SelectItem value="oldest">{t("sortOldest")}</SelectItem>
+                <SelectItem value="title">{t("sortTitle")}</SelectItem>
+                <SelectItem value="calories">{t("sortCalories")}</SelectItem>
+                <SelectItem value="cooked">{t("sortLastCooked")}</SelectItem>
+              </SelectContent>
+            </Select>
+          </div>
           <Button onClick={handleFilter}>
             <Search className="mr-2 h-4 w-4" /> {t("filter")}
           </Button>
         </div>
 
+        <div className="flex flex-wrap gap-4 items-end">
+          <Button
+            variant={favorites ? "default" : "outline"}
+            onClick={() => setFavorites((f) => !f)}
+            aria-pressed={favorites}
+          >
+            <Star className={favorites ? "mr-2 h-4 w-4 fill-current" : "mr-2 h-4 w-4"} />
+            {t("favorites")}
+          </Button>
+          <div className="grid w-full max-w-[14rem] items-center gap-1.5">
+            <Label htmlFor="collection">{t("collection")}</Label>
+            <div className="flex gap-1">
+              <Select value={collection} onValueChange={setCollection}>
+                <SelectTrigger id="collection">
+                  <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                  <SelectItem value={ALL}>{t("allRecipes")}</SelectItem>
+                  {collections.map((c) => (
+                    <SelectItem key={c.id} value={c.id}>
+                      {c.name} ({c.recipe_count})
+                    </SelectItem>
+                  ))}
+                </SelectContent>
+              </Select>
+              {collection !== ALL && (
+                <Button
+                  variant="ghost"
+                  size="icon"
+                  title={t("deleteCollection")}
+                  onClick={handleDeleteCollection}
+                  disabled={deleteCollectionMutation.isPending}
+                >
+                  <Trash2 className="h-4 w-4 text-destructive" />
+                </Button>
+              )}
+            </div>
+          </div>
+          <div className="grid w-full max-w-[14rem] items-center gap-1.5">
+            <Label htmlFor="new-collection">{t("newCollection")}</Label>
+            <div className="flex gap-1">
+              <Input
+                id="new-collection"
+                value={newCollection}
+                onChange={(e) => setNewCollection(e.target.value)}
+                onKeyDown={(e) => e.key === "Enter" && handleCreateCollection()}
+                placeholder={t("collectionName")}
+                maxLength={100}
+              />
+              <Button
+                variant="outline"
+                size="icon"
+                title={t("newCollection")}
+                onClick={handleCreateCollection}
+                disabled={createCollectionMutation.isPending || !newCollection.trim()}
+              >
+                <Plus className="h-4 w-4" />
+              </Button>
+            </div>
+          </div>
+          <div className="grid w-full max-w-[12rem] items-center gap-1.5">
+            <Label htmlFor="tag">{t("tag")}</Label>
+            <Select value={tag} onValueChange={setTag}>
+              <SelectTrigger id="tag">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value={ALL}>{t("allTags")}</SelectItem>
+                {tags.map((tc) => (
+                  <SelectItem key={tc.tag} value={tc.tag}>
+                    #{tc.tag} ({tc.recipes})
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          </div>
+        </div>
+
         {recipes.length === 0 ? (
           <div className="flex min-h-[400px] flex-col items-center justify-center space-y-4 text-center">
             <ChefHat className="h-16 w-16 text-muted-foreground/50" />
@@ -87,15 +317,29 @@ export default function MyRecipes() {
             </div>
           </div>
         ) : (
-          <div className="grid gap-6 md:grid-cols-2 lg:grid-cols-3">
-            {recipes.map((recipe) => (
-              <RecipeCard
-                key={recipe.id}
-                recipe={recipe}
-                onDelete={handleDelete}
-              />
-            ))}
-          </div>
+          <>
+            <div className="grid gap-6 md:grid-cols-2 lg:grid-cols-3">
+              {recipes.map((recipe) => (
+                <RecipeCard
+                  key={recipe.id}
+                  recipe={recipe}
+                  onDelete={handleDelete}
+                />
+              ))}
+            </div>
+            {hasNextPage && (
+              <div className="flex justify-center">
+                <Button
+                  variant="outline"
+                  onClick={() => fetchNextPage()}
+                  disabled={isFetchingNextPage}
+                >
+                  {isFetchingNextPage && <Loader2 className="mr-2 h-4 w-4 animate-spin" />}
+                  {t("loadMore")}
+                </Button>
+              </div>
+            )}
+          </>
         )}
       </div>
     </Layout>
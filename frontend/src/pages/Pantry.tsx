@@ -104,6 +104,8 @@ export default function Pantry() {
                     <SelectItem value="tbsp">tbsp</SelectItem>
                     <SelectItem value="tsp">tsp</SelectItem>
                     <SelectItem value="cup">cup</SelectItem>
+                    <SelectItem value="oz">oz</SelectItem>
+                    <SelectItem value="lb">lb</SelectItem>
                   </SelectContent>
                 </Select>
               </div>
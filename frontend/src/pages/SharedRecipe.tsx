@@ -3,6 +3,8 @@ import { Loader2, ChefHat, Flame, Calendar } from "lucide-react";
 import { Badge } from "@/components/ui/badge";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 import { usePublicRecipe } from "@/hooks/useQueries";
+import { imageAssetUrl } from "@/services/api";
+import { NutritionPanel } from "@/components/NutritionPanel";
 import { useLanguage } from "@/contexts/LanguageContext";
 
 export default function SharedRecipe() {
@@ -33,7 +35,14 @@ export default function SharedRecipe() {
   return (
     <div className="min-h-screen bg-background p-4 md:p-8">
       <div className="mx-auto max-w-3xl">
-        <Card>
+        <Card className="overflow-hidden">
+          {recipe.image_url && (
+            <img
+              src={imageAssetUrl(recipe.image_url)}
+              alt={recipe.title}
+              className="h-64 w-full object-cover"
+            />
+          )}
           <CardHeader>
             <CardTitle className="text-3xl md:text-4xl">{recipe.title}</CardTitle>
             <div className="flex flex-wrap items-center gap-4 text-muted-foreground">
@@ -59,6 +68,8 @@ export default function SharedRecipe() {
               </div>
             </div>
 
+            {recipe.nutrition && <NutritionPanel nutrition={recipe.nutrition} />}
+
             <div className="prose max-w-none dark:prose-invert">
               <div
                 dangerouslySetInnerHTML={{
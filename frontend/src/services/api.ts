@@ -6,10 +6,28 @@ import {
   GenerateRecipeRequest,
   GenerateRecipeResponse,
   SaveRecipeRequest,
+  UpdateRecipeRequest,
   Recipe,
+  RecipePage,
   PantryItem,
   MealPlan,
   RecipeFilter,
+  TagCount,
+  ImportRecipeRequest,
+  ImportRecipeResult,
+  ExportFormat,
+  ExportFile,
+  Collection,
+  SaveCollectionRequest,
+  QuotaStatus,
+  DietaryProfile,
+  UpdateDietaryProfileRequest,
+  DietaryOptions,
+  RefineRequest,
+  RefineResponse,
+  RefinementSession,
+  SubstitutionRequest,
+  SubstitutionResponse,
 } from "@/types/api";
 
 const BASE_URL = "http://localhost:8080/api/v1";
@@ -37,9 +55,13 @@ const mockRecipes: Recipe[] = [
 6. Serve immediately with extra parmesan`,
     calories_estimate: 650,
     created_at: new Date().toISOString(),
+    updated_at: new Date().toISOString(),
   },
 ];
 
+// imageAssetUrl resolves the server-relative image_url of a recipe.
+export const imageAssetUrl = (path: string) => new URL(path, BASE_URL).toString();
+
 const delay = (ms: number) => new Promise((resolve) => setTimeout(resolve, ms));
 
 async function mockApiCall<T>(data: T, delayMs = 800): Promise<T> {
@@ -47,6 +69,41 @@ async function mockApiCall<T>(data: T, delayMs = 800): Promise<T> {
   return data;
 }
 
+// organizeRecipe sends one of the small recipe changes (tags, favorite,
+// cooked) that answer with the updated recipe.
+async function organizeRecipe(path: string, method: string, body?: unknown): Promise<Recipe> {
+  const token = localStorage.getItem("token");
+  const response = await fetch(`${BASE_URL}/recipes/${path}`, {
+    method,
+    headers: {
+      ...(body ? { "Content-Type": "application/json" } : {}),
+      Authorization: `Bearer ${token}`,
+    },
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || "Failed to update recipe");
+  }
+  return response.json();
+}
+
+// fetchExport downloads an export; it needs the bearer token, so it can't
+// be a plain link. The file name comes from Content-Disposition.
+async function fetchExport(path: string, fallbackName: string): Promise<ExportFile> {
+  const token = localStorage.getItem("token");
+  const response = await fetch(`${BASE_URL}/recipes/${path}`, {
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || "Failed to export recipes");
+  }
+  const disposition = response.headers.get("Content-Disposition") ?? "";
+  const filename = /filename="?([^";]+)"?/.exec(disposition)?.[1] ?? fallbackName;
+  return { blob: await response.blob(), filename };
+}
+
 // API Service
 export const api = {
   // Authentication
@@ -157,9 +214,93 @@ ${data.preferences ? `*Note: ${data.preferences}*` : ""}`,
 
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.message || "Recipe generation failed");
+      throw new Error(error.error || error.message || "Recipe generation failed");
+    }
+
+    return response.json();
+  },
+
+  async refineRecipe(data: RefineRequest): Promise<RefineResponse> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/chef/refine`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify(data),
+    });
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({}));
+      throw new Error(error.error || "Recipe refinement failed");
+    }
+    return response.json();
+  },
+
+  async getRefinementSession(id: string): Promise<RefinementSession> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/chef/sessions/${id}`, {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) throw new Error("Failed to fetch refinement session");
+    return response.json();
+  },
+
+  async getSubstitutions(data: SubstitutionRequest): Promise<SubstitutionResponse> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/chef/substitutions`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify(data),
+    });
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({}));
+      throw new Error(error.error || "Failed to fetch substitutions");
+    }
+    return response.json();
+  },
+
+  async getQuota(): Promise<QuotaStatus> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/chef/quota`, {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) throw new Error("Failed to fetch quota");
+    return response.json();
+  },
+
+  async getDietaryProfile(): Promise<DietaryProfile> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/dietary-profile`, {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) throw new Error("Failed to fetch dietary profile");
+    return response.json();
+  },
+
+  async updateDietaryProfile(data: UpdateDietaryProfileRequest): Promise<DietaryProfile> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/dietary-profile`, {
+      method: "PUT",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify(data),
+    });
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({}));
+      throw new Error(error.error || "Failed to update dietary profile");
     }
+    return response.json();
+  },
 
+  async getDietaryOptions(): Promise<DietaryOptions> {
+    const response = await fetch(`${BASE_URL}/dietary-profile/options`);
+    if (!response.ok) throw new Error("Failed to fetch dietary options");
     return response.json();
   },
 
@@ -171,6 +312,7 @@ ${data.preferences ? `*Note: ${data.preferences}*` : ""}`,
         user_id: "mock-user-id",
         ...data,
         created_at: new Date().toISOString(),
+        updated_at: new Date().toISOString(),
       };
       mockRecipes.unshift(newRecipe);
       return mockApiCall(newRecipe);
@@ -194,16 +336,34 @@ ${data.preferences ? `*Note: ${data.preferences}*` : ""}`,
     return response.json();
   },
 
-  async getRecipes(filter?: RecipeFilter): Promise<Recipe[]> {
+  async importRecipe(data: ImportRecipeRequest): Promise<ImportRecipeResult> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/recipes/import`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify(data),
+    });
+    if (!response.ok) {
+      const error = await response.json();
+      throw new Error(error.error || "Failed to import recipe");
+    }
+    return response.json();
+  },
+
+  async getRecipes(filter?: RecipeFilter, cursor?: string): Promise<RecipePage> {
     if (USE_MOCK) {
-      return mockApiCall([...mockRecipes]);
+      return mockApiCall({ recipes: [...mockRecipes] });
     }
 
     const token = localStorage.getItem("token");
     const params = new URLSearchParams();
-    if (filter?.max_calories)
-      params.append("max_calories", filter.max_calories.toString());
-    if (filter?.ingredient) params.append("ingredient", filter.ingredient);
+    for (const [key, value] of Object.entries(filter ?? {})) {
+      if (value !== undefined && value !== "") params.append(key, String(value));
+    }
+    if (cursor) params.append("cursor", cursor);
 
     const response = await fetch(`${BASE_URL}/recipes?${params.toString()}`, {
       headers: {
@@ -213,11 +373,57 @@ ${data.preferences ? `*Note: ${data.preferences}*` : ""}`,
 
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.message || "Failed to fetch recipes");
+      throw new Error(error.error || "Failed to fetch recipes");
     }
 
-    const data = await response.json();
-    return data || [];
+    return response.json();
+  },
+
+  async exportRecipe(id: string, format: ExportFormat): Promise<ExportFile> {
+    return fetchExport(`${id}/export?format=${format}`, `recipe.${format}`);
+  },
+
+  // exportLibrary exports the recipes matching filter, all of them by
+  // default.
+  async exportLibrary(format: ExportFormat, filter?: RecipeFilter): Promise<ExportFile> {
+    const params = new URLSearchParams({ format });
+    for (const [key, value] of Object.entries(filter ?? {})) {
+      if (value !== undefined && value !== "" && key !== "limit") params.append(key, String(value));
+    }
+    return fetchExport(`export?${params.toString()}`, `recipes.${format}`);
+  },
+
+  async getRecipe(id: string, servings?: number): Promise<Recipe> {
+    const token = localStorage.getItem("token");
+    const params = servings ? `?servings=${servings}` : "";
+    const response = await fetch(`${BASE_URL}/recipes/${id}${params}`, {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) {
+      const error = await response.json();
+      throw new Error(error.error || "Failed to fetch recipe");
+    }
+    return response.json();
+  },
+
+  // updateRecipe edits a recipe only if it still is the version the caller
+  // loaded: the ETag quotes updated_at, so a stale edit fails with 412.
+  async updateRecipe(recipe: Recipe, changes: UpdateRecipeRequest): Promise<Recipe> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/recipes/${recipe.id}`, {
+      method: "PATCH",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+        "If-Match": `"${recipe.updated_at}"`,
+      },
+      body: JSON.stringify(changes),
+    });
+    if (!response.ok) {
+      const error = await response.json();
+      throw new Error(error.error || "Failed to update recipe");
+    }
+    return response.json();
   },
 
   async deleteRecipe(id: string): Promise<void> {
@@ -266,6 +472,64 @@ ${data.preferences ? `*Note: ${data.preferences}*` : ""}`,
     return response.json();
   },
 
+  async getRecipeRevisions(id: string): Promise<Recipe[]> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/recipes/${id}/revisions`, {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) throw new Error("Failed to fetch recipe revisions");
+    return response.json();
+  },
+
+  async generateRecipeImage(id: string): Promise<Recipe> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/recipes/${id}/image`, {
+      method: "POST",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) {
+      const error = await response.json();
+      throw new Error(error.error || "Failed to generate image");
+    }
+    return response.json();
+  },
+
+  async getRecipeTags(): Promise<TagCount[]> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/recipes/tags`, {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) throw new Error("Failed to fetch tags");
+    return response.json();
+  },
+
+  async addRecipeTag(id: string, tag: string): Promise<Recipe> {
+    return organizeRecipe(`${id}/tags`, "POST", { tag });
+  },
+
+  async removeRecipeTag(id: string, tag: string): Promise<Recipe> {
+    return organizeRecipe(`${id}/tags/${encodeURIComponent(tag)}`, "DELETE");
+  },
+
+  async setFavorite(id: string, favorite: boolean): Promise<Recipe> {
+    return organizeRecipe(`${id}/favorite`, favorite ? "PUT" : "DELETE");
+  },
+
+  async markCooked(id: string): Promise<Recipe> {
+    return organizeRecipe(`${id}/cooked`, "POST");
+  },
+
+  // Owner images need the bearer token, so they are fetched and handed to
+  // <img> as an object URL; shared images can be linked directly.
+  async getRecipeImage(imageUrl: string): Promise<string> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(imageAssetUrl(imageUrl), {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) throw new Error("Failed to fetch recipe image");
+    return URL.createObjectURL(await response.blob());
+  },
+
   // Pantry
   async getPantryItems(): Promise<PantryItem[]> {
     const token = localStorage.getItem("token");
@@ -286,7 +550,10 @@ ${data.preferences ? `*Note: ${data.preferences}*` : ""}`,
       },
       body: JSON.stringify({ name, quantity, unit }),
     });
-    if (!response.ok) throw new Error("Failed to add pantry item");
+    if (!response.ok) {
+      const error = await response.json();
+      throw new Error(error.error || "Failed to add pantry item");
+    }
     return response.json();
   },
 
@@ -299,6 +566,55 @@ ${data.preferences ? `*Note: ${data.preferences}*` : ""}`,
     if (!response.ok) throw new Error("Failed to delete pantry item");
   },
 
+  // Collections
+  async getCollections(): Promise<Collection[]> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/collections`, {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) throw new Error("Failed to fetch collections");
+    return response.json();
+  },
+
+  async createCollection(data: SaveCollectionRequest): Promise<Collection> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/collections`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify(data),
+    });
+    if (!response.ok) {
+      const error = await response.json();
+      throw new Error(error.error || "Failed to create collection");
+    }
+    return response.json();
+  },
+
+  async deleteCollection(id: string): Promise<void> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/collections/${id}`, {
+      method: "DELETE",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) throw new Error("Failed to delete collection");
+  },
+
+  async setInCollection(collectionId: string, recipeId: string, member: boolean): Promise<Collection> {
+    const token = localStorage.getItem("token");
+    const response = await fetch(`${BASE_URL}/collections/${collectionId}/recipes/${recipeId}`, {
+      method: member ? "PUT" : "DELETE",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!response.ok) {
+      const error = await response.json();
+      throw new Error(error.error || "Failed to update collection");
+    }
+    return response.json();
+  },
+
   // Meal Plan
   async getMealPlan(startDate: string, endDate: string): Promise<MealPlan[]> {
     const token = localStorage.getItem("token");
@@ -7,7 +7,43 @@ export interface User {
   created_at?: string;
 }
 
-export interface Recipe {
+export interface RecipeIngredient {
+  name: string;
+  quantity?: number;
+  unit?: string;
+  note?: string;
+}
+
+// Structured part of a recipe; recipes saved as plain markdown leave it empty.
+export interface RecipeDetails {
+  servings?: number;
+  prep_time_minutes?: number;
+  cook_time_minutes?: number;
+  cuisine?: string;
+  tags?: string[];
+  ingredients?: RecipeIngredient[];
+  steps?: string[];
+}
+
+export interface NutrientAmounts {
+  calories: number;
+  protein_g: number;
+  fat_g: number;
+  carbs_g: number;
+  fiber_g: number;
+  sodium_mg: number;
+}
+
+// Nutrition computed from the ingredient lines, as opposed to the model's
+// calorie estimate.
+export interface NutritionFacts {
+  per_serving: NutrientAmounts;
+  servings: number;
+  confidence: "high" | "medium" | "low";
+  unmatched?: string[];
+}
+
+export interface Recipe extends RecipeDetails {
   id: string;
   user_id: string;
   title: string;
@@ -15,8 +51,22 @@ export interface Recipe {
   content_markdown: string;
   calories_estimate: number;
   created_at: string;
+  updated_at: string;
   is_public?: boolean;
   share_token?: string;
+  language?: string;
+  prompt_version?: string;
+  model?: string;
+  revision_of?: string;
+  source_url?: string;
+  image_url?: string;
+  nutrition?: NutritionFacts;
+  scaled_from?: number;
+  is_favorite?: boolean;
+  last_cooked_at?: string;
+  collection_ids?: string[];
+  // highlight is the search excerpt, with matches wrapped in <mark>.
+  highlight?: string;
 }
 
 export interface PantryItem {
@@ -63,22 +113,224 @@ export interface GenerateRecipeRequest {
   ingredients: string[];
   preferences?: string;
   language?: string;
+  regenerate?: boolean;
+  count?: number;
+  pantry_mode?: PantryMode;
+  max_extras?: number;
+  use_up?: string[];
+}
+
+export type PantryMode = "strict" | "extras";
+
+export interface PantryUsage {
+  pantry_item_id: string;
+  name: string;
+  quantity?: number;
+  unit?: string;
+  available?: string;
+  // Set when the recipe needs more than the pantry holds.
+  exceeds?: boolean;
 }
 
-export interface GenerateRecipeResponse {
+export type ParseStatus = "clean" | "repaired" | "degraded";
+
+export interface GenerateRecipeResponse extends RecipeDetails {
   title: string;
   content: string;
   calories: number;
+  nutrition?: NutritionFacts;
+  parse_status?: ParseStatus;
+  language?: string;
+  prompt_version?: string;
+  model?: string;
+  revision_of?: string;
+  cached?: boolean;
+  pantry_used?: PantryUsage[];
+  extra_ingredients?: string[];
+  dietary_warnings?: DietaryViolation[];
+}
+
+export interface GenerateAlternativesResponse {
+  recipes: GenerateRecipeResponse[];
+  requested: number;
+  errors?: string[];
+}
+
+export interface QuotaStatus {
+  role: string;
+  unlimited: boolean;
+  daily_limit: number;
+  used: number;
+  remaining: number;
+  reset_at: string;
+  rate_per_minute: number;
+  burst: number;
+}
+
+export interface DietaryProfile {
+  user_id: string;
+  diets: string[];
+  allergens: string[];
+  dislikes: string[];
+  updated_at: string;
+}
+
+export interface UpdateDietaryProfileRequest {
+  diets: string[];
+  allergens: string[];
+  dislikes: string[];
+}
+
+export interface DietaryOptions {
+  diets: string[];
+  allergens: string[];
 }
 
-export interface SaveRecipeRequest {
+export interface DietaryViolation {
+  term: string;
+  rule: string;
+  blocking: boolean;
+}
+
+export interface SaveRecipeRequest extends RecipeDetails {
   title: string;
   content_markdown: string;
   ingredients_used: string[];
   calories_estimate: number;
+  parse_status?: ParseStatus;
+  language?: string;
+  prompt_version?: string;
+  model?: string;
+  revision_of?: string;
+}
+
+// UpdateRecipeRequest is a partial edit of a saved recipe (PATCH).
+export interface UpdateRecipeRequest extends Partial<RecipeDetails> {
+  title?: string;
+  content_markdown?: string;
+  calories_estimate?: number;
 }
 
+export interface RefineRequest {
+  session_id?: string;
+  recipe_id?: string;
+  recipe?: GenerateRecipeResponse;
+  instruction: string;
+}
+
+export interface RefineResponse {
+  session_id: string;
+  revision: number;
+  recipe: GenerateRecipeResponse;
+}
+
+export interface RefinementTurn {
+  position: number;
+  instruction?: string;
+  recipe: GenerateRecipeResponse;
+  created_at: string;
+}
+
+export interface RefinementSession {
+  id: string;
+  recipe_id?: string;
+  turns: RefinementTurn[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SubstitutionRequest {
+  recipe_id?: string;
+  ingredient?: string;
+  context?: string;
+  language?: string;
+}
+
+export type SubstitutionSource = "table" | "model" | "none";
+
+export interface Substitution {
+  name: string;
+  ratio: number;
+  notes?: string;
+  pantry_item_id?: string;
+  in_pantry: boolean;
+}
+
+export interface IngredientSubstitutions {
+  ingredient: string;
+  source: SubstitutionSource;
+  substitutions: Substitution[];
+}
+
+export interface SubstitutionResponse {
+  results: IngredientSubstitutions[];
+  model?: string;
+}
+
+export type RecipeSort = "created_at" | "title" | "calories" | "last_cooked" | "relevance";
+
 export interface RecipeFilter {
+  q?: string;
   ingredient?: string;
+  ingredient_match?: "all" | "any";
+  exclude_ingredient?: string;
+  tag?: string;
+  favorite?: boolean;
+  collection?: string;
+  min_calories?: number;
   max_calories?: number;
+  created_from?: string;
+  created_to?: string;
+  sort?: RecipeSort;
+  order?: "asc" | "desc";
+  limit?: number;
+}
+
+export interface RecipePage {
+  recipes: Recipe[];
+  next_cursor?: string;
+}
+
+// ImportRecipeRequest imports from exactly one of a URL, a pasted HTML
+// page or a schema.org Recipe JSON-LD document.
+export interface ImportRecipeRequest {
+  url?: string;
+  html?: string;
+  json_ld?: unknown;
+  language?: string;
+}
+
+export interface ImportRecipeResult {
+  recipe: Recipe;
+  source: "json-ld" | "model";
+}
+
+// ExportFormat is a download format; zip is only offered for the whole
+// library.
+export type ExportFormat = "markdown" | "jsonld" | "html" | "pdf" | "zip";
+
+export interface ExportFile {
+  blob: Blob;
+  filename: string;
+}
+
+export interface TagCount {
+  tag: string;
+  recipes: number;
+}
+
+// Collection is a named group of recipes, e.g. "Weeknight" or "Christmas".
+export interface Collection {
+  id: string;
+  user_id: string;
+  name: string;
+  description: string;
+  recipe_count: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SaveCollectionRequest {
+  name?: string;
+  description?: string;
 }